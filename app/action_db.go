@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/zenon/integrity"
+	"github.com/zenon-network/go-zenon/zenon/migrate"
+)
+
+var (
+	dbMigrateToFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "Backend to which the databases are converted: leveldb or pebble",
+		Value: string(db.Pebble),
+	}
+
+	dbCommand = &cli.Command{
+		Name:     "db",
+		Usage:    "Inspect the databases of the data dir",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action: dbVerifyAction,
+				Name:   "verify",
+				Usage:  "Walk the chain from genesis and report the first inconsistent momentum. znnd must be stopped",
+			},
+			{
+				Action: dbMigrateAction,
+				Name:   "migrate",
+				Usage:  "Convert the databases of the data dir to another backend. znnd must be stopped",
+				Flags:  []cli.Flag{dbMigrateToFlag},
+				Description: `Each database is copied to the new backend next to the current one. An interrupted migration is started
+again by running the command again. Once copied, the new databases replace the current ones, which are kept with the
+name of their backend as suffix, such as nom.leveldb, and can be removed after checking the node.`,
+			},
+		},
+	}
+)
+
+func dbVerifyAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Verifying the databases in %v ...\n", cfg.DataPath)
+	report, err := integrity.Verify(cfg.DataPath, cfg.MakeGenesisConfig(), func(height uint64) {
+		fmt.Printf("Verified momentums up to height %v\n", height)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(`Frontier momentum:%v
+Pruned height:%v
+First momentum with history:%v
+Verified momentums:%v
+Verified account-blocks:%v
+Accounts:%v
+`, report.Frontier, report.PrunedHeight, report.HistoryHeight, report.Momentums, report.AccountBlocks, report.Accounts)
+	if !report.Consistent() {
+		return fmt.Errorf("inconsistency found at height %v: %v", report.FailedHeight, report.Reason)
+	}
+	fmt.Printf("No inconsistency found\n")
+	return nil
+}
+
+func dbMigrateAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	backend, err := db.ParseBackend(ctx.String(dbMigrateToFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrating the databases in %v to %v ...\n", cfg.DataPath, backend)
+	results, err := migrate.Run(cfg.DataPath, backend, func(dir string, entries uint64) {
+		fmt.Printf("Copied %v entries of %v\n", entries, dir)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if result.Migrated {
+			fmt.Printf("%v: copied %v entries from %v, the previous database was kept as %v\n", result.Dir, result.Entries, result.From, migrate.BackupDir(result.Dir, result.From))
+		} else {
+			fmt.Printf("%v: already uses %v\n", result.Dir, backend)
+		}
+	}
+	fmt.Printf("Migration done. Start znnd with --%v %v, or set Database.Backend to \"%v\" in the config file\n", DBBackendFlag.Name, backend, backend)
+	return nil
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/zenon/reindex"
+)
+
+var (
+	reindexFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Height of the first replayed momentum. The state before it is kept. Defaults to the first momentum which wasn't pruned",
+	}
+
+	reindexCommand = &cli.Command{
+		Action:   reindexAction,
+		Name:     "reindex",
+		Usage:    "Rebuild the chain state and the consensus db by replaying the stored momentums and account-blocks. znnd must be stopped",
+		Category: "DATABASE COMMANDS",
+		Flags:    []cli.Flag{reindexFromFlag},
+		Description: `The databases are rebuilt in the reindex directory of the data dir. An interrupted reindex is resumed by
+running the command again. Once done, the rebuilt databases replace the current ones, which are kept as nom.old
+and consensus.old and can be removed after checking the node.`,
+	}
+)
+
+func reindexAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reindexing the databases in %v ...\n", cfg.DataPath)
+	result, err := reindex.Run(&reindex.Config{
+		DataDir:        cfg.DataPath,
+		Genesis:        cfg.MakeGenesisConfig(),
+		From:           ctx.Uint64(reindexFromFlag.Name),
+		PruneRetention: cfg.Pruning.Retention,
+	}, func(height uint64) {
+		fmt.Printf("Replayed momentums up to height %v\n", height)
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Resumed {
+		fmt.Printf("Resumed the reindex at height %v\n", result.From)
+	}
+	fmt.Printf(`Reindex done
+First replayed momentum:%v
+Frontier momentum:%v
+The previous databases were kept with the %v suffix
+`, result.From, result.Frontier, reindex.BackupSuffix)
+	return nil
+}
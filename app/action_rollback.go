@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/zenon/rollback"
+)
+
+var (
+	rollbackToFlag = &cli.StringFlag{
+		Name:     "to",
+		Usage:    "Height or hash of the momentum which becomes the frontier momentum",
+		Required: true,
+	}
+	rollbackForceFlag = &cli.BoolFlag{
+		Name:  "force",
+		Usage: fmt.Sprintf("Roll back stable momentums, which are more than %v momentums below the frontier momentum", chain.StableDepth),
+	}
+
+	rollbackCommand = &cli.Command{
+		Action:   rollbackAction,
+		Name:     "rollback",
+		Usage:    "Roll the chain back to a momentum. znnd must be stopped",
+		Category: "DATABASE COMMANDS",
+		Flags:    []cli.Flag{rollbackToFlag, rollbackForceFlag},
+		Description: `The momentums which follow the given momentum are removed, with the account-blocks they confirmed and
+the consensus points computed from them. Once started, the node syncs the following momentums again from its peers.
+Momentums can't be rolled back past the ones pruned by the node.
+
+A running node is rolled back with the admin.rollbackTo RPC, once admin is listed in the RPC endpoints.
+The admin methods are served only to clients sending one of the RPC.Policy.AdminKeys in the X-Api-Key header.`,
+	}
+)
+
+func rollbackAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolling back the chain in %v ...\n", cfg.DataPath)
+	result, err := rollback.Run(&rollback.Config{
+		DataDir: cfg.DataPath,
+		Genesis: cfg.MakeGenesisConfig(),
+		To:      ctx.String(rollbackToFlag.Name),
+		Force:   ctx.Bool(rollbackForceFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(`Rollback done
+Rolled back momentums:%v
+Previous frontier momentum:%v
+Frontier momentum:%v
+`, result.Momentums, result.Previous, result.Frontier)
+	return nil
+}
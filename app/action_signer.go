@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
+)
+
+const (
+	defaultSignerSocket = "signer.ipc"
+)
+
+var (
+	signerListenFlag = &cli.StringFlag{
+		Name:  "listen",
+		Usage: "Endpoint of the signer, either host:port or unix:///path/to/socket. Defaults to " + defaultSignerSocket + " inside the data dir",
+	}
+	signerTokenFileFlag = &cli.StringFlag{
+		Name:  "token-file",
+		Usage: "File holding the token which the node must send, as configured in producer.RemoteSignerTokenFile. Required for host:port endpoints",
+	}
+	signerProtectionFlag = &cli.StringFlag{
+		Name:  "protection-file",
+		Usage: "File holding the last signed momentum. Defaults to " + signer.DefaultProtectionFile + " inside the data dir",
+	}
+
+	signerCommand = &cli.Command{
+		Action: signerAction,
+		Name:   "signer",
+		Usage:  "Sign the momentums of the producer configured in config.json for a node which uses producer.RemoteSigner",
+		Flags:  []cli.Flag{signerListenFlag, signerTokenFileFlag, signerProtectionFlag},
+	}
+)
+
+func signerAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	walletManager := wallet.New(&wallet.Config{WalletDir: cfg.WalletPath})
+	if err := walletManager.Start(); err != nil {
+		return err
+	}
+	defer walletManager.Stop()
+	keyPair, err := cfg.ProducerKeyPair(walletManager)
+	if err != nil {
+		return err
+	}
+
+	protectionFile := ctx.String(signerProtectionFlag.Name)
+	if protectionFile == "" {
+		protectionFile = filepath.Join(cfg.DataPath, signer.DefaultProtectionFile)
+	}
+	protection, err := signer.OpenProtection(protectionFile)
+	if err != nil {
+		return err
+	}
+
+	token := ""
+	if tokenFile := ctx.String(signerTokenFileFlag.Name); tokenFile != "" {
+		if token, err = signer.ReadToken(tokenFile); err != nil {
+			return err
+		}
+	}
+
+	endpoint := ctx.String(signerListenFlag.Name)
+	if endpoint == "" {
+		endpoint = "unix://" + filepath.Join(cfg.DataPath, defaultSignerSocket)
+	}
+	listener, err := signer.Listen(endpoint, token)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:      signer.NewServer(signer.NewLocalSigner(keyPair, protection), token),
+		ReadTimeout:  rpc.DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout: rpc.DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:  rpc.DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(c)
+		<-c
+		server.Close()
+	}()
+
+	fmt.Printf("Signing for %v on %v\n", keyPair.Address, endpoint)
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	fmt.Println("signer successfully stopped")
+	return nil
+}
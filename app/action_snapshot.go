@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/zenon/snapshot"
+)
+
+var (
+	snapshotHeightFlag = &cli.Uint64Flag{
+		Name:  "height",
+		Usage: "Height of the exported momentum, which must be stable. Defaults to the last stable momentum",
+	}
+	snapshotHistoryFlag = &cli.Uint64Flag{
+		Name:  "history",
+		Usage: "Number of momentums before the exported momentum which can be rollbacked after import",
+		Value: snapshot.DefaultHistory,
+	}
+
+	snapshotCommand = &cli.Command{
+		Name:     "snapshot",
+		Usage:    "Export and import the state of the chain for fast bootstrap",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    snapshotExportAction,
+				Name:      "export",
+				Usage:     "Export the state at a momentum into a snapshot file. znnd must be stopped",
+				ArgsUsage: "<file>",
+				Flags:     []cli.Flag{snapshotHeightFlag, snapshotHistoryFlag},
+			},
+			{
+				Action:    snapshotImportAction,
+				Name:      "import",
+				Usage:     "Seed a fresh data dir with the state from a snapshot file",
+				ArgsUsage: "<file>",
+			},
+			{
+				Action:    snapshotVerifyAction,
+				Name:      "verify",
+				Usage:     "Check the integrity of a snapshot file and print its header",
+				ArgsUsage: "<file>",
+			},
+		},
+	}
+)
+
+func snapshotFile(ctx *cli.Context) (string, error) {
+	if ctx.Args().Len() != 1 {
+		return "", fmt.Errorf("expected the snapshot file as the only argument")
+	}
+	return ctx.Args().First(), nil
+}
+func printSnapshotHeader(header *snapshot.Header) {
+	fmt.Printf(`Snapshot
+Version:%v
+Chain identifier:%v
+Momentum height:%v
+Momentum hash:%v
+Momentum time:%v
+State digest:%v
+`, header.Version, header.ChainIdentifier, header.Momentum.Height, header.Momentum.Hash,
+		time.Unix(header.Timestamp, 0).UTC().Format(time.RFC3339), header.StateDigest)
+}
+
+func snapshotExportAction(ctx *cli.Context) error {
+	file, err := snapshotFile(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exporting snapshot to %v ...\n", file)
+	header, err := snapshot.Export(cfg.DataPath, ctx.Uint64(snapshotHeightFlag.Name), ctx.Uint64(snapshotHistoryFlag.Name), file)
+	if err != nil {
+		return err
+	}
+	printSnapshotHeader(header)
+	return nil
+}
+func snapshotImportAction(ctx *cli.Context) error {
+	file, err := snapshotFile(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	backend, err := db.ParseBackend(cfg.Database.Backend)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing snapshot from %v ...\n", file)
+	header, err := snapshot.Import(cfg.DataPath, backend, file)
+	if err != nil {
+		return err
+	}
+	printSnapshotHeader(header)
+	fmt.Printf("Check the momentum hash and the state digest against a trusted node before starting znnd\n")
+	return nil
+}
+func snapshotVerifyAction(ctx *cli.Context) error {
+	file, err := snapshotFile(ctx)
+	if err != nil {
+		return err
+	}
+
+	header, err := snapshot.Verify(file)
+	if err != nil {
+		return err
+	}
+	printSnapshotHeader(header)
+	return nil
+}
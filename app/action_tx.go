@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/offline"
+)
+
+const (
+	// the password isn't accepted as a flag, since the command line is visible to the other users of the host
+	txPasswordEnv = "ZNN_KEYFILE_PASSWORD"
+)
+
+var (
+	txKeyFileFlag = &cli.StringFlag{
+		Name:     "keyfile",
+		Usage:    "Path of the key file of the account",
+		Required: true,
+	}
+	txPasswordFileFlag = &cli.StringFlag{
+		Name:  "password-file",
+		Usage: "File holding the password of the key file. The password can also be set in the " + txPasswordEnv + " environment variable",
+	}
+	txIndexFlag = &cli.UintFlag{
+		Name:  "index",
+		Usage: "Index of the address inside the key file",
+	}
+	txOutFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "File in which the signed block is written",
+		Required: true,
+	}
+
+	txCommand = &cli.Command{
+		Name:     "tx",
+		Usage:    "Build transactions offline",
+		Category: "WALLET COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    txBuildAction,
+				Name:      "build",
+				Usage:     "Build, compute the PoW and sign an account-block from a JSON description, without access to a node. The output can be published with ledger.publishRawTransaction",
+				ArgsUsage: "<description-file>",
+				Flags:     []cli.Flag{txKeyFileFlag, txPasswordFileFlag, txIndexFlag, txOutFlag},
+			},
+		},
+	}
+)
+
+func txBuildAction(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return fmt.Errorf("expected the description file as the only argument")
+	}
+	data, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	description := new(offline.Description)
+	if err := json.Unmarshal(data, description); err != nil {
+		return fmt.Errorf("invalid description. Reason: %w", err)
+	}
+
+	keyFile, err := wallet.ReadKeyFile(ctx.String(txKeyFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	password, err := txPassword(ctx)
+	if err != nil {
+		return err
+	}
+	keyStore, err := keyFile.Decrypt(password)
+	if err != nil {
+		return err
+	}
+	defer keyStore.Zero()
+	_, keyPair, err := keyStore.DeriveForIndexPath(uint32(ctx.Uint(txIndexFlag.Name)))
+	if err != nil {
+		return err
+	}
+
+	block, err := offline.Build(description, keyPair)
+	if err != nil {
+		return err
+	}
+	output, err := offline.Marshal(block)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(ctx.String(txOutFlag.Name), output, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Signed block %v of %v at height %v\n", block.Hash, block.Address, block.Height)
+	return nil
+}
+
+// txPassword reads the password of the key file from the password file or the environment
+func txPassword(ctx *cli.Context) (string, error) {
+	if file := ctx.String(txPasswordFileFlag.Name); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv(txPasswordEnv); ok {
+		return password, nil
+	}
+	return "", fmt.Errorf("the password of the key file must be set with --%v or in the %v environment variable", txPasswordFileFlag.Name, txPasswordEnv)
+}
@@ -54,6 +54,12 @@ func init() {
 	app.Commands = []*cli.Command{
 		versionCommand,
 		licenseCommand,
+		snapshotCommand,
+		dbCommand,
+		reindexCommand,
+		rollbackCommand,
+		signerCommand,
+		txCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
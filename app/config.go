@@ -104,6 +104,44 @@ func applyFlagsToConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.RPC.WSPort = ctx.Int(WSPortFlag.Name)
 	}
 
+	if ctx.IsSet(RPCDecodeDataFlag.Name) {
+		cfg.RPC.DecodeBlockData = ctx.Bool(RPCDecodeDataFlag.Name)
+	}
+
+	// Index Config
+	if ctx.IsSet(AddressIndexFlag.Name) {
+		cfg.Index.EnableAddressIndex = ctx.Bool(AddressIndexFlag.Name)
+	}
+	if ctx.IsSet(HolderIndexFlag.Name) {
+		cfg.Index.EnableHolderIndex = ctx.Bool(HolderIndexFlag.Name)
+	}
+	if ctx.IsSet(HtlcIndexFlag.Name) {
+		cfg.Index.EnableHtlcIndex = ctx.Bool(HtlcIndexFlag.Name)
+	}
+
+	// Pruning Config
+	if ctx.IsSet(PruneRetentionFlag.Name) {
+		cfg.Pruning.Retention = ctx.Uint64(PruneRetentionFlag.Name)
+	}
+
+	// Database Config
+	if ctx.IsSet(DBBackendFlag.Name) {
+		cfg.Database.Backend = ctx.String(DBBackendFlag.Name)
+	}
+
+	// Metrics Config
+	if ctx.IsSet(MetricsEnabledFlag.Name) {
+		cfg.Metrics.Enabled = ctx.Bool(MetricsEnabledFlag.Name)
+	}
+
+	if metricsHost := ctx.String(MetricsListenAddrFlag.Name); ctx.IsSet(MetricsListenAddrFlag.Name) && len(metricsHost) > 0 {
+		cfg.Metrics.Host = metricsHost
+	}
+
+	if ctx.IsSet(MetricsPortFlag.Name) {
+		cfg.Metrics.Port = ctx.Int(MetricsPortFlag.Name)
+	}
+
 	// Log Level Config
 	if logLevel := ctx.String(LogLvlFlag.Name); ctx.IsSet(LogLvlFlag.Name) && len(logLevel) > 0 {
 		cfg.LogLevel = logLevel
@@ -108,6 +108,55 @@ var (
 		Usage: "WS-RPC server listening port",
 		Value: p2p.DefaultWSPort,
 	}
+	RPCDecodeDataFlag = &cli.BoolFlag{
+		Name:  "rpc-decode-data",
+		Usage: "Include by default the decoded calls to embedded contracts in the account-blocks returned by the ledger API; each call can override it with its last, optional, decode parameter",
+	}
+
+	// index
+
+	AddressIndexFlag = &cli.BoolFlag{
+		Name:  "address-index",
+		Usage: "Enable the address index used to query the account-blocks which involve an address",
+	}
+	HolderIndexFlag = &cli.BoolFlag{
+		Name:  "holder-index",
+		Usage: "Enable the holder index used to query the holders and the supply distribution of a token",
+	}
+	HtlcIndexFlag = &cli.BoolFlag{
+		Name:  "htlc-index",
+		Usage: "Enable the htlc index used to list the htlcs of an address, including the unlocked and reclaimed ones",
+	}
+
+	// pruning
+
+	PruneRetentionFlag = &cli.Uint64Flag{
+		Name:  "prune-retention",
+		Usage: "Number of recent momentums for which account-blocks are kept. Older account-blocks are pruned. 0 keeps the full history",
+	}
+
+	// database
+
+	DBBackendFlag = &cli.StringFlag{
+		Name:  "db-backend",
+		Usage: "Key-value engine of the databases: leveldb or pebble. Existing data dirs must be converted with 'znnd db migrate'",
+	}
+
+	// metrics
+
+	MetricsEnabledFlag = &cli.BoolFlag{
+		Name:  "metrics",
+		Usage: "Enable the metrics collection and the Prometheus metrics endpoint",
+	}
+	MetricsListenAddrFlag = &cli.StringFlag{
+		Name:  "metrics-addr",
+		Usage: "Metrics server listening interface",
+	}
+	MetricsPortFlag = &cli.IntFlag{
+		Name:  "metrics-port",
+		Usage: "Metrics server listening port",
+		Value: p2p.DefaultMetricsPort,
+	}
 
 	// log
 
@@ -147,6 +196,23 @@ var (
 		WSEnabledFlag,
 		WSListenAddrFlag,
 		WSPortFlag,
+		RPCDecodeDataFlag,
+
+		// index
+		AddressIndexFlag,
+		HolderIndexFlag,
+		HtlcIndexFlag,
+
+		// pruning
+		PruneRetentionFlag,
+
+		// database
+		DBBackendFlag,
+
+		// metrics
+		MetricsEnabledFlag,
+		MetricsListenAddrFlag,
+		MetricsPortFlag,
 
 		// log
 		LogLvlFlag,
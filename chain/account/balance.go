@@ -16,6 +16,15 @@ func getBalancePrefix() []byte {
 	return common.JoinBytes(balanceKeyPrefix)
 }
 
+// ParseBalanceKey returns the token standard of key if key is the key of a balance in the account store
+func ParseBalanceKey(key []byte) (types.ZenonTokenStandard, bool) {
+	if len(key) != len(balanceKeyPrefix)+types.ZenonTokenStandardSize || key[0] != balanceKeyPrefix[0] {
+		return types.ZeroTokenStandard, false
+	}
+	zts, err := types.BytesToZTS(key[len(balanceKeyPrefix):])
+	return zts, err == nil
+}
+
 func (as *accountStore) GetBalance(zts types.ZenonTokenStandard) (*big.Int, error) {
 	data, err := as.DB.Get(getBalanceKey(zts))
 	if err == leveldb.ErrNotFound {
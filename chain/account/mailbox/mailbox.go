@@ -1,6 +1,7 @@
 package mailbox
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/syndtr/goleveldb/leveldb"
@@ -82,22 +83,32 @@ func (m *mailbox) GetBlockWhichReceives(fromHash types.Hash) *types.AccountHeade
 	return parseAccountHeader(m.DB.Get(getBlockWhichReceivesKey(fromHash)))
 }
 func (m *mailbox) GetUnreceivedAccountBlockHashes(atMost uint64) ([]types.Hash, error) {
+	return m.GetUnreceivedAccountBlockHashesAfter(nil, atMost)
+}
+
+// GetUnreceivedAccountBlockHashesAfter returns at most atMost hashes, in ascending order, starting with the first hash
+// greater than after. A nil after starts with the first unreceived block.
+func (m *mailbox) GetUnreceivedAccountBlockHashesAfter(after *types.Hash, atMost uint64) ([]types.Hash, error) {
 	iterator := m.DB.NewIterator(getPendingBlocksIterator())
 	defer iterator.Release()
 	list := make([]types.Hash, 0)
 
-	for {
-		if !iterator.Next() {
-			if iterator.Error() != nil {
-				return nil, iterator.Error()
-			}
-			break
+	var ok bool
+	if after == nil {
+		ok = iterator.Next()
+	} else {
+		// the pending blocks up to the cursor are not visited
+		afterKey := getPendingBlockKey(*after)
+		ok = db.Seek(iterator, afterKey)
+		if ok && bytes.Equal(iterator.Key(), afterKey) {
+			ok = iterator.Next()
 		}
+	}
+	for ; ok; ok = iterator.Next() {
 		if iterator.Value() == nil {
 			continue
 		}
 		hash, err := types.BytesToHash(iterator.Key()[1:])
-
 		if err != nil {
 			return nil, err
 		}
@@ -108,6 +119,9 @@ func (m *mailbox) GetUnreceivedAccountBlockHashes(atMost uint64) ([]types.Hash,
 			return list, nil
 		}
 	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
 	return list, nil
 }
 
@@ -3,7 +3,9 @@ package chain
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
@@ -34,6 +36,10 @@ type accountPool struct {
 	stable   Stable
 	managers map[types.Address]db.Manager
 	changes  sync.Mutex
+
+	// added holds the uncommitted account-blocks with the time at which they were added to the pool
+	added    map[types.Hash]*PooledAccountBlock
+	rejected rejectedAccountBlocks
 }
 
 func (ap *accountPool) getAccountManager(address types.Address) db.Manager {
@@ -97,7 +103,7 @@ func (ap *accountPool) AddAccountBlockTransaction(insertLocker sync.Locker, tran
 	}
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
-	return ap.addAccountBlockTransaction(transaction, false)
+	return ap.reject(transaction.Block, ap.addAccountBlockTransaction(transaction, false))
 }
 func (ap *accountPool) ForceAddAccountBlockTransaction(insertLocker sync.Locker, transaction *nom.AccountBlockTransaction) error {
 	if insertLocker == nil {
@@ -105,7 +111,19 @@ func (ap *accountPool) ForceAddAccountBlockTransaction(insertLocker sync.Locker,
 	}
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
-	return ap.addAccountBlockTransaction(transaction, true)
+	return ap.reject(transaction.Block, ap.addAccountBlockTransaction(transaction, true))
+}
+
+// reject records the account-block if it couldn't be added to the pool, and returns err
+func (ap *accountPool) reject(block *nom.AccountBlock, err error) error {
+	if err != nil {
+		ap.rejected.add(&RejectedAccountBlock{
+			Block:  block,
+			Reason: err,
+			Time:   time.Now(),
+		})
+	}
+	return err
 }
 func (ap *accountPool) addAccountBlockTransaction(transaction *nom.AccountBlockTransaction, forceAdd bool) error {
 	block := transaction.Block
@@ -121,7 +139,7 @@ func (ap *accountPool) addAccountBlockTransaction(transaction *nom.AccountBlockT
 	// fast-forward insert on top of chain
 	if previous == frontierIdentifier {
 		log.Info("fast-forward inserting account-block")
-		return ap.getAccountManager(address).Add(transaction)
+		return ap.add(transaction)
 	}
 
 	// already inserted
@@ -156,10 +174,37 @@ func (ap *accountPool) addAccountBlockTransaction(transaction *nom.AccountBlockT
 			log.Info("failed to insert account-block-transaction. can't pop manager", "reason", err, "frontier-identifier", currentIdentifier)
 			return fmt.Errorf(`%w can't pop manager; reason:%v; frontier-identifier:%v; identifier:%v`, ErrFailedToAddAccountBlockTransaction, err, currentIdentifier, identifier)
 		}
+		ap.drop(currentIdentifier.Hash, ErrAccountBlockReplaced, &identifier)
 	}
 
 	log.Info("inserting account-block after rollback")
-	return ap.getAccountManager(address).Add(transaction)
+	return ap.add(transaction)
+}
+func (ap *accountPool) add(transaction *nom.AccountBlockTransaction) error {
+	if err := ap.getAccountManager(transaction.Block.Address).Add(transaction); err != nil {
+		return err
+	}
+	ap.added[transaction.Block.Hash] = &PooledAccountBlock{
+		Block: transaction.Block,
+		Added: time.Now(),
+	}
+	return nil
+}
+
+// drop records the uncommitted account-block as rejected and forgets it. Account-blocks which weren't added through
+// the pool are not recorded.
+func (ap *accountPool) drop(hash types.Hash, reason error, replacedBy *types.HashHeight) {
+	pooled, ok := ap.added[hash]
+	if !ok {
+		return
+	}
+	delete(ap.added, hash)
+	ap.rejected.add(&RejectedAccountBlock{
+		Block:      pooled.Block,
+		Reason:     reason,
+		ReplacedBy: replacedBy,
+		Time:       time.Now(),
+	})
 }
 
 func (ap *accountPool) GetPatch(address types.Address, identifier types.HashHeight) db.Patch {
@@ -208,17 +253,60 @@ func (ap *accountPool) InsertMomentum(detailed *nom.DetailedMomentum) {
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
 
-	if err := ap.rebuild(detailed); err != nil {
-		common.ChainLogger.Error("failed to handle InsertMomentum in AccountPool", "reason", err)
+	ap.rebuild(detailed)
+
+	// forget the account-blocks which were committed, and reject the ones dropped by the rebuild
+	added := make(map[types.Hash]*PooledAccountBlock)
+	for address := range ap.managers {
+		for _, block := range ap.getUncommittedAccountBlocksByAddress(address) {
+			if pooled, ok := ap.added[block.Hash]; ok {
+				added[block.Hash] = pooled
+				delete(ap.added, block.Hash)
+			}
+		}
 	}
+	dropped := make([]*nom.AccountBlock, 0)
+	for _, pooled := range ap.added {
+		dropped = append(dropped, pooled.Block)
+	}
+	sort.Slice(dropped, func(i, j int) bool {
+		if dropped[i].Address != dropped[j].Address {
+			return bytes.Compare(dropped[i].Address.Bytes(), dropped[j].Address.Bytes()) < 0
+		}
+		return dropped[i].Height < dropped[j].Height
+	})
+	for _, block := range dropped {
+		committed, err := ap.getStableAccountStore(block.Address).ByHeight(block.Height)
+		if err != nil {
+			ap.log.Error("failed to get committed account-block", "reason", err, "identifier", block.Header())
+			continue
+		}
+		if committed == nil {
+			ap.drop(block.Hash, ErrPreviousBlockDropped, nil)
+		} else if committed.Hash != block.Hash {
+			identifier := committed.Identifier()
+			ap.drop(block.Hash, ErrAccountBlockConflict, &identifier)
+		}
+	}
+	ap.added = added
 }
 func (ap *accountPool) DeleteMomentum(*nom.DetailedMomentum) {
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
 
+	// the uncommitted account-blocks are dropped with the managers
+	for address := range ap.managers {
+		for _, block := range ap.getUncommittedAccountBlocksByAddress(address) {
+			ap.drop(block.Hash, ErrMomentumRolledBack, nil)
+		}
+	}
 	ap.managers = make(map[types.Address]db.Manager)
+	ap.added = make(map[types.Hash]*PooledAccountBlock)
 }
-func (ap *accountPool) rebuild(detailed *nom.DetailedMomentum) error {
+
+// rebuild re-applies the uncommitted account-blocks on top of the new stable state. The account-blocks which no longer
+// link, since the momentum confirmed a conflicting account-block, are dropped together with the ones built on them.
+func (ap *accountPool) rebuild(detailed *nom.DetailedMomentum) {
 	addresses := make([]types.Address, 0, len(ap.managers))
 	for address := range ap.managers {
 		addresses = append(addresses, address)
@@ -256,7 +344,8 @@ func (ap *accountPool) rebuild(detailed *nom.DetailedMomentum) error {
 				Changes: patch,
 			})
 			if err != nil {
-				return errors.Errorf("account pool rebuild error. Unable to re-apply block %v. Reason %v", block.Header(), err)
+				log.Info("dropping uncommitted account-blocks", "reason", err, "identifier", block.Header())
+				break
 			}
 		}
 		ap.managers[address] = manager
@@ -265,7 +354,6 @@ func (ap *accountPool) rebuild(detailed *nom.DetailedMomentum) error {
 	}
 
 	ap.log.Debug("finished rebuilding account-pool")
-	return nil
 }
 
 func (ap *accountPool) GetNewMomentumContent() []*nom.AccountBlock {
@@ -304,6 +392,45 @@ func (ap *accountPool) GetUncommittedAccountBlocksByAddress(address types.Addres
 
 	return ap.getUncommittedAccountBlocksByAddress(address)
 }
+func (ap *accountPool) GetPooledAccountBlocks() []*PooledAccountBlock {
+	ap.changes.Lock()
+	defer ap.changes.Unlock()
+
+	addresses := make([]types.Address, 0, len(ap.managers))
+	for address := range ap.managers {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i].Bytes(), addresses[j].Bytes()) < 0
+	})
+
+	blocks := make([]*nom.AccountBlock, 0)
+	for _, address := range addresses {
+		blocks = append(blocks, ap.getUncommittedAccountBlocksByAddress(address)...)
+	}
+	ready := make(map[types.Hash]bool)
+	for _, block := range ap.filterBlocksToCommit(blocks) {
+		ready[block.Hash] = true
+	}
+
+	pooled := make([]*PooledAccountBlock, len(blocks))
+	for index, block := range blocks {
+		pooled[index] = &PooledAccountBlock{
+			Block: block,
+			Ready: ready[block.Hash],
+		}
+		if entry, ok := ap.added[block.Hash]; ok {
+			pooled[index].Added = entry.Added
+		}
+	}
+	return pooled
+}
+func (ap *accountPool) GetRejectedAccountBlocks() []*RejectedAccountBlock {
+	ap.changes.Lock()
+	defer ap.changes.Unlock()
+
+	return ap.rejected.list()
+}
 func (ap *accountPool) getUncommittedAccountBlocksByAddress(address types.Address) []*nom.AccountBlock {
 	blocks := make([]*nom.AccountBlock, 0)
 
@@ -323,6 +450,7 @@ func newAccountPool(stable Stable) *accountPool {
 		log:      common.ChainLogger.New("module", "account-pool"),
 		stable:   stable,
 		managers: make(map[types.Address]db.Manager),
+		added:    make(map[types.Hash]*PooledAccountBlock),
 	}
 }
 func NewAccountPool(stable Stable) AccountPool {
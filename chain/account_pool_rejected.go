@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	ErrAccountBlockReplaced = errors.Errorf("rolled back to insert a conflicting account-block")
+	ErrMomentumRolledBack   = errors.Errorf("dropped by the rollback of a momentum")
+	ErrAccountBlockConflict = errors.Errorf("dropped for a conflicting account-block confirmed by a momentum")
+	ErrPreviousBlockDropped = errors.Errorf("dropped with its previous account-block")
+
+	// MaxRejectedAccountBlocks is the number of dropped account-blocks remembered by the account pool
+	MaxRejectedAccountBlocks = 256
+)
+
+// PooledAccountBlock is an uncommitted account-block. Ready is set if the account-block fits in the next momentum, and
+// Added is the time at which it was added to the pool, which is zero if it wasn't added through the pool.
+type PooledAccountBlock struct {
+	Block *nom.AccountBlock
+	Ready bool
+	Added time.Time
+}
+
+// RejectedAccountBlock is an account-block dropped by the account pool, either when it was added, or once it was
+// uncommitted and rolled back
+type RejectedAccountBlock struct {
+	Block  *nom.AccountBlock
+	Reason error
+	// ReplacedBy is the account-block inserted instead of the rolled back one
+	ReplacedBy *types.HashHeight
+	Time       time.Time
+}
+
+// rejectedAccountBlocks is a ring buffer which keeps the last MaxRejectedAccountBlocks rejected account-blocks
+type rejectedAccountBlocks struct {
+	entries []*RejectedAccountBlock
+	next    int
+}
+
+// add records the rejected account-block. An account-block rejected again for the same reason, which happens when it
+// is received from several peers, is only recorded once.
+func (r *rejectedAccountBlocks) add(entry *RejectedAccountBlock) {
+	for _, previous := range r.entries {
+		if previous.Block.Hash == entry.Block.Hash && previous.Reason.Error() == entry.Reason.Error() {
+			return
+		}
+	}
+	if len(r.entries) < MaxRejectedAccountBlocks {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+}
+
+// list returns the rejected account-blocks, the most recently rejected first
+func (r *rejectedAccountBlocks) list() []*RejectedAccountBlock {
+	list := make([]*RejectedAccountBlock, 0, len(r.entries))
+	for i := len(r.entries) - 1; i >= 0; i -= 1 {
+		list = append(list, r.entries[(r.next+i)%len(r.entries)])
+	}
+	return list
+}
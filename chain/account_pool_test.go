@@ -1,10 +1,15 @@
 package chain
 
 import (
+	"fmt"
+	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
 )
 
 func TestAccountPool_filterBlocksToCommit(t *testing.T) {
@@ -22,3 +27,101 @@ func TestAccountPool_filterBlocksToCommit(t *testing.T) {
 		{Height: 3, BlockType: nom.BlockTypeUserReceive},
 	})), 0)
 }
+
+func TestAccountPool_rejectedAccountBlocks(t *testing.T) {
+	defer func(max int) { MaxRejectedAccountBlocks = max }(MaxRejectedAccountBlocks)
+	MaxRejectedAccountBlocks = 3
+
+	rejected := rejectedAccountBlocks{}
+	heights := func() []uint64 {
+		list := make([]uint64, 0)
+		for _, entry := range rejected.list() {
+			list = append(list, entry.Block.Height)
+		}
+		return list
+	}
+	for height := uint64(1); height <= 5; height += 1 {
+		rejected.add(&RejectedAccountBlock{
+			Block:  &nom.AccountBlock{Height: height, Hash: types.HexToHashPanic(fmt.Sprintf("%064x", height))},
+			Reason: ErrHashTieBreak,
+		})
+		if height == 2 {
+			common.Expect(t, heights(), []uint64{2, 1})
+		}
+	}
+	common.Expect(t, heights(), []uint64{5, 4, 3})
+
+	// the same rejection is recorded once
+	rejected.add(&RejectedAccountBlock{
+		Block:  &nom.AccountBlock{Height: 4, Hash: types.HexToHashPanic(fmt.Sprintf("%064x", 4))},
+		Reason: ErrHashTieBreak,
+	})
+	common.Expect(t, heights(), []uint64{5, 4, 3})
+	rejected.add(&RejectedAccountBlock{
+		Block:  &nom.AccountBlock{Height: 4, Hash: types.HexToHashPanic(fmt.Sprintf("%064x", 4))},
+		Reason: ErrAccountBlockReplaced,
+	})
+	common.Expect(t, heights(), []uint64{4, 5, 4})
+}
+
+type testStable struct {
+	dbs map[types.Address]db.DB
+}
+
+func (s *testStable) GetStableAccountDB(address types.Address) db.DB {
+	if s.dbs[address] == nil {
+		s.dbs[address] = db.NewMemDB()
+	}
+	return s.dbs[address]
+}
+
+func newTestAccountBlock(address types.Address, previous types.HashHeight, amount int64) *nom.AccountBlock {
+	block := &nom.AccountBlock{
+		BlockType:    nom.BlockTypeUserSend,
+		Address:      address,
+		Height:       previous.Height + 1,
+		PreviousHash: previous.Hash,
+		Amount:       big.NewInt(amount),
+		TotalPlasma:  21000,
+		BasePlasma:   21000,
+	}
+	block.Hash = block.ComputeHash()
+	return block
+}
+
+func TestAccountPool_rejectConflictingMomentum(t *testing.T) {
+	address := types.ParseAddressPanic("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
+	stable := &testStable{dbs: make(map[types.Address]db.DB)}
+	ap := newAccountPool(stable)
+	insert := new(sync.Mutex)
+
+	first := newTestAccountBlock(address, types.ZeroHashHeight, 1)
+	second := newTestAccountBlock(address, first.Identifier(), 2)
+	common.FailIfErr(t, ap.AddAccountBlockTransaction(insert, &nom.AccountBlockTransaction{Block: first, Changes: db.NewPatch()}))
+	common.FailIfErr(t, ap.AddAccountBlockTransaction(insert, &nom.AccountBlockTransaction{Block: second, Changes: db.NewPatch()}))
+	common.Expect(t, len(ap.GetPooledAccountBlocks()), 2)
+
+	// the momentum confirms a conflicting account-block at the height of the first one
+	conflicting := newTestAccountBlock(address, types.ZeroHashHeight, 3)
+	data, err := conflicting.Serialize()
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, db.SetFrontier(stable.GetStableAccountDB(address), conflicting.Identifier(), data))
+	ap.InsertMomentum(&nom.DetailedMomentum{Momentum: &nom.Momentum{}})
+
+	common.Expect(t, len(ap.GetPooledAccountBlocks()), 0)
+	rejected := ap.GetRejectedAccountBlocks()
+	common.Expect(t, len(rejected), 2)
+	common.Expect(t, rejected[0].Block.Hash, second.Hash)
+	common.Expect(t, rejected[0].Reason, ErrPreviousBlockDropped)
+	common.Expect(t, rejected[1].Block.Hash, first.Hash)
+	common.Expect(t, rejected[1].Reason, ErrAccountBlockConflict)
+	common.Expect(t, *rejected[1].ReplacedBy, conflicting.Identifier())
+
+	// the pool accepts account-blocks on top of the confirmed one
+	third := newTestAccountBlock(address, conflicting.Identifier(), 4)
+	common.FailIfErr(t, ap.AddAccountBlockTransaction(insert, &nom.AccountBlockTransaction{Block: third, Changes: db.NewPatch()}))
+	pooled := ap.GetPooledAccountBlocks()
+	common.Expect(t, len(pooled), 1)
+	common.Expect(t, pooled[0].Block.Hash, third.Hash)
+	common.ExpectTrue(t, !pooled[0].Added.IsZero())
+}
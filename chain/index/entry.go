@@ -0,0 +1,136 @@
+package index
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	entryHeaderSize = types.HashSize + 8 + 8 + 8 + types.ZenonTokenStandardSize + 1
+	// cursor is the momentum height and the position inside the momentum of an entry
+	cursorSize = 8 + 4
+)
+
+var (
+	frontierKey = []byte{0}
+	entryPrefix = []byte{1}
+
+	errInvalidEntry  = errors.New("invalid address-index entry")
+	ErrInvalidCursor = errors.New("invalid address-index cursor")
+	ErrInvalidCount  = errors.New("address-index count must be greater than zero")
+)
+
+// Entry links an address to an account-block which involves it.
+//   - TokenStandard is the token transferred by the block; for receive-blocks it is the token of the send-block
+//   - Method is the name of the embedded method called, empty if the block doesn't call an embedded contract
+//   - Incoming is true for send-blocks made by other accounts to this address
+type Entry struct {
+	BlockHash         types.Hash
+	BlockType         uint64
+	MomentumHeight    uint64
+	MomentumTimestamp int64
+	TokenStandard     types.ZenonTokenStandard
+	Method            string
+	Incoming          bool
+}
+
+func (e *Entry) serialize() []byte {
+	incoming := byte(0)
+	if e.Incoming {
+		incoming = 1
+	}
+	return common.JoinBytes(
+		e.BlockHash.Bytes(),
+		common.Uint64ToBytes(e.BlockType),
+		common.Uint64ToBytes(e.MomentumHeight),
+		common.Uint64ToBytes(uint64(e.MomentumTimestamp)),
+		e.TokenStandard.Bytes(),
+		[]byte{incoming},
+		[]byte(e.Method),
+	)
+}
+func deserializeEntry(data []byte) (*Entry, error) {
+	if len(data) < entryHeaderSize {
+		return nil, errInvalidEntry
+	}
+	e := &Entry{}
+	var err error
+	if e.BlockHash, err = types.BytesToHash(data[0:32]); err != nil {
+		return nil, err
+	}
+	e.BlockType = common.BytesToUint64(data[32:40])
+	e.MomentumHeight = common.BytesToUint64(data[40:48])
+	e.MomentumTimestamp = int64(common.BytesToUint64(data[48:56]))
+	if e.TokenStandard, err = types.BytesToZTS(data[56:66]); err != nil {
+		return nil, err
+	}
+	e.Incoming = data[66] == 1
+	e.Method = string(data[entryHeaderSize:])
+	return e, nil
+}
+
+func getAddressPrefix(address types.Address) []byte {
+	return common.JoinBytes(entryPrefix, address.Bytes())
+}
+func getHeightPrefix(address types.Address, height uint64) []byte {
+	return common.JoinBytes(getAddressPrefix(address), common.Uint64ToBytes(height))
+}
+func getEntryKey(address types.Address, height uint64, position uint32) []byte {
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, position)
+	return common.JoinBytes(getHeightPrefix(address, height), seq)
+}
+
+// Filter restricts the entries returned by a query. Zero values mean no restriction.
+type Filter struct {
+	TokenStandard *types.ZenonTokenStandard `json:"tokenStandard"`
+	BlockTypes    []uint64                  `json:"blockTypes"`
+	Methods       []string                  `json:"methods"`
+	Incoming      *bool                     `json:"incoming"`
+
+	FromHeight uint64 `json:"fromHeight"`
+	ToHeight   uint64 `json:"toHeight"`
+	FromTime   int64  `json:"fromTime"`
+	ToTime     int64  `json:"toTime"`
+}
+
+func (f *Filter) matches(e *Entry) bool {
+	if f.TokenStandard != nil && *f.TokenStandard != e.TokenStandard {
+		return false
+	}
+	if f.Incoming != nil && *f.Incoming != e.Incoming {
+		return false
+	}
+	if f.FromTime != 0 && e.MomentumTimestamp < f.FromTime {
+		return false
+	}
+	if len(f.BlockTypes) != 0 {
+		found := false
+		for _, blockType := range f.BlockTypes {
+			if blockType == e.BlockType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Methods) != 0 {
+		found := false
+		for _, method := range f.Methods {
+			if method == e.Method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package index
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// momentumIndexer is an index built by applying the momentums of the chain in order
+type momentumIndexer interface {
+	chain.MomentumEventListener
+	// Frontier returns the identifier of the last indexed momentum
+	Frontier() (*types.HashHeight, error)
+	reset() error
+	// insert indexes the momentum, which has to follow the frontier of the index
+	insert(momentumStore store.Momentum, detailed *nom.DetailedMomentum) error
+	delete(detailed *nom.DetailedMomentum) error
+	// caughtUp is called with the insert lock acquired, once all momentums of the chain are indexed
+	caughtUp(momentumStore store.Momentum) error
+}
+
+// follower keeps a momentumIndexer in step with the chain. It indexes the momentums inserted while the index was disabled,
+// in batches, to not stall the insertion of new momentums, then listens for new momentums. If a new momentum can't be
+// indexed, it stops listening and catches up again from the frontier of the index, so the index never skips a momentum.
+// Failed batches are retried with an increasing delay, and the error is reported by Failure meanwhile.
+type follower struct {
+	log     common.Logger
+	chain   chain.Chain
+	name    string
+	indexer momentumIndexer
+
+	caughtUp     chan struct{}
+	caughtUpOnce sync.Once
+	stopped      chan struct{}
+	wg           sync.WaitGroup
+
+	// failure is the error which keeps the index from following the chain, nil if it follows it
+	failure   error
+	following bool
+	lock      sync.Mutex
+}
+
+func newFollower(log common.Logger, chain chain.Chain, name string, indexer momentumIndexer) *follower {
+	return &follower{
+		log:      log,
+		chain:    chain,
+		name:     name,
+		indexer:  indexer,
+		caughtUp: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (f *follower) start() {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.catchUp()
+	}()
+}
+func (f *follower) stop() {
+	f.lock.Lock()
+	close(f.stopped)
+	f.lock.Unlock()
+
+	f.wg.Wait()
+	f.chain.UnRegister(f.indexer)
+}
+
+func (f *follower) catchUp() {
+	delay := minRetryDelay
+	for {
+		select {
+		case <-f.stopped:
+			return
+		default:
+		}
+
+		done, err := f.catchUpBatch()
+		if err != nil {
+			f.setFailure(err)
+			f.log.Error("failed to catch up with the chain", "reason", err, "retry-in", delay)
+			select {
+			case <-f.stopped:
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			continue
+		}
+		delay = minRetryDelay
+		if done {
+			return
+		}
+	}
+}
+func (f *follower) catchUpBatch() (bool, error) {
+	insert := f.chain.AcquireInsert(f.name + " catch-up")
+	defer insert.Unlock()
+
+	momentumStore := f.chain.GetFrontierMomentumStore()
+	target := momentumStore.Identifier().Height
+	frontier, err := f.indexer.Frontier()
+	if err != nil {
+		return false, err
+	}
+	if frontier.Height != 0 {
+		momentum, err := momentumStore.GetMomentumByHeight(frontier.Height)
+		if err != nil {
+			return false, err
+		}
+		if momentum == nil || momentum.Hash != frontier.Hash {
+			// the chain was rollbacked while the index wasn't listening
+			f.log.Warn("index doesn't match the chain. Rebuilding it", "index-frontier", frontier)
+			if err := f.indexer.reset(); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+	}
+
+	for height := frontier.Height + 1; height <= target && height <= frontier.Height+catchUpBatchSize; height += 1 {
+		momentum, err := momentumStore.GetMomentumByHeight(height)
+		if err != nil {
+			return false, err
+		}
+		detailed, err := momentumStore.PrefetchMomentum(momentum)
+		if err != nil {
+			return false, err
+		}
+		if err := f.indexer.insert(momentumStore, detailed); err != nil {
+			return false, err
+		}
+	}
+
+	if frontier.Height+catchUpBatchSize < target {
+		f.log.Info("catching up with the chain", "indexed-height", frontier.Height+catchUpBatchSize, "frontier-height", target)
+		return false, nil
+	}
+	if err := f.indexer.caughtUp(momentumStore); err != nil {
+		return false, err
+	}
+
+	// the insert lock is still acquired so no momentum can be missed
+	f.chain.Register(f.indexer)
+	f.lock.Lock()
+	f.failure = nil
+	f.following = true
+	f.lock.Unlock()
+	f.caughtUpOnce.Do(func() { close(f.caughtUp) })
+	f.log.Info("caught up with the chain", "frontier-height", target)
+	return true, nil
+}
+
+// insertMomentum indexes the new momentum. If it fails, the index stops listening and catches up again, and Failure
+// reports the error until the index reaches the chain again.
+func (f *follower) insertMomentum(detailed *nom.DetailedMomentum) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.following {
+		return
+	}
+
+	err := f.indexer.insert(f.chain.GetFrontierMomentumStore(), detailed)
+	if err == nil {
+		return
+	}
+	f.log.Error("failed to index momentum. Catching up again", "reason", err, "identifier", detailed.Momentum.Identifier())
+	f.failure = err
+	f.following = false
+
+	select {
+	case <-f.stopped:
+		return
+	default:
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		// the listeners can't be changed while the momentum is broadcast
+		f.chain.UnRegister(f.indexer)
+		f.catchUp()
+	}()
+}
+func (f *follower) deleteMomentum(detailed *nom.DetailedMomentum) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.following {
+		return
+	}
+
+	if err := f.indexer.delete(detailed); err != nil {
+		f.log.Error("failed to remove momentum from index", "reason", err, "identifier", detailed.Momentum.Identifier())
+	}
+}
+
+// CaughtUp is closed once all momentums of the chain are indexed and new momentums are indexed as they are inserted
+func (f *follower) CaughtUp() <-chan struct{} {
+	return f.caughtUp
+}
+
+// Failure returns the error which keeps the index from following the chain, nil if it's not failing
+func (f *follower) Failure() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.failure
+}
+func (f *follower) setFailure(err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.failure = err
+}
+
+// checkFollows returns an error if the momentum isn't the one after the frontier of the index
+func checkFollows(frontier *types.HashHeight, momentum *nom.Momentum) error {
+	if momentum.Height != frontier.Height+1 || (frontier.Height != 0 && momentum.PreviousHash != frontier.Hash) {
+		return errors.Errorf("momentum %v doesn't follow the index frontier %v", momentum.Identifier(), frontier)
+	}
+	return nil
+}
@@ -0,0 +1,463 @@
+package index
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// balances are stored as 32 byte big-endian numbers so they can be used in keys
+	balanceSize = 32
+)
+
+var (
+	holderFrontierKey       = []byte{0}
+	holderBalancePrefix     = []byte{1}
+	holderRankPrefix        = []byte{2}
+	holderTokenPrefix       = []byte{3}
+	holderCountPrefix       = []byte{4}
+	holderCirculatingPrefix = []byte{5}
+
+	errBalanceTooBig = errors.New("balance doesn't fit in 32 bytes")
+)
+
+// Holder is an account with a non-zero balance of a token
+type Holder struct {
+	Address types.Address
+	Balance *big.Int
+}
+
+// HolderIndex keeps, for every token, the accounts with a non-zero balance sorted by balance, the number of holders
+// and the sum of the balances held by non-embedded accounts.
+//
+// The index is built from the state of the frontier momentum, so it doesn't depend on the account-block history and
+// works on pruned nodes. Afterwards, the balances of the accounts with account-blocks in a momentum are refreshed
+// every time a momentum is inserted or rollbacked.
+type HolderIndex struct {
+	log   common.Logger
+	chain chain.Chain
+	db    db.Storage
+
+	changes sync.Mutex
+	built   chan struct{}
+	stopped chan struct{}
+	wg      sync.WaitGroup
+
+	// failure is the error of the last attempt to build the index, nil if it succeeded
+	failure     error
+	failureLock sync.Mutex
+}
+
+func NewHolderIndex(chain chain.Chain, db db.Storage) *HolderIndex {
+	return &HolderIndex{
+		log:     common.ChainLogger.New("submodule", "holder-index"),
+		chain:   chain,
+		db:      db,
+		built:   make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (hi *HolderIndex) Init() error {
+	hi.log.Info("initializing ...")
+	defer hi.log.Info("initialized")
+	return nil
+}
+func (hi *HolderIndex) Start() error {
+	hi.log.Info("starting ...")
+	defer hi.log.Info("started")
+
+	hi.wg.Add(1)
+	go func() {
+		defer hi.wg.Done()
+		hi.buildWithRetry()
+	}()
+	return nil
+}
+func (hi *HolderIndex) Stop() error {
+	hi.log.Info("stopping ...")
+	defer hi.log.Info("stopped")
+
+	close(hi.stopped)
+	hi.wg.Wait()
+	hi.chain.UnRegister(hi)
+	return hi.db.Close()
+}
+
+// buildWithRetry retries the build with an increasing delay until it succeeds, reporting the error by Failure meanwhile
+func (hi *HolderIndex) buildWithRetry() {
+	delay := minRetryDelay
+	for {
+		err := hi.build()
+		hi.setFailure(err)
+		if err == nil {
+			return
+		}
+		hi.log.Error("failed to build the index", "reason", err, "retry-in", delay)
+		select {
+		case <-hi.stopped:
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// build rebuilds the index from the frontier state, unless the index is already at the frontier momentum.
+// The insert lock is held while the state is scanned, so no momentum can be missed.
+func (hi *HolderIndex) build() error {
+	insert := hi.chain.AcquireInsert("holder-index build")
+	defer insert.Unlock()
+
+	select {
+	case <-hi.stopped:
+		return nil
+	default:
+	}
+
+	momentumStore := hi.chain.GetFrontierMomentumStore()
+	frontier, err := hi.Frontier()
+	if err != nil {
+		return err
+	}
+	if *frontier != momentumStore.Identifier() {
+		hi.log.Info("building index from the frontier state", "index-frontier", frontier, "frontier", momentumStore.Identifier())
+		if err := hi.rebuild(momentumStore); err != nil {
+			return err
+		}
+	}
+
+	hi.chain.Register(hi)
+	close(hi.built)
+	hi.log.Info("index is up to date", "frontier-height", momentumStore.Identifier().Height)
+	return nil
+}
+
+// Built is closed once the index matches the frontier momentum and is updated as new momentums are inserted
+func (hi *HolderIndex) Built() <-chan struct{} {
+	return hi.built
+}
+
+// Failure returns the error which keeps the index from being built, nil if it's not failing
+func (hi *HolderIndex) Failure() error {
+	hi.failureLock.Lock()
+	defer hi.failureLock.Unlock()
+	return hi.failure
+}
+func (hi *HolderIndex) setFailure(err error) {
+	hi.failureLock.Lock()
+	defer hi.failureLock.Unlock()
+	hi.failure = err
+}
+
+// Frontier returns the identifier of the momentum the index is up to date with
+func (hi *HolderIndex) Frontier() (*types.HashHeight, error) {
+	return getHashHeight(hi.db, holderFrontierKey)
+}
+
+func getHolderBalanceKey(zts types.ZenonTokenStandard, address types.Address) []byte {
+	return common.JoinBytes(holderBalancePrefix, zts.Bytes(), address.Bytes())
+}
+func getHolderRankPrefix(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(holderRankPrefix, zts.Bytes())
+}
+
+// getHolderRankKey orders the holders of a token by balance in descending order, then by address
+func getHolderRankKey(zts types.ZenonTokenStandard, balance []byte, address types.Address) []byte {
+	inverted := make([]byte, balanceSize)
+	for i := range balance {
+		inverted[i] = ^balance[i]
+	}
+	return common.JoinBytes(getHolderRankPrefix(zts), inverted, address.Bytes())
+}
+func getHolderTokenPrefix(address types.Address) []byte {
+	return common.JoinBytes(holderTokenPrefix, address.Bytes())
+}
+func getHolderTokenKey(address types.Address, zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(getHolderTokenPrefix(address), zts.Bytes())
+}
+func getHolderCountKey(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(holderCountPrefix, zts.Bytes())
+}
+func getHolderCirculatingKey(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(holderCirculatingPrefix, zts.Bytes())
+}
+
+func balanceToBytes(balance *big.Int) ([]byte, error) {
+	if balance.BitLen() > balanceSize*8 {
+		return nil, errBalanceTooBig
+	}
+	return balance.FillBytes(make([]byte, balanceSize)), nil
+}
+
+// holderBatch accumulates the changes of the index. Totals are read from the db once and kept in memory until written.
+type holderBatch struct {
+	db          db.Storage
+	batch       *leveldb.Batch
+	counts      map[types.ZenonTokenStandard]uint64
+	circulating map[types.ZenonTokenStandard]*big.Int
+}
+
+func newHolderBatch(db db.Storage) *holderBatch {
+	return &holderBatch{
+		db:          db,
+		batch:       new(leveldb.Batch),
+		counts:      make(map[types.ZenonTokenStandard]uint64),
+		circulating: make(map[types.ZenonTokenStandard]*big.Int),
+	}
+}
+func (hb *holderBatch) loadTotals(zts types.ZenonTokenStandard) error {
+	if _, ok := hb.counts[zts]; ok {
+		return nil
+	}
+	count, err := getUint64(hb.db, getHolderCountKey(zts))
+	if err != nil {
+		return err
+	}
+	circulating, err := getBigInt(hb.db, getHolderCirculatingKey(zts))
+	if err != nil {
+		return err
+	}
+	hb.counts[zts] = count
+	hb.circulating[zts] = circulating
+	return nil
+}
+
+// set replaces the balance of address for zts, which is old according to the index
+func (hb *holderBatch) set(address types.Address, zts types.ZenonTokenStandard, old, balance *big.Int) error {
+	if old.Cmp(balance) == 0 {
+		return nil
+	}
+	if err := hb.loadTotals(zts); err != nil {
+		return err
+	}
+
+	if old.Sign() != 0 {
+		oldBytes, err := balanceToBytes(old)
+		if err != nil {
+			return err
+		}
+		hb.batch.Delete(getHolderRankKey(zts, oldBytes, address))
+		hb.counts[zts] -= 1
+	}
+	if balance.Sign() == 0 {
+		hb.batch.Delete(getHolderBalanceKey(zts, address))
+		hb.batch.Delete(getHolderTokenKey(address, zts))
+	} else {
+		balanceBytes, err := balanceToBytes(balance)
+		if err != nil {
+			return err
+		}
+		hb.batch.Put(getHolderBalanceKey(zts, address), balanceBytes)
+		hb.batch.Put(getHolderRankKey(zts, balanceBytes, address), []byte{})
+		hb.batch.Put(getHolderTokenKey(address, zts), []byte{})
+		hb.counts[zts] += 1
+	}
+	if !types.IsEmbeddedAddress(address) {
+		circulating := hb.circulating[zts]
+		circulating.Add(circulating, balance)
+		circulating.Sub(circulating, old)
+	}
+	return nil
+}
+func (hb *holderBatch) write(frontier types.HashHeight) error {
+	for zts, count := range hb.counts {
+		if count == 0 {
+			hb.batch.Delete(getHolderCountKey(zts))
+			hb.batch.Delete(getHolderCirculatingKey(zts))
+			continue
+		}
+		hb.batch.Put(getHolderCountKey(zts), common.Uint64ToBytes(count))
+		hb.batch.Put(getHolderCirculatingKey(zts), common.BigIntToBytes(hb.circulating[zts]))
+	}
+	hb.batch.Put(holderFrontierKey, frontier.Serialize())
+	return hb.db.Write(hb.batch, nil)
+}
+
+func getUint64(reader db.LevelDBLikeRO, key []byte) (uint64, error) {
+	data, err := reader.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return common.BytesToUint64(data), nil
+}
+func getBigInt(reader db.LevelDBLikeRO, key []byte) (*big.Int, error) {
+	data, err := reader.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return big.NewInt(0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.BytesToBigInt(data), nil
+}
+
+func (hi *HolderIndex) rebuild(momentumStore store.Momentum) error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	batch := new(leveldb.Batch)
+	iterator := hi.db.NewIterator(nil, nil)
+	for iterator.Next() {
+		batch.Delete(append([]byte{}, iterator.Key()...))
+	}
+	iterator.Release()
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	if err := hi.db.Write(batch, nil); err != nil {
+		return err
+	}
+
+	hb := newHolderBatch(hi.db)
+	if err := momentumStore.IterateBalances(func(address types.Address, zts types.ZenonTokenStandard, balance *big.Int) error {
+		return hb.set(address, zts, common.Big0, balance)
+	}); err != nil {
+		return err
+	}
+	return hb.write(momentumStore.Identifier())
+}
+
+// refresh updates the balances of all accounts with account-blocks in the momentum to the frontier state
+func (hi *HolderIndex) refresh(detailed *nom.DetailedMomentum) error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	momentumStore := hi.chain.GetFrontierMomentumStore()
+	hb := newHolderBatch(hi.db)
+	for address := range momentumAccounts(detailed) {
+		balances, err := momentumStore.GetAccountStore(address).GetBalanceMap()
+		if err != nil {
+			return err
+		}
+		// tokens held before the momentum, which are no longer held
+		iterator := hi.db.NewIterator(util.BytesPrefix(getHolderTokenPrefix(address)), nil)
+		for iterator.Next() {
+			zts, err := types.BytesToZTS(iterator.Key()[len(holderTokenPrefix)+types.AddressSize:])
+			if err != nil {
+				iterator.Release()
+				return err
+			}
+			if _, ok := balances[zts]; !ok {
+				balances[zts] = common.Big0
+			}
+		}
+		iterator.Release()
+		if err := iterator.Error(); err != nil {
+			return err
+		}
+
+		for zts, balance := range balances {
+			old, err := getBigInt(hi.db, getHolderBalanceKey(zts, address))
+			if err != nil {
+				return err
+			}
+			if err := hb.set(address, zts, old, balance); err != nil {
+				return err
+			}
+		}
+	}
+	return hb.write(momentumStore.Identifier())
+}
+
+// momentumAccounts returns the accounts which have account-blocks in the momentum, including the embedded contracts
+// which sent descendant blocks. Only these accounts can have a different balance after the momentum.
+func momentumAccounts(detailed *nom.DetailedMomentum) map[types.Address]bool {
+	addresses := make(map[types.Address]bool)
+	for _, header := range detailed.Momentum.Content {
+		addresses[header.Address] = true
+	}
+	return addresses
+}
+
+func (hi *HolderIndex) InsertMomentum(detailed *nom.DetailedMomentum) {
+	if err := hi.refresh(detailed); err != nil {
+		hi.log.Error("failed to index momentum", "reason", err, "identifier", detailed.Momentum.Identifier())
+	}
+}
+func (hi *HolderIndex) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	if err := hi.refresh(detailed); err != nil {
+		hi.log.Error("failed to remove momentum from index", "reason", err, "identifier", detailed.Momentum.Identifier())
+	}
+}
+
+// HolderSnapshot is a consistent view of the index, so the frontier, the totals and the holders read from it match
+type HolderSnapshot struct {
+	snapshot db.StorageSnapshot
+}
+
+// Snapshot returns a view of the current state of the index, which must be released after use
+func (hi *HolderIndex) Snapshot() (*HolderSnapshot, error) {
+	snapshot, err := hi.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &HolderSnapshot{snapshot: snapshot}, nil
+}
+func (hs *HolderSnapshot) Release() {
+	hs.snapshot.Release()
+}
+
+// Frontier returns the identifier of the momentum the snapshot is up to date with
+func (hs *HolderSnapshot) Frontier() (*types.HashHeight, error) {
+	return getHashHeight(hs.snapshot, holderFrontierKey)
+}
+
+// Holders returns count holders of zts, starting with the holder at position skip, in descending order of balance
+func (hs *HolderSnapshot) Holders(zts types.ZenonTokenStandard, skip, count int) ([]*Holder, error) {
+	iterator := hs.snapshot.NewIterator(util.BytesPrefix(getHolderRankPrefix(zts)), nil)
+	defer iterator.Release()
+
+	prefixSize := len(holderRankPrefix) + types.ZenonTokenStandardSize
+	list := make([]*Holder, 0, count)
+	for position := 0; len(list) < count && iterator.Next(); position += 1 {
+		if position < skip {
+			continue
+		}
+		key := iterator.Key()
+		inverted := key[prefixSize : prefixSize+balanceSize]
+		balance := make([]byte, balanceSize)
+		for i := range inverted {
+			balance[i] = ^inverted[i]
+		}
+		address, err := types.BytesToAddress(key[prefixSize+balanceSize:])
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &Holder{
+			Address: address,
+			Balance: new(big.Int).SetBytes(balance),
+		})
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// HolderCount returns the number of accounts with a non-zero balance of zts
+func (hs *HolderSnapshot) HolderCount(zts types.ZenonTokenStandard) (uint64, error) {
+	return getUint64(hs.snapshot, getHolderCountKey(zts))
+}
+
+// Circulating returns the sum of the balances of zts held by accounts which are not embedded contracts
+func (hs *HolderSnapshot) Circulating(zts types.ZenonTokenStandard) (*big.Int, error) {
+	return getBigInt(hs.snapshot, getHolderCirculatingKey(zts))
+}
@@ -0,0 +1,579 @@
+package index
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	HtlcActive uint8 = iota
+	HtlcUnlocked
+	HtlcReclaimed
+
+	// htlcCreated marks in the change log the htlcs created by a momentum
+	htlcCreated = 0xff
+	// a record starts with the status and the size of the preimage
+	htlcRecordHeaderSize = 1 + 8
+)
+
+var (
+	htlcFrontierKey      = []byte{0}
+	htlcRecordPrefix     = []byte{1}
+	htlcTimeLockedPrefix = []byte{2}
+	htlcHashLockedPrefix = []byte{3}
+	htlcHashLockPrefix   = []byte{4}
+	htlcCountPrefix      = []byte{5}
+	htlcChangePrefix     = []byte{6}
+
+	errInvalidHtlcRecord = errors.New("invalid htlc-index record")
+)
+
+// Htlc is an htlc together with the way it was resolved. Preimage is set once the htlc is unlocked
+type Htlc struct {
+	*definition.HtlcInfo
+	Status   uint8
+	Preimage []byte
+}
+
+func (h *Htlc) serialize() ([]byte, error) {
+	info, err := h.HtlcInfo.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return common.JoinBytes(
+		[]byte{h.Status},
+		common.Uint64ToBytes(uint64(len(h.Preimage))),
+		h.Preimage,
+		info,
+	), nil
+}
+func deserializeHtlc(id types.Hash, data []byte) (*Htlc, error) {
+	if len(data) < htlcRecordHeaderSize {
+		return nil, errInvalidHtlcRecord
+	}
+	size := common.BytesToUint64(data[1:htlcRecordHeaderSize])
+	if uint64(len(data)-htlcRecordHeaderSize) < size {
+		return nil, errInvalidHtlcRecord
+	}
+	// the hash lock is unpacked as a slice of the data
+	data = append([]byte{}, data...)
+	info, err := definition.DeserializeHtlcInfo(id, data[htlcRecordHeaderSize+size:])
+	if err != nil {
+		return nil, err
+	}
+	h := &Htlc{
+		HtlcInfo: info,
+		Status:   data[0],
+	}
+	if size != 0 {
+		h.Preimage = data[htlcRecordHeaderSize : htlcRecordHeaderSize+size]
+	}
+	return h, nil
+}
+
+// HtlcList is a page of an htlc list, the total number of htlcs in the list and the momentum the index is up to date with
+type HtlcList struct {
+	Frontier types.HashHeight
+	Count    uint64
+	List     []*Htlc
+}
+
+func getHtlcRecordKey(id types.Hash) []byte {
+	return common.JoinBytes(htlcRecordPrefix, id.Bytes())
+}
+func getHtlcTimeLockedPrefix(address types.Address) []byte {
+	return common.JoinBytes(htlcTimeLockedPrefix, address.Bytes())
+}
+func getHtlcHashLockedPrefix(address types.Address) []byte {
+	return common.JoinBytes(htlcHashLockedPrefix, address.Bytes())
+}
+func getHtlcHashLockPrefix(hashLock []byte) []byte {
+	return common.JoinBytes(htlcHashLockPrefix, types.NewHash(hashLock).Bytes())
+}
+
+// getHtlcListPrefixes returns the prefixes of all lists which contain the htlc
+func getHtlcListPrefixes(info *definition.HtlcInfo) [][]byte {
+	return [][]byte{
+		getHtlcTimeLockedPrefix(info.TimeLocked),
+		getHtlcHashLockedPrefix(info.HashLocked),
+		getHtlcHashLockPrefix(info.HashLock),
+	}
+}
+
+// getHtlcListKey orders the htlcs of a list by expiration time in descending order, then by id
+func getHtlcListKey(prefix []byte, info *definition.HtlcInfo) []byte {
+	expiration := uint64(info.ExpirationTime) ^ (1 << 63)
+	return common.JoinBytes(prefix, common.Uint64ToBytes(^expiration), info.Id.Bytes())
+}
+func getHtlcCountKey(prefix []byte) []byte {
+	return common.JoinBytes(htlcCountPrefix, prefix)
+}
+func getHtlcChangePrefix(height uint64) []byte {
+	return common.JoinBytes(htlcChangePrefix, common.Uint64ToBytes(height))
+}
+func getHtlcChangeKey(height uint64, id types.Hash) []byte {
+	return common.JoinBytes(getHtlcChangePrefix(height), id.Bytes())
+}
+
+func getHashHeight(reader db.LevelDBLikeRO, key []byte) (*types.HashHeight, error) {
+	data, err := reader.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return &types.ZeroHashHeight, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return types.DeserializeHashHeight(data)
+}
+
+// HtlcIndex keeps every htlc created by the htlc contract together with the way it was resolved, so the htlcs
+// which are no longer in the contract storage can still be listed. Htlcs are listed by time-locked address,
+// hash-locked address and hash lock.
+//
+// For every momentum, the index keeps a change log with the previous status of the htlcs it changed,
+// so the momentum can be rollbacked. Htlcs created in account-blocks pruned by this node are added from the contract
+// storage while they are active, but their outcome is only known if they are resolved after the index is enabled.
+type HtlcIndex struct {
+	*follower
+	log   common.Logger
+	chain chain.Chain
+	db    db.Storage
+
+	changes sync.Mutex
+}
+
+func NewHtlcIndex(chain chain.Chain, db db.Storage) *HtlcIndex {
+	hi := &HtlcIndex{
+		log:   common.ChainLogger.New("submodule", "htlc-index"),
+		chain: chain,
+		db:    db,
+	}
+	hi.follower = newFollower(hi.log, chain, "htlc-index", hi)
+	return hi
+}
+
+func (hi *HtlcIndex) Init() error {
+	hi.log.Info("initializing ...")
+	defer hi.log.Info("initialized")
+
+	frontier, err := hi.Frontier()
+	if err != nil {
+		return err
+	}
+	if frontier.Height == 0 {
+		return nil
+	}
+	momentum, err := hi.chain.GetFrontierMomentumStore().GetMomentumByHeight(frontier.Height)
+	if err != nil {
+		return err
+	}
+	if momentum == nil || momentum.Hash != frontier.Hash {
+		// the chain was rollbacked while the index was disabled
+		hi.log.Warn("htlc-index doesn't match the chain. Rebuilding it", "index-frontier", frontier)
+		return hi.reset()
+	}
+	return nil
+}
+func (hi *HtlcIndex) Start() error {
+	hi.log.Info("starting ...")
+	defer hi.log.Info("started")
+
+	hi.follower.start()
+	return nil
+}
+func (hi *HtlcIndex) Stop() error {
+	hi.log.Info("stopping ...")
+	defer hi.log.Info("stopped")
+
+	hi.follower.stop()
+	return hi.db.Close()
+}
+
+// Frontier returns the identifier of the last indexed momentum
+func (hi *HtlcIndex) Frontier() (*types.HashHeight, error) {
+	return getHashHeight(hi.db, htlcFrontierKey)
+}
+
+func (hi *HtlcIndex) reset() error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	batch := new(leveldb.Batch)
+	iterator := hi.db.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		batch.Delete(append([]byte{}, iterator.Key()...))
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	return hi.db.Write(batch, nil)
+}
+
+// htlcBatch accumulates the changes of the index. Records and counts are read from the db once and kept in memory until written.
+type htlcBatch struct {
+	db    db.Storage
+	batch *leveldb.Batch
+	// records holds the changed htlcs, nil for the removed ones
+	records map[types.Hash]*Htlc
+	counts  map[string]uint64
+	// changes holds the status of the changed htlcs before the momentum
+	changes map[types.Hash]uint8
+}
+
+func newHtlcBatch(db db.Storage) *htlcBatch {
+	return &htlcBatch{
+		db:      db,
+		batch:   new(leveldb.Batch),
+		records: make(map[types.Hash]*Htlc),
+		counts:  make(map[string]uint64),
+		changes: make(map[types.Hash]uint8),
+	}
+}
+func (hb *htlcBatch) get(id types.Hash) (*Htlc, error) {
+	if h, ok := hb.records[id]; ok {
+		return h, nil
+	}
+	data, err := hb.db.Get(getHtlcRecordKey(id), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deserializeHtlc(id, data)
+}
+func (hb *htlcBatch) addCount(prefix []byte, added bool) error {
+	count, ok := hb.counts[string(prefix)]
+	if !ok {
+		var err error
+		if count, err = getUint64(hb.db, getHtlcCountKey(prefix)); err != nil {
+			return err
+		}
+	}
+	if added {
+		count += 1
+	} else {
+		count -= 1
+	}
+	hb.counts[string(prefix)] = count
+	return nil
+}
+func (hb *htlcBatch) add(h *Htlc) error {
+	hb.records[h.Id] = h
+	for _, prefix := range getHtlcListPrefixes(h.HtlcInfo) {
+		hb.batch.Put(getHtlcListKey(prefix, h.HtlcInfo), []byte{})
+		if err := hb.addCount(prefix, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (hb *htlcBatch) remove(h *Htlc) error {
+	hb.records[h.Id] = nil
+	for _, prefix := range getHtlcListPrefixes(h.HtlcInfo) {
+		hb.batch.Delete(getHtlcListKey(prefix, h.HtlcInfo))
+		if err := hb.addCount(prefix, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logChange keeps the first status the htlc had in the momentum
+func (hb *htlcBatch) logChange(id types.Hash, previous uint8) {
+	if _, ok := hb.changes[id]; !ok {
+		hb.changes[id] = previous
+	}
+}
+func (hb *htlcBatch) create(info *definition.HtlcInfo) error {
+	if existing, err := hb.get(info.Id); err != nil || existing != nil {
+		return err
+	}
+	hb.logChange(info.Id, htlcCreated)
+	return hb.add(&Htlc{HtlcInfo: info, Status: HtlcActive})
+}
+func (hb *htlcBatch) resolve(id types.Hash, status uint8, preimage []byte) error {
+	h, err := hb.get(id)
+	if err != nil {
+		return err
+	}
+	// created in a pruned account-block and resolved before the index was enabled
+	if h == nil || h.Status != HtlcActive {
+		return nil
+	}
+	hb.logChange(id, h.Status)
+	hb.records[id] = &Htlc{HtlcInfo: h.HtlcInfo, Status: status, Preimage: preimage}
+	return nil
+}
+func (hb *htlcBatch) write(frontier types.HashHeight) error {
+	for id, h := range hb.records {
+		if h == nil {
+			hb.batch.Delete(getHtlcRecordKey(id))
+			continue
+		}
+		data, err := h.serialize()
+		if err != nil {
+			return err
+		}
+		hb.batch.Put(getHtlcRecordKey(id), data)
+	}
+	for prefix, count := range hb.counts {
+		if count == 0 {
+			hb.batch.Delete(getHtlcCountKey([]byte(prefix)))
+			continue
+		}
+		hb.batch.Put(getHtlcCountKey([]byte(prefix)), common.Uint64ToBytes(count))
+	}
+	for id, previous := range hb.changes {
+		hb.batch.Put(getHtlcChangeKey(frontier.Height, id), []byte{previous})
+	}
+	hb.batch.Put(htlcFrontierKey, frontier.Serialize())
+	return hb.db.Write(hb.batch, nil)
+}
+
+func (hi *HtlcIndex) insert(momentumStore store.Momentum, detailed *nom.DetailedMomentum) error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	frontier, err := hi.Frontier()
+	if err != nil {
+		return err
+	}
+	if err := checkFollows(frontier, detailed.Momentum); err != nil {
+		return err
+	}
+
+	hb := newHtlcBatch(hi.db)
+	for _, block := range detailed.AccountBlocks {
+		// account-blocks pruned by this node can't be indexed
+		if block == nil || block.Address != types.HtlcContract || block.BlockType != nom.BlockTypeContractReceive {
+			continue
+		}
+		if err := applyHtlcBlock(momentumStore, hb, block); err != nil {
+			return err
+		}
+	}
+	return hb.write(detailed.Momentum.Identifier())
+}
+
+// applyHtlcBlock records the effect of the htlc contract receive-block.
+// A method call failed if the contract refunded the send-block, or if it didn't pay out the htlc for unlock and reclaim.
+func applyHtlcBlock(momentumStore store.Momentum, hb *htlcBatch, block *nom.AccountBlock) error {
+	sendBlock, err := momentumStore.GetAccountBlockByHash(block.FromBlockHash)
+	if err == db.ErrPruned {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sendBlock == nil {
+		return nil
+	}
+	method, err := definition.ABIHtlc.MethodById(sendBlock.Data)
+	if err != nil {
+		return nil
+	}
+
+	switch method.Name {
+	case definition.CreateHtlcMethodName:
+		if len(block.DescendantBlocks) != 0 {
+			return nil
+		}
+		param := new(definition.CreateHtlcParam)
+		if err := definition.ABIHtlc.UnpackMethod(param, method.Name, sendBlock.Data); err != nil {
+			return nil
+		}
+		return hb.create(&definition.HtlcInfo{
+			Id:             sendBlock.Hash,
+			TimeLocked:     sendBlock.Address,
+			HashLocked:     param.HashLocked,
+			TokenStandard:  sendBlock.TokenStandard,
+			Amount:         sendBlock.Amount,
+			ExpirationTime: param.ExpirationTime,
+			HashType:       param.HashType,
+			KeyMaxSize:     param.KeyMaxSize,
+			HashLock:       param.HashLock,
+		})
+	case definition.UnlockHtlcMethodName:
+		if len(block.DescendantBlocks) == 0 {
+			return nil
+		}
+		param := new(definition.UnlockHtlcParam)
+		if err := definition.ABIHtlc.UnpackMethod(param, method.Name, sendBlock.Data); err != nil {
+			return nil
+		}
+		return hb.resolve(param.Id, HtlcUnlocked, param.Preimage)
+	case definition.ReclaimHtlcMethodName:
+		if len(block.DescendantBlocks) == 0 {
+			return nil
+		}
+		id := new(types.Hash)
+		if err := definition.ABIHtlc.UnpackMethod(id, method.Name, sendBlock.Data); err != nil {
+			return nil
+		}
+		return hb.resolve(*id, HtlcReclaimed, nil)
+	}
+	return nil
+}
+
+// delete reverts the changes of the momentum using its change log
+func (hi *HtlcIndex) delete(detailed *nom.DetailedMomentum) error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	hb := newHtlcBatch(hi.db)
+	prefix := getHtlcChangePrefix(detailed.Momentum.Height)
+	iterator := hi.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		key := append([]byte{}, iterator.Key()...)
+		if len(iterator.Value()) != 1 {
+			return errInvalidHtlcRecord
+		}
+		previous := iterator.Value()[0]
+		id, err := types.BytesToHash(key[len(prefix):])
+		if err != nil {
+			return err
+		}
+		h, err := hb.get(id)
+		if err != nil {
+			return err
+		}
+		hb.batch.Delete(key)
+		if h == nil {
+			continue
+		}
+		if previous == htlcCreated {
+			if err := hb.remove(h); err != nil {
+				return err
+			}
+		} else {
+			hb.records[id] = &Htlc{HtlcInfo: h.HtlcInfo, Status: previous}
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	previous := types.HashHeight{
+		Hash:   detailed.Momentum.PreviousHash,
+		Height: detailed.Momentum.Height - 1,
+	}
+	return hb.write(previous)
+}
+
+// reconcile adds the active htlcs which were created in account-blocks pruned by this node
+func (hi *HtlcIndex) reconcile(momentumStore store.Momentum) error {
+	hi.changes.Lock()
+	defer hi.changes.Unlock()
+
+	active, err := definition.GetAllHtlcInfo(momentumStore.GetAccountStore(types.HtlcContract).Storage())
+	if err != nil {
+		return err
+	}
+	hb := newHtlcBatch(hi.db)
+	for _, info := range active {
+		existing, err := hb.get(info.Id)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		if err := hb.add(&Htlc{HtlcInfo: info, Status: HtlcActive}); err != nil {
+			return err
+		}
+	}
+	if len(hb.records) == 0 {
+		return nil
+	}
+	return hb.write(momentumStore.Identifier())
+}
+
+// caughtUp adds the active htlcs which were created in account-blocks pruned by this node
+func (hi *HtlcIndex) caughtUp(momentumStore store.Momentum) error {
+	return hi.reconcile(momentumStore)
+}
+
+func (hi *HtlcIndex) InsertMomentum(detailed *nom.DetailedMomentum) {
+	hi.follower.insertMomentum(detailed)
+}
+func (hi *HtlcIndex) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	hi.follower.deleteMomentum(detailed)
+}
+
+// ByTimeLocked returns count htlcs created by address, starting with the htlc at position skip
+func (hi *HtlcIndex) ByTimeLocked(address types.Address, skip, count int) (*HtlcList, error) {
+	return hi.list(getHtlcTimeLockedPrefix(address), skip, count)
+}
+
+// ByHashLocked returns count htlcs which can be unlocked by address, starting with the htlc at position skip
+func (hi *HtlcIndex) ByHashLocked(address types.Address, skip, count int) (*HtlcList, error) {
+	return hi.list(getHtlcHashLockedPrefix(address), skip, count)
+}
+
+// ByHashLock returns count htlcs locked with hashLock, starting with the htlc at position skip
+func (hi *HtlcIndex) ByHashLock(hashLock []byte, skip, count int) (*HtlcList, error) {
+	return hi.list(getHtlcHashLockPrefix(hashLock), skip, count)
+}
+
+// list reads a page of the list from a single snapshot of the index, sorted by expiration time in descending order
+func (hi *HtlcIndex) list(prefix []byte, skip, count int) (*HtlcList, error) {
+	snapshot, err := hi.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+
+	frontier, err := getHashHeight(snapshot, htlcFrontierKey)
+	if err != nil {
+		return nil, err
+	}
+	total, err := getUint64(snapshot, getHtlcCountKey(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	iterator := snapshot.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iterator.Release()
+
+	list := make([]*Htlc, 0, count)
+	for position := 0; len(list) < count && iterator.Next(); position += 1 {
+		if position < skip {
+			continue
+		}
+		key := iterator.Key()
+		id, err := types.BytesToHash(key[len(key)-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		data, err := snapshot.Get(getHtlcRecordKey(id), nil)
+		if err != nil {
+			return nil, err
+		}
+		h, err := deserializeHtlc(id, data)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return &HtlcList{
+		Frontier: *frontier,
+		Count:    total,
+		List:     list,
+	}, nil
+}
@@ -0,0 +1,321 @@
+package index
+
+import (
+	"encoding/hex"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded"
+)
+
+const (
+	// number of momentums indexed while holding the insert lock when catching up with the chain
+	catchUpBatchSize = 1000
+	// maximum number of entries scanned by a single query, matching or not
+	maxScannedEntries = 10000
+	// delays between the attempts to catch up with the chain after a failure
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// AddressIndex is a secondary index over confirmed momentums. For every address it stores the account-blocks
+// published by the address and the send-blocks which have the address as recipient, ordered by momentum height.
+type AddressIndex struct {
+	*follower
+	log   common.Logger
+	chain chain.Chain
+	db    db.Storage
+
+	changes sync.Mutex
+}
+
+func NewAddressIndex(chain chain.Chain, db db.Storage) *AddressIndex {
+	ai := &AddressIndex{
+		log:   common.ChainLogger.New("submodule", "address-index"),
+		chain: chain,
+		db:    db,
+	}
+	ai.follower = newFollower(ai.log, chain, "address-index", ai)
+	return ai
+}
+
+func (ai *AddressIndex) Init() error {
+	ai.log.Info("initializing ...")
+	defer ai.log.Info("initialized")
+
+	frontier, err := ai.Frontier()
+	if err != nil {
+		return err
+	}
+	if frontier.Height == 0 {
+		return nil
+	}
+	momentum, err := ai.chain.GetFrontierMomentumStore().GetMomentumByHeight(frontier.Height)
+	if err != nil {
+		return err
+	}
+	if momentum == nil || momentum.Hash != frontier.Hash {
+		// the chain was rollbacked while the index was disabled
+		ai.log.Warn("address-index doesn't match the chain. Rebuilding it", "index-frontier", frontier)
+		return ai.reset()
+	}
+	return nil
+}
+func (ai *AddressIndex) Start() error {
+	ai.log.Info("starting ...")
+	defer ai.log.Info("started")
+
+	ai.follower.start()
+	return nil
+}
+func (ai *AddressIndex) Stop() error {
+	ai.log.Info("stopping ...")
+	defer ai.log.Info("stopped")
+
+	ai.follower.stop()
+	return ai.db.Close()
+}
+
+// Frontier returns the identifier of the last indexed momentum
+func (ai *AddressIndex) Frontier() (*types.HashHeight, error) {
+	data, err := ai.db.Get(frontierKey, nil)
+	if err == leveldb.ErrNotFound {
+		return &types.ZeroHashHeight, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return types.DeserializeHashHeight(data)
+}
+
+func (ai *AddressIndex) reset() error {
+	ai.changes.Lock()
+	defer ai.changes.Unlock()
+
+	batch := new(leveldb.Batch)
+	iterator := ai.db.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		batch.Delete(append([]byte{}, iterator.Key()...))
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	return ai.db.Write(batch, nil)
+}
+
+// momentumEntries returns the entries generated by all account-blocks confirmed in the momentum, keyed by the indexed address
+func momentumEntries(momentumStore store.Momentum, detailed *nom.DetailedMomentum) (map[types.Address][]*Entry, []types.Address, error) {
+	entries := make(map[types.Address][]*Entry)
+	addresses := make([]types.Address, 0)
+	add := func(address types.Address, entry *Entry) {
+		if _, ok := entries[address]; !ok {
+			addresses = append(addresses, address)
+		}
+		entries[address] = append(entries[address], entry)
+	}
+
+	var visit func(block *nom.AccountBlock) error
+	visit = func(block *nom.AccountBlock) error {
+		entry := &Entry{
+			BlockHash:         block.Hash,
+			BlockType:         block.BlockType,
+			MomentumHeight:    detailed.Momentum.Height,
+			MomentumTimestamp: detailed.Momentum.Timestamp.Unix(),
+			TokenStandard:     block.TokenStandard,
+		}
+		if nom.IsSendBlock(block.BlockType) {
+			if types.IsEmbeddedAddress(block.ToAddress) {
+				if method, _, err := embedded.DecodeEmbeddedCall(block.ToAddress, block.Data); err == nil {
+					entry.Method = method.Name
+				}
+			}
+			add(block.Address, entry)
+			if block.ToAddress != block.Address {
+				incoming := *entry
+				incoming.Incoming = true
+				add(block.ToAddress, &incoming)
+			}
+		} else {
+			sendBlock, err := momentumStore.GetAccountBlockByHash(block.FromBlockHash)
+			if err == db.ErrPruned {
+				sendBlock, err = nil, nil
+			}
+			if err != nil {
+				return err
+			}
+			if sendBlock != nil {
+				entry.TokenStandard = sendBlock.TokenStandard
+				if method, _, err := embedded.DecodeEmbeddedCall(block.Address, sendBlock.Data); err == nil {
+					entry.Method = method.Name
+				}
+			}
+			add(block.Address, entry)
+		}
+
+		for _, dBlock := range block.DescendantBlocks {
+			if err := visit(dBlock); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, block := range detailed.AccountBlocks {
+		// account-blocks pruned by this node can't be indexed
+		if block == nil {
+			continue
+		}
+		if err := visit(block); err != nil {
+			return nil, nil, err
+		}
+	}
+	return entries, addresses, nil
+}
+
+func (ai *AddressIndex) insert(momentumStore store.Momentum, detailed *nom.DetailedMomentum) error {
+	ai.changes.Lock()
+	defer ai.changes.Unlock()
+
+	frontier, err := ai.Frontier()
+	if err != nil {
+		return err
+	}
+	if err := checkFollows(frontier, detailed.Momentum); err != nil {
+		return err
+	}
+
+	entries, addresses, err := momentumEntries(momentumStore, detailed)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	position := uint32(0)
+	for _, address := range addresses {
+		for _, entry := range entries[address] {
+			batch.Put(getEntryKey(address, detailed.Momentum.Height, position), entry.serialize())
+			position += 1
+		}
+	}
+	identifier := detailed.Momentum.Identifier()
+	batch.Put(frontierKey, identifier.Serialize())
+	return ai.db.Write(batch, nil)
+}
+
+// momentumAddresses returns all addresses which have entries generated by the momentum
+func momentumAddresses(detailed *nom.DetailedMomentum) map[types.Address]bool {
+	addresses := make(map[types.Address]bool)
+	var visit func(block *nom.AccountBlock)
+	visit = func(block *nom.AccountBlock) {
+		addresses[block.Address] = true
+		if nom.IsSendBlock(block.BlockType) {
+			addresses[block.ToAddress] = true
+		}
+		for _, dBlock := range block.DescendantBlocks {
+			visit(dBlock)
+		}
+	}
+	for _, block := range detailed.AccountBlocks {
+		if block != nil {
+			visit(block)
+		}
+	}
+	return addresses
+}
+
+func (ai *AddressIndex) delete(detailed *nom.DetailedMomentum) error {
+	ai.changes.Lock()
+	defer ai.changes.Unlock()
+
+	batch := new(leveldb.Batch)
+	for address := range momentumAddresses(detailed) {
+		iterator := ai.db.NewIterator(util.BytesPrefix(getHeightPrefix(address, detailed.Momentum.Height)), nil)
+		for iterator.Next() {
+			batch.Delete(append([]byte{}, iterator.Key()...))
+		}
+		iterator.Release()
+		if err := iterator.Error(); err != nil {
+			return err
+		}
+	}
+	previous := types.HashHeight{
+		Hash:   detailed.Momentum.PreviousHash,
+		Height: detailed.Momentum.Height - 1,
+	}
+	batch.Put(frontierKey, previous.Serialize())
+	return ai.db.Write(batch, nil)
+}
+
+func (ai *AddressIndex) caughtUp(store.Momentum) error {
+	return nil
+}
+
+func (ai *AddressIndex) InsertMomentum(detailed *nom.DetailedMomentum) {
+	ai.follower.insertMomentum(detailed)
+}
+func (ai *AddressIndex) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	ai.follower.deleteMomentum(detailed)
+}
+
+// Query returns up to count entries of the address which match the filter, in ascending order of momentum height.
+// The returned cursor can be used to continue the query, and is empty when there are no more entries.
+// A page can contain less than count entries even if the cursor is not empty, since the number of scanned entries is limited.
+func (ai *AddressIndex) Query(address types.Address, filter *Filter, cursor string, count int) ([]*Entry, string, error) {
+	if count <= 0 {
+		return nil, "", ErrInvalidCount
+	}
+	if filter == nil {
+		filter = &Filter{}
+	}
+
+	start := getHeightPrefix(address, filter.FromHeight)
+	if cursor != "" {
+		raw, err := hex.DecodeString(cursor)
+		if err != nil || len(raw) != cursorSize {
+			return nil, "", ErrInvalidCursor
+		}
+		start = common.JoinBytes(getAddressPrefix(address), raw)
+	}
+	limit := util.BytesPrefix(getAddressPrefix(address)).Limit
+	// the entries up to the last height are the whole address
+	if filter.ToHeight != 0 && filter.ToHeight != math.MaxUint64 {
+		limit = getHeightPrefix(address, filter.ToHeight+1)
+	}
+
+	iterator := ai.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+	defer iterator.Release()
+
+	list := make([]*Entry, 0, count)
+	for scanned := 0; iterator.Next(); scanned += 1 {
+		key := iterator.Key()
+		if len(list) == count || scanned == maxScannedEntries {
+			return list, hex.EncodeToString(key[len(key)-cursorSize:]), nil
+		}
+
+		entry, err := deserializeEntry(iterator.Value())
+		if err != nil {
+			return nil, "", err
+		}
+		if filter.ToTime != 0 && entry.MomentumTimestamp > filter.ToTime {
+			break
+		}
+		if filter.matches(entry) {
+			list = append(list, entry)
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, "", err
+	}
+	return list, "", nil
+}
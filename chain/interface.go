@@ -41,6 +41,9 @@ type MomentumPool interface {
 
 	GetFrontierMomentumStore() store.Momentum
 	GetMomentumStore(identifier types.HashHeight) store.Momentum
+
+	// SetPruneRetention enables the pruned mode. Only the last retention momentums keep their account-blocks and patches.
+	SetPruneRetention(retention uint64) error
 }
 
 type AccountPool interface {
@@ -62,4 +65,11 @@ type AccountPool interface {
 	GetNewMomentumContent() []*nom.AccountBlock
 	GetAllUncommittedAccountBlocks() []*nom.AccountBlock
 	GetUncommittedAccountBlocksByAddress(address types.Address) []*nom.AccountBlock
+	// GetPooledAccountBlocks returns the uncommitted account-blocks of all addresses, ordered by address and height,
+	// with the time at which they were added to the pool
+	GetPooledAccountBlocks() []*PooledAccountBlock
+	// GetRejectedAccountBlocks returns the last MaxRejectedAccountBlocks account-blocks dropped by the pool, either
+	// because they lost against a fork with a higher priority, conflict with a confirmed account-block or couldn't be
+	// linked, the most recently dropped first
+	GetRejectedAccountBlocks() []*RejectedAccountBlock
 }
@@ -0,0 +1,12 @@
+package chain
+
+import (
+	"github.com/zenon-network/go-zenon/common/metrics"
+)
+
+func momentumInsertTimer() metrics.Timer {
+	return metrics.GetOrRegisterTimer("chain/momentums/insert")
+}
+func momentumRollbackCounter() metrics.Counter {
+	return metrics.GetOrRegisterCounter("chain/momentums/rollbacks")
+}
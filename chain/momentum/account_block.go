@@ -5,6 +5,7 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 )
 
@@ -42,9 +43,14 @@ func (ms *momentumStore) GetAccountBlockByHash(hash types.Hash) (*nom.AccountBlo
 		return nil, err
 	}
 
-	if header, err := types.DeserializeAccountHeader(data); err != nil {
+	header, err := types.DeserializeAccountHeader(data)
+	if err != nil {
 		return nil, err
-	} else {
-		return ms.GetAccountStore(header.Address).ByHeight(header.Height)
 	}
+	block, err := ms.GetAccountStore(header.Address).ByHeight(header.Height)
+	if err == nil && block == nil {
+		// the header is kept when the body of the block is pruned
+		return nil, db.ErrPruned
+	}
+	return block, err
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/syndtr/goleveldb/leveldb"
 
+	"github.com/zenon-network/go-zenon/chain/account"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 )
@@ -26,3 +27,35 @@ func (ms *momentumStore) getZnnBalance(address types.Address) (*big.Int, error)
 func (ms *momentumStore) setZnnBalance(address types.Address, balance *big.Int) error {
 	return ms.DB.Put(getAccountZNNBalance(address), common.BigIntToBytes(balance))
 }
+
+// IterateBalances calls f for every non-zero balance of every account.
+// All account stores are scanned, so it is meant for building indexes and not for regular queries.
+func (ms *momentumStore) IterateBalances(f func(address types.Address, zts types.ZenonTokenStandard, balance *big.Int) error) error {
+	iterator := ms.DB.Subset(accountStorePrefix).NewIterator(nil)
+	defer iterator.Release()
+
+	for {
+		if !iterator.Next() {
+			return iterator.Error()
+		}
+		key := iterator.Key()
+		if len(key) < types.AddressSize {
+			continue
+		}
+		zts, ok := account.ParseBalanceKey(key[types.AddressSize:])
+		if !ok || len(iterator.Value()) == 0 {
+			continue
+		}
+		balance := common.BytesToBigInt(iterator.Value())
+		if balance.Sign() == 0 {
+			continue
+		}
+		address, err := types.BytesToAddress(key[:types.AddressSize])
+		if err != nil {
+			return err
+		}
+		if err := f(address, zts, balance); err != nil {
+			return err
+		}
+	}
+}
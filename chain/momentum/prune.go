@@ -0,0 +1,58 @@
+package momentum
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+)
+
+// PruneAccountBlocks deletes the bodies of the account-blocks confirmed by the momentum at height.
+// The frontier block of each account and the send-blocks which are not received yet are kept,
+// since they are required to insert new account-blocks.
+func (ms *momentumStore) PruneAccountBlocks(height uint64) error {
+	momentum, err := ms.GetMomentumByHeight(height)
+	if err != nil {
+		return err
+	}
+	if momentum == nil {
+		return errors.Errorf("can't find momentum at height %v", height)
+	}
+
+	for _, header := range momentum.Content {
+		block, err := ms.GetAccountBlock(*header)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+
+		blocks := []*nom.AccountBlock{block}
+		blocks = append(blocks, block.DescendantBlocks...)
+		for _, block := range blocks {
+			if required, err := ms.isRequiredAccountBlock(block); err != nil {
+				return err
+			} else if required {
+				continue
+			}
+			if err := db.DeleteEntryByHeight(ms.DB.Subset(getAccountStorePrefix(block.Address)), block.Height); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (ms *momentumStore) isRequiredAccountBlock(block *nom.AccountBlock) (bool, error) {
+	frontier, err := ms.GetAccountStore(block.Address).Frontier()
+	if err != nil {
+		return false, err
+	}
+	if frontier != nil && frontier.Height == block.Height {
+		return true, nil
+	}
+	if block.IsSendBlock() && ms.getAccountMailbox(block.Address).GetBlockWhichReceives(block.Hash) == nil {
+		return true, nil
+	}
+	return false, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
@@ -14,15 +15,33 @@ import (
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
+const (
+	// maximum number of momentums pruned at each insert, used when pruning is enabled on a node with full history
+	maxPrunedPerInsert = 100
+)
+
+var (
+	// MinPruneRetention is the minimum number of momentums for which the history is kept in pruned mode.
+	// Consensus needs the state of momentums a few epochs behind the frontier.
+	MinPruneRetention = uint64(4 * constants.MomentumsPerEpoch)
+	// StableDepth is the number of momentums after which a momentum is stable. Stable momentums are never rolled back
+	// when syncing a side-chain.
+	StableDepth = uint64(30)
+)
+
 type momentumPool struct {
 	*momentumEventManager
 	chainManager db.Manager
 	genesis      store.Genesis
 	log          log15.Logger
 	changes      sync.Mutex
+
+	// pruneRetention is the number of momentums for which the history is kept; 0 means the full history is kept
+	pruneRetention uint64
 }
 
 func (c *momentumPool) AddMomentumTransaction(insertLocker sync.Locker, transaction *nom.MomentumTransaction) error {
@@ -35,9 +54,11 @@ func (c *momentumPool) AddMomentumTransaction(insertLocker sync.Locker, transact
 
 	momentum := transaction.Momentum
 
+	start := time.Now()
 	if err := c.chainManager.Add(transaction); err != nil {
 		return err
 	}
+	momentumInsertTimer().UpdateSince(start)
 
 	store := c.getFrontierStore()
 	detailed, err := store.PrefetchMomentum(momentum)
@@ -49,6 +70,11 @@ func (c *momentumPool) AddMomentumTransaction(insertLocker sync.Locker, transact
 	c.broadcastInsertMomentum(detailed)
 	c.changes.Lock()
 
+	// the momentum is already inserted, so a failed prune is only logged and retried on the next insert
+	if err := c.prune(); err != nil {
+		c.log.Error("failed to prune momentums", "reason", err)
+	}
+
 	frontier := c.getFrontierStore()
 	if justNow, unimplemented, err := GotAllActiveSporksImplemented(frontier); err != nil {
 		return err
@@ -75,6 +101,55 @@ func (c *momentumPool) AddMomentumTransaction(insertLocker sync.Locker, transact
 
 	return nil
 }
+
+// prune discards the account-block bodies and the patches of the momentums which are older than the retention
+func (c *momentumPool) prune() error {
+	if c.pruneRetention == 0 {
+		return nil
+	}
+	pruner, ok := c.chainManager.(db.Pruner)
+	if !ok {
+		return nil
+	}
+
+	frontier := c.getFrontierStore().Identifier()
+	if frontier.Height <= c.pruneRetention {
+		return nil
+	}
+	target := frontier.Height - c.pruneRetention
+	start := pruner.PrunedHeight() + 1
+	if target >= start+maxPrunedPerInsert {
+		c.log.Info("pruning momentums", "from-height", start, "target", target)
+		target = start + maxPrunedPerInsert - 1
+	}
+	for height := start; height <= target; height += 1 {
+		store := c.getFrontierStore()
+		if err := store.PruneAccountBlocks(height); err != nil {
+			return err
+		}
+		patch, err := store.Changes()
+		if err != nil {
+			return err
+		}
+		if err := pruner.Prune(height, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetPruneRetention enables the pruned mode, in which only the last retention momentums keep their account-block bodies and patches.
+// A retention of 0 keeps the full history. Callers are expected to use a retention of at least MinPruneRetention.
+func (c *momentumPool) SetPruneRetention(retention uint64) error {
+	if _, ok := c.chainManager.(db.Pruner); !ok && retention != 0 {
+		return errors.Errorf("db %v doesn't support pruning", c.chainManager.Location())
+	}
+	c.changes.Lock()
+	defer c.changes.Unlock()
+	c.pruneRetention = retention
+	return nil
+}
+
 func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.HashHeight) error {
 	c.log.Info("rollbacking momentums", "to-identifier", identifier)
 	if insertLocker == nil {
@@ -110,6 +185,7 @@ func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.Has
 		if err := c.chainManager.Pop(); err != nil {
 			return err
 		}
+		momentumRollbackCounter().Inc(1)
 
 		c.changes.Unlock()
 		c.broadcastDeleteMomentum(detailed)
@@ -47,6 +47,7 @@ type AccountMailbox interface {
 
 	GetBlockWhichReceives(fromHash types.Hash) *types.AccountHeader
 	GetUnreceivedAccountBlockHashes(atMost uint64) ([]types.Hash, error)
+	GetUnreceivedAccountBlockHashesAfter(after *types.Hash, atMost uint64) ([]types.Hash, error)
 
 	SequencerPushBack(types.AccountHeader)
 	SequencerSize() uint64
@@ -51,9 +51,12 @@ type Momentum interface {
 	GetAccountStore(address types.Address) Account
 	GetAccountDB(address types.Address) db.DB
 	GetAccountMailbox(address types.Address) AccountMailbox
+	// IterateBalances calls f for every non-zero balance of every account
+	IterateBalances(f func(address types.Address, zts types.ZenonTokenStandard, balance *big.Int) error) error
 
 	Snapshot() Momentum
 	Changes() (db.Patch, error)
 
 	AddAccountBlockTransaction(header types.AccountHeader, patch db.Patch) error
+	PruneAccountBlocks(height uint64) error
 }
@@ -103,6 +103,9 @@ func (i *enableDeleteIterator) Value() []byte {
 	}
 	return val[1:]
 }
+func (i *enableDeleteIterator) Seek(key []byte) bool {
+	return Seek(i.StorageIterator, key)
+}
 
 func newEnableDeleteIterator(iterator StorageIterator) StorageIterator {
 	return &enableDeleteIterator{
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+
 	"github.com/zenon-network/go-zenon/common/types"
 )
 
@@ -35,6 +37,26 @@ type StorageIterator interface {
 	Release()
 }
 
+// Seeker is implemented by the iterators which can be positioned at a key without visiting the keys before it
+type Seeker interface {
+	// Seek moves the iterator to the first key greater than or equal to key and reports whether there is such key
+	Seek(key []byte) bool
+}
+
+// Seek moves the iterator to the first key greater than or equal to key and reports whether there is such key.
+// Iterators which are not a Seeker are advanced one key at a time.
+func Seek(iterator StorageIterator, key []byte) bool {
+	if seeker, ok := iterator.(Seeker); ok {
+		return seeker.Seek(key)
+	}
+	for iterator.Next() {
+		if bytes.Compare(iterator.Key(), key) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
 type DB interface {
 	Get([]byte) ([]byte, error)
 	Has([]byte) (bool, error)
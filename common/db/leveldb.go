@@ -22,6 +22,18 @@ func getConsensusOpenFilesCacheCapacity() int {
 	}
 }
 
+// the indexes are read by the RPC queries only, so they keep less files open than the chain db
+func getIndexOpenFilesCacheCapacity() int {
+	switch runtime.GOOS {
+	case "darwin":
+		return 50
+	case "windows":
+		return 100
+	default:
+		return 100
+	}
+}
+
 type LevelDBLikeRO interface {
 	Get(key []byte, ro *opt.ReadOptions) (value []byte, err error)
 	Has(key []byte, ro *opt.ReadOptions) (ret bool, err error)
@@ -74,7 +86,7 @@ func (ldbw *levelDBWrapper) changesInternal(prefix []byte) (Patch, error) {
 	panic("unimplemented")
 }
 
-func newLevelDBSnapshotWrapper(ldb *leveldb.Snapshot) db {
+func newLevelDBSnapshotWrapper(ldb LevelDBLikeRO) db {
 	return newMergedDb([]db{
 		newMemDBInternal(),
 		&levelDBROWrapper{
@@ -83,7 +95,7 @@ func newLevelDBSnapshotWrapper(ldb *leveldb.Snapshot) db {
 	})
 }
 
-func NewLevelDBSnapshotWrapper(ldb *leveldb.Snapshot) DB {
+func NewLevelDBSnapshotWrapper(ldb LevelDBLikeRO) DB {
 	return enableDelete(newMergedDb([]db{
 		newMemDBInternal(),
 		&levelDBROWrapper{
@@ -92,7 +104,7 @@ func NewLevelDBSnapshotWrapper(ldb *leveldb.Snapshot) DB {
 	}))
 }
 
-func NewLevelDBWrapper(db *leveldb.DB) DB {
+func NewLevelDBWrapper(db LevelDBLike) DB {
 	return enableDelete(
 		&levelDBWrapper{
 			db: db,
@@ -100,8 +112,15 @@ func NewLevelDBWrapper(db *leveldb.DB) DB {
 }
 
 func NewLevelDB(dirname string) (DB, *leveldb.DB) {
+	wrapper, db, err := OpenLevelDB(dirname)
+	common.DealWithErr(err)
+	return wrapper, db
+}
+func OpenLevelDB(dirname string) (DB, *leveldb.DB, error) {
 	opts := &opt.Options{OpenFilesCacheCapacity: getConsensusOpenFilesCacheCapacity()}
 	db, err := leveldb.OpenFile(dirname, opts)
-	common.DealWithErr(err)
-	return NewLevelDBWrapper(db), db
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewLevelDBWrapper(db), db, nil
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
@@ -89,6 +90,35 @@ func TestMergedIterators(t *testing.T) {
 000301 - 102005`)
 }
 
+func TestMergedIteratorsSeek(t *testing.T) {
+	db1 := newMemDBInternal()
+	db1.Put([]byte{0, 1, 1}, []byte{0, 16})
+	db1.Put([]byte{0, 2, 1}, []byte{0})
+	db1.Put([]byte{0, 3, 1}, []byte{0, 17})
+
+	db2 := newMemDBInternal()
+	db2.Put([]byte{0, 1, 2}, []byte{0, 18})
+	db2.Put([]byte{0, 2, 1}, []byte{0, 19})
+	db2.Put([]byte{0, 2, 2}, []byte{0, 20})
+
+	seek := func(db DB, key []byte) string {
+		iterator := db.NewIterator([]byte{})
+		defer iterator.Release()
+		keys := ""
+		for ok := Seek(iterator, key); ok; ok = iterator.Next() {
+			keys += fmt.Sprintf("%x ", iterator.Key())
+		}
+		common.FailIfErr(t, iterator.Error())
+		return keys
+	}
+
+	merged := enableDelete(newSkipDelete(newMergedDb([]db{db1, db2})))
+	common.ExpectString(t, seek(merged, []byte{0, 1, 2}), "000102 000202 000301 ")
+	common.ExpectString(t, seek(merged, []byte{0, 2}), "000202 000301 ")
+	common.ExpectString(t, seek(merged, []byte{0, 4}), "")
+	common.ExpectString(t, seek(merged.Subset([]byte{0}), []byte{2, 1}), "0202 0301 ")
+}
+
 func simpleMemDBOperations(t *testing.T, db DB) {
 	common.FailIfErr(t, db.Put([]byte{1, 2, 3}, []byte{1, 2, 3, 4, 5}))
 	common.FailIfErr(t, db.Put([]byte{1, 2, 3, 4}, []byte{1, 2, 3, 4, 5}))
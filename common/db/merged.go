@@ -107,6 +107,22 @@ func (mi *mergedIterator) Release() {
 		iter.Release()
 	}
 }
+func (mi *mergedIterator) Seek(key []byte) bool {
+	if mi.err != nil {
+		return false
+	}
+	for index, i := range mi.iterators {
+		mi.status[index] = 0
+		if !Seek(i, key) {
+			if err := i.Error(); err != nil && err != leveldb.ErrNotFound {
+				mi.err = err
+				return false
+			}
+			mi.status[index] = iteratorFinished
+		}
+	}
+	return mi.selectCurrent()
+}
 func (mi *mergedIterator) step() bool {
 	if mi.err != nil {
 		return false
@@ -134,6 +150,11 @@ func (mi *mergedIterator) step() bool {
 		}
 	}
 
+	return mi.selectCurrent()
+}
+
+// selectCurrent makes current the iterator with the smallest key
+func (mi *mergedIterator) selectCurrent() bool {
 	bestIndex := noCurrent
 	var bestKey []byte
 	for index, iterator := range mi.iterators {
@@ -0,0 +1,409 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/inconshreveable/log15"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+// pebbleReader is implemented by both pebble databases and snapshots
+type pebbleReader interface {
+	Get(key []byte) ([]byte, io.Closer, error)
+	NewIter(o *pebble.IterOptions) (*pebble.Iterator, error)
+}
+
+// pebbleHandle is a pebble snapshot or iterator which is released either by its owner, once garbage collected,
+// or when the storage is closed, since pebble refuses to close a database with open snapshots or iterators
+type pebbleHandle struct {
+	mu       sync.RWMutex
+	closer   io.Closer
+	iterator bool
+}
+
+func (h *pebbleHandle) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closer != nil {
+		h.closer.Close()
+		h.closer = nil
+	}
+}
+
+// pebbleStorage implements Storage on top of pebble, following the semantics of goleveldb
+type pebbleStorage struct {
+	// closing waits for the running calls, which hold mu for reading
+	mu     sync.RWMutex
+	db     *pebble.DB
+	closed bool
+
+	handlesMu sync.Mutex
+	handles   map[*pebbleHandle]struct{}
+}
+
+func openPebbleStorage(dir string, openFiles int) (Storage, error) {
+	pdb, err := pebble.Open(dir, &pebble.Options{
+		MaxOpenFiles: openFiles,
+		Logger:       &pebbleLogger{log: common.ChainLogger.New("submodule", "pebble")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleStorage{
+		db:      pdb,
+		handles: make(map[*pebbleHandle]struct{}),
+	}, nil
+}
+
+// pebbleLogger writes the compaction and recovery messages of pebble in the debug log
+type pebbleLogger struct {
+	log log15.Logger
+}
+
+func (l *pebbleLogger) Infof(format string, args ...interface{}) {
+	l.log.Debug(fmt.Sprintf(format, args...))
+}
+func (l *pebbleLogger) Fatalf(format string, args ...interface{}) {
+	l.log.Crit(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func writeOptions(wo *opt.WriteOptions) *pebble.WriteOptions {
+	if wo != nil && wo.Sync {
+		return pebble.Sync
+	}
+	return pebble.NoSync
+}
+func pebbleGet(reader pebbleReader, key []byte) ([]byte, error) {
+	value, closer, err := reader.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, leveldb.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	// the value is only valid until closer is closed
+	return append([]byte{}, value...), nil
+}
+func pebbleHas(reader pebbleReader, key []byte) (bool, error) {
+	_, err := pebbleGet(reader, key)
+	if err == leveldb.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *pebbleStorage) track(closer io.Closer, iterator bool) *pebbleHandle {
+	handle := &pebbleHandle{closer: closer, iterator: iterator}
+	s.handlesMu.Lock()
+	s.handles[handle] = struct{}{}
+	s.handlesMu.Unlock()
+	return handle
+}
+func (s *pebbleStorage) release(handle *pebbleHandle) {
+	s.handlesMu.Lock()
+	delete(s.handles, handle)
+	s.handlesMu.Unlock()
+	handle.release()
+}
+
+func (s *pebbleStorage) Get(key []byte, _ *opt.ReadOptions) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, leveldb.ErrClosed
+	}
+	return pebbleGet(s.db, key)
+}
+func (s *pebbleStorage) Has(key []byte, _ *opt.ReadOptions) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false, leveldb.ErrClosed
+	}
+	return pebbleHas(s.db, key)
+}
+func (s *pebbleStorage) NewIterator(slice *util.Range, _ *opt.ReadOptions) iterator.Iterator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return iterator.NewEmptyIterator(leveldb.ErrClosed)
+	}
+	return s.newIterator(s.db, slice, nil)
+}
+func (s *pebbleStorage) Put(key []byte, value []byte, wo *opt.WriteOptions) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return leveldb.ErrClosed
+	}
+	return s.db.Set(key, value, writeOptions(wo))
+}
+func (s *pebbleStorage) Delete(key []byte, wo *opt.WriteOptions) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return leveldb.ErrClosed
+	}
+	return s.db.Delete(key, writeOptions(wo))
+}
+func (s *pebbleStorage) Write(batch *leveldb.Batch, wo *opt.WriteOptions) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return leveldb.ErrClosed
+	}
+	replay := &pebbleBatch{batch: s.db.NewBatch()}
+	defer replay.batch.Close()
+	if err := batch.Replay(replay); err != nil {
+		return err
+	}
+	if replay.err != nil {
+		return replay.err
+	}
+	return replay.batch.Commit(writeOptions(wo))
+}
+func (s *pebbleStorage) GetSnapshot() (StorageSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, leveldb.ErrClosed
+	}
+	snapshot := &pebbleSnapshot{
+		storage:  s,
+		snapshot: s.db.NewSnapshot(),
+	}
+	snapshot.handle = s.track(snapshot.snapshot, false)
+	runtime.SetFinalizer(snapshot, (*pebbleSnapshot).Release)
+	return snapshot, nil
+}
+func (s *pebbleStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return leveldb.ErrClosed
+	}
+	s.closed = true
+
+	s.handlesMu.Lock()
+	handles := s.handles
+	s.handles = make(map[*pebbleHandle]struct{})
+	s.handlesMu.Unlock()
+	// iterators are released before the snapshots they were created from
+	for handle := range handles {
+		if handle.iterator {
+			handle.release()
+		}
+	}
+	for handle := range handles {
+		handle.release()
+	}
+	return s.db.Close()
+}
+
+// newIterator creates an iterator over slice. owner is referenced by the iterator, so it is not released before.
+func (s *pebbleStorage) newIterator(reader pebbleReader, slice *util.Range, owner interface{}) iterator.Iterator {
+	options := &pebble.IterOptions{}
+	if slice != nil {
+		options.LowerBound = slice.Start
+		options.UpperBound = slice.Limit
+	}
+	it, err := reader.NewIter(options)
+	if err != nil {
+		return iterator.NewEmptyIterator(err)
+	}
+	pi := &pebbleIterator{
+		storage: s,
+		owner:   owner,
+		it:      it,
+	}
+	pi.handle = s.track(it, true)
+	runtime.SetFinalizer(pi, (*pebbleIterator).Release)
+	return pi
+}
+
+// pebbleBatch replays a leveldb batch in a pebble batch
+type pebbleBatch struct {
+	batch *pebble.Batch
+	err   error
+}
+
+func (b *pebbleBatch) Put(key, value []byte) {
+	if b.err == nil {
+		b.err = b.batch.Set(key, value, nil)
+	}
+}
+func (b *pebbleBatch) Delete(key []byte) {
+	if b.err == nil {
+		b.err = b.batch.Delete(key, nil)
+	}
+}
+
+type pebbleSnapshot struct {
+	storage  *pebbleStorage
+	snapshot *pebble.Snapshot
+	handle   *pebbleHandle
+}
+
+func (s *pebbleSnapshot) Get(key []byte, _ *opt.ReadOptions) ([]byte, error) {
+	s.handle.mu.RLock()
+	defer s.handle.mu.RUnlock()
+	if s.handle.closer == nil {
+		return nil, leveldb.ErrSnapshotReleased
+	}
+	return pebbleGet(s.snapshot, key)
+}
+func (s *pebbleSnapshot) Has(key []byte, _ *opt.ReadOptions) (bool, error) {
+	s.handle.mu.RLock()
+	defer s.handle.mu.RUnlock()
+	if s.handle.closer == nil {
+		return false, leveldb.ErrSnapshotReleased
+	}
+	return pebbleHas(s.snapshot, key)
+}
+func (s *pebbleSnapshot) NewIterator(slice *util.Range, _ *opt.ReadOptions) iterator.Iterator {
+	s.handle.mu.RLock()
+	defer s.handle.mu.RUnlock()
+	if s.handle.closer == nil {
+		return iterator.NewEmptyIterator(leveldb.ErrSnapshotReleased)
+	}
+	return s.storage.newIterator(s.snapshot, slice, s)
+}
+func (s *pebbleSnapshot) Release() {
+	runtime.SetFinalizer(s, nil)
+	s.storage.release(s.handle)
+}
+
+// pebbleIterator implements the goleveldb iterator, which is positioned before the first key when created
+type pebbleIterator struct {
+	storage  *pebbleStorage
+	owner    interface{}
+	it       *pebble.Iterator
+	handle   *pebbleHandle
+	started  bool
+	released bool
+	releaser util.Releaser
+}
+
+// the methods of the iterator hold the lock of its handle, so the storage can't release the iterator while it is used
+
+// open reports whether the iterator was neither released by its owner nor by the storage once closed.
+// The lock of the handle must be held.
+func (i *pebbleIterator) open() bool {
+	return !i.released && i.handle.closer != nil
+}
+func (i *pebbleIterator) First() bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.open() {
+		return false
+	}
+	i.started = true
+	return i.it.First()
+}
+func (i *pebbleIterator) Last() bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.open() {
+		return false
+	}
+	i.started = true
+	return i.it.Last()
+}
+func (i *pebbleIterator) Seek(key []byte) bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.open() {
+		return false
+	}
+	i.started = true
+	return i.it.SeekGE(key)
+}
+func (i *pebbleIterator) Next() bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.open() {
+		return false
+	}
+	if !i.started {
+		i.started = true
+		return i.it.First()
+	}
+	return i.it.Next()
+}
+func (i *pebbleIterator) Prev() bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.open() {
+		return false
+	}
+	if !i.started {
+		i.started = true
+		return i.it.Last()
+	}
+	return i.it.Prev()
+}
+func (i *pebbleIterator) Valid() bool {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	return i.valid()
+}
+func (i *pebbleIterator) valid() bool {
+	return i.open() && i.started && i.it.Valid()
+}
+func (i *pebbleIterator) Key() []byte {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.valid() {
+		return nil
+	}
+	return i.it.Key()
+}
+func (i *pebbleIterator) Value() []byte {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if !i.valid() {
+		return nil
+	}
+	return i.it.Value()
+}
+func (i *pebbleIterator) Error() error {
+	i.handle.mu.RLock()
+	defer i.handle.mu.RUnlock()
+	if i.released {
+		return leveldb.ErrIterReleased
+	}
+	if i.handle.closer == nil {
+		return leveldb.ErrClosed
+	}
+	return i.it.Error()
+}
+func (i *pebbleIterator) Release() {
+	if i.released {
+		return
+	}
+	runtime.SetFinalizer(i, nil)
+	i.released = true
+	i.storage.release(i.handle)
+	i.owner = nil
+	if i.releaser != nil {
+		i.releaser.Release()
+		i.releaser = nil
+	}
+}
+func (i *pebbleIterator) SetReleaser(releaser util.Releaser) {
+	if !i.released && i.releaser == nil {
+		i.releaser = releaser
+	}
+}
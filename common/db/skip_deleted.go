@@ -24,6 +24,16 @@ func (i *skipDeletedIterator) Next() bool {
 	}
 }
 
+func (i *skipDeletedIterator) Seek(key []byte) bool {
+	if !Seek(i.StorageIterator, key) {
+		return false
+	}
+	if len(i.StorageIterator.Value()) > 1 {
+		return true
+	}
+	return i.Next()
+}
+
 func newSkipDeletedIterator(iterator StorageIterator) StorageIterator {
 	return &skipDeletedIterator{
 		StorageIterator: iterator,
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Backend is the key-value engine on which a database is stored
+type Backend string
+
+const (
+	LevelDB Backend = "leveldb"
+	Pebble  Backend = "pebble"
+
+	DefaultBackend = LevelDB
+)
+
+var (
+	Backends = []Backend{LevelDB, Pebble}
+)
+
+// ParseBackend returns the backend with the given name, DefaultBackend if name is empty
+func ParseBackend(name string) (Backend, error) {
+	if name == "" {
+		return DefaultBackend, nil
+	}
+	for _, backend := range Backends {
+		if string(backend) == strings.ToLower(name) {
+			return backend, nil
+		}
+	}
+	return "", errors.Errorf("unknown db backend %v. Supported backends are %v", name, Backends)
+}
+
+// Storage is the key-value engine under the databases. All backends follow the semantics of goleveldb:
+// missing keys are reported with leveldb.ErrNotFound, keys are sorted byte-wise, new iterators are positioned before
+// the first key, and snapshots and iterators which are not released are released once they are garbage collected.
+type Storage interface {
+	LevelDBLike
+	Delete(key []byte, wo *opt.WriteOptions) error
+	Write(batch *leveldb.Batch, wo *opt.WriteOptions) error
+	GetSnapshot() (StorageSnapshot, error)
+	Close() error
+}
+
+// StorageSnapshot is a read-only view of a Storage at the time it was taken
+type StorageSnapshot interface {
+	LevelDBLikeRO
+	Release()
+}
+
+// DetectBackend returns the backend of the database stored in dir, or an empty backend if dir doesn't hold a database
+func DetectBackend(dir string) (Backend, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	hasCurrent := false
+	for _, entry := range entries {
+		// only pebble writes an options file, both engines keep the name of the manifest in CURRENT
+		if strings.HasPrefix(entry.Name(), "OPTIONS-") {
+			return Pebble, nil
+		}
+		if entry.Name() == "CURRENT" {
+			hasCurrent = true
+		}
+	}
+	if hasCurrent {
+		return LevelDB, nil
+	}
+	return "", nil
+}
+
+// OpenStorage opens the database in dir using backend, and creates it if dir doesn't hold a database.
+// An empty backend opens the database with the backend it was created with, or with DefaultBackend if it is created.
+func OpenStorage(backend Backend, dir string) (Storage, error) {
+	return openStorage(backend, dir, getConsensusOpenFilesCacheCapacity())
+}
+func openStorage(backend Backend, dir string, openFiles int) (Storage, error) {
+	existing, err := DetectBackend(dir)
+	if err != nil {
+		return nil, err
+	}
+	if backend == "" {
+		backend = existing
+		if backend == "" {
+			backend = DefaultBackend
+		}
+	}
+	if existing != "" && existing != backend {
+		return nil, errors.Errorf("%v holds a %v database but the %v backend is configured. Migrate the data dir to %v first", filepath.Base(dir), existing, backend, backend)
+	}
+
+	switch backend {
+	case LevelDB:
+		return openLevelDBStorage(dir, openFiles)
+	case Pebble:
+		return openPebbleStorage(dir, openFiles)
+	default:
+		return nil, errors.Errorf("unknown db backend %v", backend)
+	}
+}
+
+// OpenDB opens the database in dir as a DB. The returned Storage must be closed once the DB is no longer used.
+func OpenDB(backend Backend, dir string) (DB, Storage, error) {
+	storage, err := OpenStorage(backend, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewLevelDBWrapper(storage), storage, nil
+}
+
+// OpenIndexDB opens the index database in dir, see OpenDB
+func OpenIndexDB(backend Backend, dir string) (DB, Storage, error) {
+	storage, err := openStorage(backend, dir, getIndexOpenFilesCacheCapacity())
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewLevelDBWrapper(storage), storage, nil
+}
+
+// CopyStorage writes all the entries of from in to, in batches of batchSize entries,
+// and returns the number of entries copied
+func CopyStorage(from, to Storage, batchSize int, progress func(entries uint64)) (uint64, error) {
+	iterator := from.NewIterator(nil, nil)
+	defer iterator.Release()
+
+	count := uint64(0)
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Put(iterator.Key(), iterator.Value())
+		count += 1
+		if batch.Len() >= batchSize {
+			if err := to.Write(batch, nil); err != nil {
+				return count, err
+			}
+			batch.Reset()
+			if progress != nil {
+				progress(count)
+			}
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return count, err
+	}
+	return count, to.Write(batch, nil)
+}
+
+type levelDBStorage struct {
+	*leveldb.DB
+}
+
+func openLevelDBStorage(dir string, openFiles int) (Storage, error) {
+	ldb, err := leveldb.OpenFile(dir, &opt.Options{OpenFilesCacheCapacity: openFiles})
+	if err != nil {
+		return nil, err
+	}
+	return &levelDBStorage{DB: ldb}, nil
+}
+
+func (s *levelDBStorage) GetSnapshot() (StorageSnapshot, error) {
+	snapshot, err := s.DB.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
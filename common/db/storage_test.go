@@ -0,0 +1,104 @@
+package db
+
+import (
+	"path"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+func TestStorageBackends(t *testing.T) {
+	dir := t.TempDir()
+	ldbDir := path.Join(dir, "leveldb")
+	pebbleDir := path.Join(dir, "pebble")
+	ldbManager, err := OpenManager(LevelDB, ldbDir)
+	common.FailIfErr(t, err)
+	pebbleManager, err := OpenManager(Pebble, pebbleDir)
+	common.FailIfErr(t, err)
+
+	versions := make([]types.HashHeight, 0)
+	for i := int64(1); i <= 5; i += 1 {
+		common.FailIfErr(t, ldbManager.Add(newMockTransaction(i, ldbManager.Frontier())))
+		common.FailIfErr(t, pebbleManager.Add(newMockTransaction(i, pebbleManager.Frontier())))
+		versions = append(versions, GetFrontierIdentifier(pebbleManager.Frontier()))
+	}
+	common.FailIfErr(t, ldbManager.Pop())
+	common.FailIfErr(t, pebbleManager.Pop())
+	for _, version := range versions[:4] {
+		common.ExpectString(t, DebugDB(pebbleManager.Get(version)), DebugDB(ldbManager.Get(version)))
+	}
+	if pebbleManager.Get(versions[4]) != nil {
+		t.Fatal("expected popped version to be missing")
+	}
+	common.FailIfErr(t, pebbleManager.Stop())
+
+	backend, err := DetectBackend(pebbleDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, string(backend), "pebble")
+	_, err = OpenManager(LevelDB, pebbleDir)
+	common.ExpectString(t, err.Error(), "pebble holds a pebble database but the leveldb backend is configured. Migrate the data dir to leveldb first")
+
+	// an empty backend opens the database with its own backend
+	pebbleManager, err = OpenManager("", pebbleDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, DebugDB(pebbleManager.Frontier()), DebugDB(ldbManager.Frontier()))
+	common.FailIfErr(t, pebbleManager.Stop())
+	common.FailIfErr(t, ldbManager.Stop())
+
+	// migrate the leveldb database
+	from, err := OpenStorage(LevelDB, ldbDir)
+	common.FailIfErr(t, err)
+	to, err := OpenStorage(Pebble, path.Join(dir, "migrated"))
+	common.FailIfErr(t, err)
+	count, err := CopyStorage(from, to, 10, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, count, 44)
+	common.FailIfErr(t, from.Close())
+	common.FailIfErr(t, to.Close())
+
+	migrated, err := OpenManager(Pebble, path.Join(dir, "migrated"))
+	common.FailIfErr(t, err)
+	defer migrated.Stop()
+	ldbManager, err = OpenManager(LevelDB, ldbDir)
+	common.FailIfErr(t, err)
+	defer ldbManager.Stop()
+	for _, version := range versions[:4] {
+		common.ExpectString(t, DebugDB(migrated.Get(version)), DebugDB(ldbManager.Get(version)))
+	}
+	common.ExpectString(t, DebugPatch(migrated.GetPatch(versions[3])), DebugPatch(ldbManager.GetPatch(versions[3])))
+}
+
+func TestPebbleStorageSnapshots(t *testing.T) {
+	storage, err := OpenStorage(Pebble, t.TempDir())
+	common.FailIfErr(t, err)
+	wrapper := NewLevelDBWrapper(storage)
+	common.FailIfErr(t, wrapper.Put([]byte{1}, []byte{1}))
+	common.FailIfErr(t, wrapper.Put([]byte{2}, []byte{2}))
+
+	snapshot, err := storage.GetSnapshot()
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, wrapper.Delete([]byte{1}))
+	common.FailIfErr(t, wrapper.Put([]byte{3}, []byte{3}))
+	common.ExpectString(t, DebugDB(NewLevelDBSnapshotWrapper(snapshot)), `
+01 - 01
+02 - 02`)
+	common.ExpectString(t, DebugDB(wrapper), `
+02 - 02
+03 - 03`)
+	_, err = wrapper.Get([]byte{1})
+	common.ExpectError(t, err, leveldb.ErrNotFound)
+
+	// snapshots and iterators which are still open are released on close
+	iterator := storage.NewIterator(nil, nil)
+	common.ExpectTrue(t, iterator.Next())
+	common.FailIfErr(t, storage.Close())
+	_, err = snapshot.Get([]byte{1}, nil)
+	common.ExpectError(t, err, leveldb.ErrSnapshotReleased)
+	common.ExpectTrue(t, !iterator.Next())
+	common.ExpectTrue(t, iterator.Key() == nil)
+	common.ExpectError(t, iterator.Error(), leveldb.ErrClosed)
+	iterator.Release()
+}
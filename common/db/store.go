@@ -1,6 +1,9 @@
 package db
 
 import (
+	"bytes"
+
+	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/zenon-network/go-zenon/common"
@@ -62,3 +65,56 @@ func GetEntryByHash(db DB, hash types.Hash) ([]byte, error) {
 func GetEntryByHeight(db DB, height uint64) ([]byte, error) {
 	return db.Get(getEntryByHeightKey(height))
 }
+func DeleteEntryByHeight(db DB, height uint64) error {
+	return db.Delete(getEntryByHeightKey(height))
+}
+
+type patchRecord struct {
+	key   []byte
+	value []byte
+	del   bool
+}
+type patchRecorder struct {
+	records []patchRecord
+}
+
+func (pr *patchRecorder) Put(key []byte, value []byte) {
+	pr.records = append(pr.records, patchRecord{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+func (pr *patchRecorder) Delete(key []byte) {
+	pr.records = append(pr.records, patchRecord{key: append([]byte{}, key...), del: true})
+}
+
+// StripFrontier returns the changes of a patch stored by a Manager for version, without the SetFrontier records
+// which the manager appends to the changes of the transaction.
+//
+// The manager appends the records of the single commit of the transaction as the changes of a MemDB, which are
+// in key order, so the patch ends with the frontier identifier, the height by hash and the entry by height records.
+// A patch which doesn't end with the records of version is rejected, so a change of this layout can't go unnoticed.
+func StripFrontier(patch Patch, version types.HashHeight) (Patch, error) {
+	recorder := new(patchRecorder)
+	if err := patch.Replay(recorder); err != nil {
+		return nil, err
+	}
+	frontierKeys := [][]byte{getFrontierIdentifierKey(), getHeightByHashKey(version.Hash), getEntryByHeightKey(version.Height)}
+	if len(recorder.records) < len(frontierKeys) {
+		return nil, errors.Errorf("patch of %v doesn't contain the frontier records", version)
+	}
+	changes := recorder.records[:len(recorder.records)-len(frontierKeys)]
+	for i, key := range frontierKeys {
+		record := recorder.records[len(changes)+i]
+		if record.del || !bytes.Equal(record.key, key) {
+			return nil, errors.Errorf("patch of %v doesn't contain the frontier records", version)
+		}
+	}
+
+	stripped := NewPatch()
+	for _, record := range changes {
+		if record.del {
+			stripped.Delete(record.key)
+		} else {
+			stripped.Put(record.key, record.value)
+		}
+	}
+	return stripped, nil
+}
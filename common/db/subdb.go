@@ -35,7 +35,7 @@ func (u *subDB) Put(key, value []byte) error {
 	return u.db.Put(common.JoinBytes(u.prefix, key), value)
 }
 func (u *subDB) NewIterator(prefix []byte) StorageIterator {
-	return newSubIterator(len(u.prefix), u.db.NewIterator(common.JoinBytes(u.prefix, prefix)))
+	return newSubIterator(u.prefix, u.db.NewIterator(common.JoinBytes(u.prefix, prefix)))
 }
 
 func (u *subDB) changesInternal(prefix []byte) (Patch, error) {
@@ -56,17 +56,20 @@ func (u *subDB) changesInternal(prefix []byte) (Patch, error) {
 }
 
 type subIterator struct {
-	prefixLen int
+	prefix []byte
 	StorageIterator
 }
 
 func (si *subIterator) Key() []byte {
-	return si.StorageIterator.Key()[si.prefixLen:]
+	return si.StorageIterator.Key()[len(si.prefix):]
+}
+func (si *subIterator) Seek(key []byte) bool {
+	return Seek(si.StorageIterator, common.JoinBytes(si.prefix, key))
 }
 
-func newSubIterator(prefixLen int, iterator StorageIterator) StorageIterator {
+func newSubIterator(prefix []byte, iterator StorageIterator) StorageIterator {
 	return &subIterator{
-		prefixLen:       prefixLen,
+		prefix:          prefix,
 		StorageIterator: iterator,
 	}
 }
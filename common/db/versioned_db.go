@@ -7,7 +7,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -17,12 +17,16 @@ const (
 	l1CacheSize                  = 400
 	l2CacheSize                  = 100
 	maximumCacheHeightDifference = 360
+	seedBatchSize                = 10000
 )
 
 var (
 	frontierByte = []byte{85}
 	patchByte    = []byte{102}
 	rollbackByte = []byte{119}
+	prunedByte   = []byte{136}
+
+	ErrPruned = errors.New("data was pruned by this node")
 )
 
 func absDiff(x, y uint64) uint64 {
@@ -55,6 +59,27 @@ type Manager interface {
 	Location() string
 }
 
+// Pruner is implemented by managers which are able to discard the history of old versions.
+// Once a version is pruned, it can't be rollbacked or queried using Get.
+type Pruner interface {
+	// PrunedHeight returns the height of the last pruned version, 0 if nothing was pruned
+	PrunedHeight() uint64
+	// Prune discards the patch and rollback-patch of the version at height and applies patch directly on the frontier.
+	// Versions must be pruned in order.
+	Prune(height uint64, patch Patch) error
+}
+
+// Seeder is implemented by managers which can be initialized with the state of a version, without its full history.
+type Seeder interface {
+	// Rollbacks iterates over the dumps of the rollback-patches of the versions with heights in [from, to], keyed by height
+	Rollbacks(from, to uint64) StorageIterator
+	// Seed writes state in an empty manager. The versions before the frontier of state are considered pruned.
+	Seed(state StorageIterator) error
+	// SeedRollbacks writes the rollback-patches of the last versions of a seeded manager, as returned by Rollbacks.
+	// The versions before the oldest rollback-patch are considered pruned.
+	SeedRollbacks(rollbacks StorageIterator) error
+}
+
 type memdbManager struct {
 	stableDB           DB
 	stableIdentifier   types.HashHeight
@@ -188,15 +213,26 @@ type ldbManager struct {
 	location string
 	l1Cache  *lru.Cache
 	l2Cache  *lru.Cache
-	ldb      *leveldb.DB
+	ldb      Storage
 	changes  sync.Mutex
 	stopped  bool
 }
 
 func NewLevelDBManager(dir string) Manager {
-	opts := &opt.Options{OpenFilesCacheCapacity: getOpenFilesCacheCapacity()}
-	ldb, err := leveldb.OpenFile(dir, opts)
+	manager, err := OpenLevelDBManager(dir)
 	common.DealWithErr(err)
+	return manager
+}
+func OpenLevelDBManager(dir string) (Manager, error) {
+	return OpenManager(LevelDB, dir)
+}
+
+// OpenManager opens the versioned database in dir using backend, see OpenStorage
+func OpenManager(backend Backend, dir string) (Manager, error) {
+	ldb, err := openStorage(backend, dir, getOpenFilesCacheCapacity())
+	if err != nil {
+		return nil, err
+	}
 	l1Cache, err := lru.New(l1CacheSize)
 	common.DealWithErr(err)
 	l2Cache, err := lru.New(l2CacheSize)
@@ -206,7 +242,7 @@ func NewLevelDBManager(dir string) Manager {
 		l1Cache:  l1Cache,
 		l2Cache:  l2Cache,
 		ldb:      ldb,
-	}
+	}, nil
 }
 
 func (m *ldbManager) Frontier() DB {
@@ -235,6 +271,9 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 	if identifier == frontierIdentifier {
 		return frontier
 	}
+	if identifier.Height < m.prunedHeight() {
+		return nil
+	}
 
 	trueIdentifier, err := GetIdentifierByHash(frontier, identifier.Hash)
 	if err == leveldb.ErrNotFound {
@@ -375,6 +414,9 @@ func (m *ldbManager) Add(transaction Transaction) error {
 }
 func (m *ldbManager) Pop() error {
 	frontierIdentifier := GetFrontierIdentifier(m.Frontier())
+	if frontierIdentifier.Height <= m.PrunedHeight() {
+		return ErrPruned
+	}
 	rollbackPatch := m.getRollback(frontierIdentifier.Height)
 
 	if err := ApplyPatch(NewLevelDBWrapper(m.ldb).Subset(frontierByte), rollbackPatch); err != nil {
@@ -389,6 +431,124 @@ func (m *ldbManager) Pop() error {
 
 	return nil
 }
+func (m *ldbManager) PrunedHeight() uint64 {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return 0
+	}
+	return m.prunedHeight()
+}
+func (m *ldbManager) prunedHeight() uint64 {
+	value, err := m.ldb.Get(prunedByte, nil)
+	if err == leveldb.ErrNotFound {
+		return 0
+	}
+	common.DealWithErr(err)
+	return common.BytesToUint64(value)
+}
+func (m *ldbManager) Prune(height uint64, patch Patch) error {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return errors.Errorf("can't prune stopped db")
+	}
+	if expected := m.prunedHeight() + 1; height != expected {
+		return errors.Errorf("can't prune height %v. Expected height %v", height, expected)
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Delete(common.JoinBytes(patchByte, common.Uint64ToBytes(height)))
+	batch.Delete(common.JoinBytes(rollbackByte, common.Uint64ToBytes(height)))
+	if err := patch.Replay(&prefixedBatch{prefix: frontierByte, batch: batch}); err != nil {
+		return err
+	}
+	batch.Put(prunedByte, common.Uint64ToBytes(height))
+	return m.ldb.Write(batch, nil)
+}
+func (m *ldbManager) Seed(state StorageIterator) error {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return errors.Errorf("can't seed stopped db")
+	}
+	frontier := NewLevelDBWrapper(m.ldb).Subset(frontierByte)
+	if !GetFrontierIdentifier(frontier).IsZero() {
+		return errors.Errorf("can't seed non-empty db")
+	}
+
+	batch := &prefixedBatch{prefix: frontierByte, batch: new(leveldb.Batch)}
+	for state.Next() {
+		// deleted entries have nil values
+		if state.Value() == nil {
+			continue
+		}
+		batch.Put(state.Key(), state.Value())
+		if batch.batch.Len() >= seedBatchSize {
+			if err := m.ldb.Write(batch.batch, nil); err != nil {
+				return err
+			}
+			batch.batch.Reset()
+		}
+	}
+	if err := state.Error(); err != nil {
+		return err
+	}
+	if err := m.ldb.Write(batch.batch, nil); err != nil {
+		return err
+	}
+
+	seeded := GetFrontierIdentifier(frontier)
+	if seeded.IsZero() {
+		return errors.Errorf("seeded state doesn't have a frontier")
+	}
+	return m.ldb.Put(prunedByte, common.Uint64ToBytes(seeded.Height), nil)
+}
+func (m *ldbManager) Rollbacks(from, to uint64) StorageIterator {
+	return newSubIterator(rollbackByte, m.ldb.NewIterator(&util.Range{
+		Start: common.JoinBytes(rollbackByte, common.Uint64ToBytes(from)),
+		Limit: common.JoinBytes(rollbackByte, common.Uint64ToBytes(to+1)),
+	}, nil))
+}
+func (m *ldbManager) SeedRollbacks(rollbacks StorageIterator) error {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return errors.Errorf("can't seed stopped db")
+	}
+	frontier := GetFrontierIdentifier(NewLevelDBWrapper(m.ldb).Subset(frontierByte))
+	if frontier.IsZero() {
+		return errors.Errorf("can't seed rollbacks before the state")
+	}
+
+	batch := new(leveldb.Batch)
+	oldest := uint64(0)
+	previous := uint64(0)
+	for rollbacks.Next() {
+		if len(rollbacks.Key()) != 8 {
+			return errors.Errorf("invalid rollback key")
+		}
+		height := common.BytesToUint64(rollbacks.Key())
+		if oldest == 0 {
+			oldest = height
+		} else if height != previous+1 {
+			return errors.Errorf("missing rollback for height %v", previous+1)
+		}
+		previous = height
+		batch.Put(common.JoinBytes(rollbackByte, rollbacks.Key()), rollbacks.Value())
+	}
+	if err := rollbacks.Error(); err != nil {
+		return err
+	}
+	if oldest == 0 {
+		return nil
+	}
+	if previous != frontier.Height {
+		return errors.Errorf("expected last rollback for height %v but got %v", frontier.Height, previous)
+	}
+	batch.Put(prunedByte, common.Uint64ToBytes(oldest-1))
+	return m.ldb.Write(batch, nil)
+}
 func (m *ldbManager) Stop() error {
 	m.changes.Lock()
 	defer m.changes.Unlock()
@@ -404,3 +564,16 @@ func (m *ldbManager) Stop() error {
 func (m *ldbManager) Location() string {
 	return m.location
 }
+
+// prefixedBatch writes patches in a leveldb batch using the same encoding as enableDeleteDB
+type prefixedBatch struct {
+	prefix []byte
+	batch  *leveldb.Batch
+}
+
+func (b *prefixedBatch) Put(key []byte, value []byte) {
+	b.batch.Put(common.JoinBytes(b.prefix, key), common.JoinBytes(existsByte, value))
+}
+func (b *prefixedBatch) Delete(key []byte) {
+	b.batch.Delete(common.JoinBytes(b.prefix, key))
+}
@@ -260,3 +260,65 @@ d5104dc76695721d - b80704bb7b4d7c03
 dc2864602be7fb85 - d38967f931a50490
 f25f4b21eef64b43 - 9c0a8a2bfc0914df`)
 }
+
+func TestVersionedDBSeedAndPrune(t *testing.T) {
+	m := NewLevelDBManager(t.TempDir())
+	for i := int64(1); i <= 4; i += 1 {
+		common.DealWithErr(m.Add(newMockTransaction(i, m.Frontier())))
+	}
+	frontier := GetFrontierIdentifier(m.Frontier())
+	second := m.Get(types.HashHeight{Height: 2, Hash: mustGetHashByHeight(m.Frontier(), 2)})
+	if second == nil {
+		t.Fatal("expected state at height 2 to be available")
+	}
+
+	// prune the first 2 versions
+	pruner := m.(Pruner)
+	common.FailIfErr(t, pruner.Prune(1, NewPatch()))
+	common.ExpectString(t, pruner.Prune(1, NewPatch()).Error(), "can't prune height 1. Expected height 2")
+	common.FailIfErr(t, pruner.Prune(2, NewPatch()))
+	common.ExpectUint64(t, pruner.PrunedHeight(), 2)
+	if m.Get(types.HashHeight{Height: 1, Hash: mustGetHashByHeight(m.Frontier(), 1)}) != nil {
+		t.Fatal("expected state at height 1 to be pruned")
+	}
+	common.FailIfErr(t, m.Pop())
+	common.FailIfErr(t, m.Pop())
+	common.ExpectError(t, m.Pop(), ErrPruned)
+	common.DealWithErr(m.Add(newMockTransaction(3, m.Frontier())))
+
+	// seed a new manager with the frontier state
+	seeded := NewLevelDBManager(t.TempDir())
+	iterator := m.Frontier().NewIterator(nil)
+	common.FailIfErr(t, seeded.(Seeder).Seed(iterator))
+	iterator.Release()
+	common.ExpectString(t, DebugDB(seeded.Frontier()), DebugDB(m.Frontier()))
+	common.ExpectUint64(t, seeded.(Pruner).PrunedHeight(), frontier.Height-1)
+	common.ExpectError(t, seeded.Pop(), ErrPruned)
+	common.ExpectString(t, seeded.(Seeder).Seed(m.Frontier().NewIterator(nil)).Error(), "can't seed non-empty db")
+}
+
+func mustGetHashByHeight(db DB, height uint64) types.Hash {
+	data, err := GetEntryByHeight(db, height)
+	common.DealWithErr(err)
+	hash, err := types.BytesToHash(data[:types.HashSize])
+	common.DealWithErr(err)
+	return hash
+}
+
+func TestStripFrontier(t *testing.T) {
+	m := NewLevelDBManager(t.TempDir())
+	transaction := newMockTransaction(1, m.Frontier())
+	changes := DebugPatch(transaction.patch)
+	identifier := transaction.commit.Identifier()
+	common.DealWithErr(m.Add(transaction))
+
+	stripped, err := StripFrontier(m.GetPatch(identifier), identifier)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, DebugPatch(stripped), changes)
+
+	next := types.HashHeight{Hash: identifier.Hash, Height: identifier.Height + 1}
+	_, err = StripFrontier(m.GetPatch(identifier), next)
+	common.ExpectString(t, fmt.Sprint(err), fmt.Sprintf("patch of %v doesn't contain the frontier records", next))
+	_, err = StripFrontier(stripped, identifier)
+	common.ExpectString(t, fmt.Sprint(err), fmt.Sprintf("patch of %v doesn't contain the frontier records", identifier))
+}
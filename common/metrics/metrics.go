@@ -0,0 +1,67 @@
+// Package metrics contains the registry shared by all the modules of the node and exports it in the Prometheus text format.
+//
+// Modules must import this package instead of go-ethereum/metrics directly. The collection is disabled by default and
+// metrics constructed while it is disabled are stubs, so modules look up their metrics by name when they update them
+// instead of constructing them at package initialization.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+)
+
+const (
+	// refresh interval of the process metrics (memory, cpu, disk)
+	processMetricsRefresh = 3 * time.Second
+)
+
+type (
+	Counter = metrics.Counter
+	Gauge   = metrics.Gauge
+	Meter   = metrics.Meter
+	Timer   = metrics.Timer
+)
+
+// Enable turns on the collection. It must be called before any metric is looked up, since the stubs looked up while
+// the collection is disabled stay registered.
+func Enable() {
+	metrics.Enabled = true
+}
+
+func GetOrRegisterCounter(name string) Counter {
+	return metrics.GetOrRegisterCounter(name, nil)
+}
+func GetOrRegisterGauge(name string) Gauge {
+	return metrics.GetOrRegisterGauge(name, nil)
+}
+func GetOrRegisterMeter(name string) Meter {
+	return metrics.GetOrRegisterMeter(name, nil)
+}
+func GetOrRegisterTimer(name string) Timer {
+	return metrics.GetOrRegisterTimer(name, nil)
+}
+
+// RegisterFunctionalGauge registers a gauge whose value is computed by f at each scrape.
+// A previously registered metric with the same name is replaced, so the gauge always reflects the last instance of a module.
+func RegisterFunctionalGauge(name string, f func() int64) {
+	metrics.DefaultRegistry.Unregister(name)
+	metrics.NewRegisteredFunctionalGauge(name, nil, f)
+}
+
+// Unregister removes the metric with the given name, if any
+func Unregister(name string) {
+	metrics.DefaultRegistry.Unregister(name)
+}
+
+// CollectProcessMetrics periodically collects the memory, cpu and disk usage of the process. It never returns.
+func CollectProcessMetrics() {
+	metrics.CollectProcessMetrics(processMetricsRefresh)
+}
+
+// Handler serves all the registered metrics in the Prometheus text format
+func Handler() http.Handler {
+	return prometheus.Handler(metrics.DefaultRegistry)
+}
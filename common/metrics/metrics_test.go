@@ -0,0 +1,40 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/metrics"
+)
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	common.FailIfErr(t, err)
+	return string(body)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	defer metrics.Unregister("test/counter")
+	defer metrics.Unregister("test/gauge")
+
+	metrics.Enable()
+	metrics.GetOrRegisterCounter("test/counter").Inc(3)
+	metrics.RegisterFunctionalGauge("test/gauge", func() int64 { return 1 })
+	// the last registered gauge replaces the previous one
+	metrics.RegisterFunctionalGauge("test/gauge", func() int64 { return 7 })
+
+	body := scrape(t)
+	for _, expected := range []string{
+		"test_counter 3\n",
+		"# TYPE test_gauge gauge\ntest_gauge 7\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Fatalf("expected %q in metrics\n%v", expected, body)
+		}
+	}
+}
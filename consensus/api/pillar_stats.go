@@ -23,9 +23,45 @@ type EpochStats struct {
 	TotalBlocks uint64 `json:"totalBlocks"`
 }
 
+// MissedSlot is a slot in which the elected producer didn't produce a momentum
+type MissedSlot struct {
+	// Timestamp is the start time of the slot, which would have been the timestamp of the momentum
+	Timestamp int64         `json:"timestamp"`
+	Producer  types.Address `json:"producer"`
+}
+
+// EpochProduction compares the momentums expected from a pillar during an epoch with the ones which made it in the chain
+type EpochProduction struct {
+	Epoch       uint64        `json:"epoch"`
+	Name        string        `json:"name"`
+	ExpectedNum uint64        `json:"expectedNum"`
+	ProducedNum uint64        `json:"producedNum"`
+	Missed      []*MissedSlot `json:"missed"`
+}
+
 type PillarReader interface {
 	GetPillarWeights() (map[string]*big.Int, error)
 	EpochTicker() common.Ticker
 	EpochStats(epoch uint64) (*EpochStats, error)
 	GetPillarDelegationsByEpoch(epoch uint64) (map[string]*types.PillarDelegationDetail, error)
 }
+
+// ScheduledSlot is a slot of the momentum production schedule
+type ScheduledSlot struct {
+	StartTime int64         `json:"startTime"`
+	EndTime   int64         `json:"endTime"`
+	Name      string        `json:"name"`
+	Producer  types.Address `json:"producer"`
+	// Final is false while the momentum which holds the delegations used by the election isn't part of the chain,
+	// in which case the election uses the frontier momentum and the slot can still change
+	Final bool `json:"final"`
+}
+
+// ProducerSchedule lists the slots of an epoch, in order, together with the elected producer of each slot
+type ProducerSchedule struct {
+	Epoch     uint64           `json:"epoch"`
+	StartTime int64            `json:"startTime"`
+	EndTime   int64            `json:"endTime"`
+	Final     bool             `json:"final"`
+	Slots     []*ScheduledSlot `json:"slots"`
+}
@@ -39,6 +39,10 @@ type Consensus interface {
 	Stop() error
 
 	GetMomentumProducer(timestamp time.Time) (*types.Address, error)
+	// EpochProduction returns the slots of the pillar in the epoch, up to the frontier momentum, and which of them were missed
+	EpochProduction(epoch uint64, pillarName string) (*api.EpochProduction, error)
+	// ProducerSchedule returns the elected producer of each slot of the epoch, filtered by pillarName if not empty
+	ProducerSchedule(epoch uint64, pillarName string) (*api.ProducerSchedule, error)
 
 	FrontierPillarReader() api.PillarReader
 	FixedPillarReader(types.HashHeight) api.PillarReader
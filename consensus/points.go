@@ -19,6 +19,7 @@ type Points interface {
 
 type points struct {
 	log          common.Logger
+	db           *storage.DB
 	epochPoints  PointsReader
 	periodPoints PointsReader
 
@@ -53,6 +54,7 @@ func newPoints(electionReader ElectionReader, epochTicker common.Ticker, ch chai
 
 	return &points{
 		log:                 common.ConsensusLogger.New("submodule", "points"),
+		db:                  db,
 		periodPoints:        periodPoints,
 		epochPoints:         epochPoints,
 		lastCompletedPeriod: lastCompletedPeriod,
@@ -100,7 +102,29 @@ func (p *points) InsertMomentum(detailed *nom.DetailedMomentum) {
 		p.lastCompletedEpoch = epochTick - 1
 	}
 }
-func (p *points) DeleteMomentum(*nom.DetailedMomentum) {
+
+// DeleteMomentum discards the points of the ticks which are no longer completed once the momentum is rolled back,
+// so the stored points always end at the last completed tick
+func (p *points) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	block := detailed.Momentum
+
+	tick := int64(p.periodPoints.ToTick(*block.Timestamp))
+	epochTick := tick / p.epochTickMultiplier
+
+	for ; p.lastCompletedPeriod >= tick; p.lastCompletedPeriod -= 1 {
+		p.log.Debug("delete period point", "tick", p.lastCompletedPeriod)
+		if err := p.db.DeletePointByHeight(storage.PrefixPeriodPoint, uint64(p.lastCompletedPeriod)); err != nil {
+			p.log.Error("failed to delete point", "tick", p.lastCompletedPeriod, "reason", err)
+			return
+		}
+	}
+	for ; p.lastCompletedEpoch >= epochTick; p.lastCompletedEpoch -= 1 {
+		p.log.Info("delete epoch point", "tick", p.lastCompletedEpoch)
+		if err := p.db.DeletePointByHeight(storage.PrefixEpochPoint, uint64(p.lastCompletedEpoch)); err != nil {
+			p.log.Error("failed to delete point", "tick", p.lastCompletedEpoch, "reason", err)
+			return
+		}
+	}
 }
 
 // PointsReader can read pillar statistics of epoch or period
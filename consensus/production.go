@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/consensus/api"
+)
+
+// EpochProduction goes over the elections of the epoch and checks for each slot of the pillar if
+// the chain contains a momentum with the timestamp of the slot. Slots which didn't start before the
+// frontier momentum are not taken into account. Returns nil, nil for epochs in the future.
+func (obj *API) EpochProduction(epoch uint64, pillarName string) (*api.EpochProduction, error) {
+	frontier, err := obj.momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	if epochStart, _ := obj.EpochTicker().ToTime(epoch); !epochStart.Before(*frontier.Timestamp) {
+		return nil, nil
+	}
+	multiplier, err := obj.er.TickMultiplier(obj.EpochTicker())
+	if err != nil {
+		return nil, err
+	}
+
+	genesis := obj.momentumStore.GetGenesisMomentum()
+	result := &api.EpochProduction{
+		Epoch:  epoch,
+		Name:   pillarName,
+		Missed: make([]*api.MissedSlot, 0),
+	}
+	for tick := epoch * multiplier; tick < (epoch+1)*multiplier; tick += 1 {
+		sTime, eTime := obj.er.ToTime(tick)
+		if !sTime.Before(*frontier.Timestamp) {
+			break
+		}
+		election, err := obj.er.ElectionByTick(tick)
+		if err != nil {
+			return nil, err
+		}
+		produced, err := obj.momentumsBetween(sTime, eTime)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range election.Producers {
+			if event.Name != pillarName || !event.StartTime.Before(*frontier.Timestamp) {
+				continue
+			}
+			// the first slot is taken by the genesis momentum
+			if !event.StartTime.After(*genesis.Timestamp) {
+				continue
+			}
+			result.ExpectedNum += 1
+			if momentum, ok := produced[event.StartTime.Unix()]; ok && momentum.Producer() == event.Producer {
+				result.ProducedNum += 1
+			} else {
+				result.Missed = append(result.Missed, &api.MissedSlot{
+					Timestamp: event.StartTime.Unix(),
+					Producer:  event.Producer,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// momentumsBetween returns the momentums with the timestamp in [sTime, eTime), by timestamp
+func (obj *API) momentumsBetween(sTime, eTime time.Time) (map[int64]*nom.Momentum, error) {
+	start, err := obj.momentumStore.GetMomentumBeforeTime(&sTime)
+	if err != nil {
+		return nil, err
+	}
+	if start == nil {
+		start = obj.momentumStore.GetGenesisMomentum()
+	}
+	end, err := obj.momentumStore.GetMomentumBeforeTime(&eTime)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]*nom.Momentum)
+	if end == nil || end.Height <= start.Height {
+		return result, nil
+	}
+	momentums, err := obj.momentumStore.GetMomentumsByHeight(start.Height+1, true, end.Height-start.Height)
+	if err != nil {
+		return nil, err
+	}
+	for _, momentum := range momentums {
+		result[momentum.Timestamp.Unix()] = momentum
+	}
+	return result, nil
+}
+
+func (cs *consensus) EpochProduction(epoch uint64, pillarName string) (*api.EpochProduction, error) {
+	return (&API{
+		momentumStore: cs.chain.GetFrontierMomentumStore(),
+		er:            cs.electionManager,
+		points:        cs.points,
+	}).EpochProduction(epoch, pillarName)
+}
@@ -0,0 +1,57 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/zenon-network/go-zenon/consensus/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+// ProducerSchedule runs the election of each tick of the epoch and returns the slots, filtered by pillarName if not empty.
+// The election of a tick uses the delegations at the last momentum before its proof time, so the slots of the ticks
+// for which a momentum can still be inserted before the proof time can still change.
+func (cs *consensus) ProducerSchedule(epoch uint64, pillarName string) (*api.ProducerSchedule, error) {
+	frontier, err := cs.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	epochTicker := cs.points.GetEpochPoints()
+	multiplier, err := cs.electionManager.TickMultiplier(epochTicker)
+	if err != nil {
+		return nil, err
+	}
+
+	// momentums are produced only at the start of a slot, so the next momentum can't be earlier than this
+	nextMomentum := frontier.Timestamp.Add(time.Duration(constants.ConsensusConfig.BlockTime) * time.Second)
+
+	epochStart, epochEnd := epochTicker.ToTime(epoch)
+	result := &api.ProducerSchedule{
+		Epoch:     epoch,
+		StartTime: epochStart.Unix(),
+		EndTime:   epochEnd.Unix(),
+		Final:     true,
+		Slots:     make([]*api.ScheduledSlot, 0),
+	}
+	for tick := epoch * multiplier; tick < (epoch+1)*multiplier; tick += 1 {
+		election, err := cs.electionManager.ElectionByTick(tick)
+		if err != nil {
+			return nil, err
+		}
+		final := !nextMomentum.Before(cs.electionManager.genProofTime(tick))
+		result.Final = result.Final && final
+
+		for _, event := range election.Producers {
+			if pillarName != "" && event.Name != pillarName {
+				continue
+			}
+			result.Slots = append(result.Slots, &api.ScheduledSlot{
+				StartTime: event.StartTime.Unix(),
+				EndTime:   event.EndTime.Unix(),
+				Name:      event.Name,
+				Producer:  event.Producer,
+				Final:     final,
+			})
+		}
+	}
+	return result, nil
+}
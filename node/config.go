@@ -7,12 +7,17 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/genesis"
 	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
+	api "github.com/zenon-network/go-zenon/rpc"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
 	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -21,6 +26,12 @@ type ProducerConfig struct {
 	Index       uint32
 	KeyFilePath string
 	Password    string
+	// RemoteSigner is the endpoint of the process holding the producer key, either an http(s) URL or unix:///path/to/socket.
+	// When set, KeyFilePath, Password and Index are not used by the node.
+	RemoteSigner string
+	// RemoteSignerTokenFile holds the token which authenticates the node to the remote signer.
+	// It's required by signers listening on a TCP endpoint.
+	RemoteSignerTokenFile string
 }
 type RPCConfig struct {
 	EnableHTTP bool
@@ -36,6 +47,43 @@ type RPCConfig struct {
 	HTTPVirtualHosts []string
 	HTTPCors         []string
 	WSOrigins        []string
+
+	// DecodeBlockData includes the decoded calls to embedded contracts in the account-blocks returned by the ledger API
+	DecodeBlockData bool
+
+	// Policy restricts the methods and the number of calls served to each client, over HTTP and WebSocket
+	Policy RPCPolicyConfig
+}
+
+// RPCPolicyConfig is applied to each call served over HTTP and WebSocket. Zero values disable the limits.
+// Methods are matched either by name, such as "ledger.publishRawTransaction", or by namespace, such as "ledger.*".
+type RPCPolicyConfig struct {
+	// AllowedMethods lists the methods which can be called, all methods can be called if empty
+	AllowedMethods []string
+	// DeniedMethods lists the methods which can't be called, it takes precedence over AllowedMethods
+	DeniedMethods []string
+
+	// RateLimit is the number of calls per second allowed for each client IP. Bursts of up to RateBurst calls are
+	// allowed by all the limits, or of one second of calls if more.
+	RateLimit float64
+	RateBurst int
+	// MethodRateLimits is the number of calls per second allowed for each client IP to the matching methods
+	MethodRateLimits map[string]float64
+	// APIKeys is the number of calls per second allowed for each API key, which replaces the limits of the client IP.
+	// Clients send the key in the X-Api-Key header, or in the apiKey query parameter of the WebSocket URL.
+	APIKeys map[string]float64
+	// AdminKeys are the API keys which unlock the admin methods, sent the same way as the APIKeys. The admin methods
+	// are denied to the clients without an admin key, including the clients connected from localhost, since behind
+	// a reverse proxy every client seems to connect from localhost.
+	AdminKeys []string
+
+	// BatchLimit is the maximum number of calls in a batch
+	BatchLimit int
+	// ResponseSizeLimit is the maximum size in bytes of the result of a call, or of all the results of a batch.
+	// It's checked once the call is done, so it doesn't bound the work of the node, only the size of its responses.
+	ResponseSizeLimit int
+	// SubscriptionLimit is the maximum number of subscriptions of a WebSocket connection
+	SubscriptionLimit int
 }
 type NetConfig struct {
 	ListenHost string
@@ -48,6 +96,29 @@ type NetConfig struct {
 
 	Seeders []string
 }
+type MetricsConfig struct {
+	// Enabled serves the metrics of the node in the Prometheus text format on http://Host:Port/metrics
+	Enabled bool
+	Host    string
+	Port    int
+}
+type PruningConfig struct {
+	// Retention is the number of momentums for which the account-blocks and patches are kept. 0 keeps the full history
+	Retention uint64
+}
+type DatabaseConfig struct {
+	// Backend is the key-value engine of the databases, "leveldb" (default) or "pebble".
+	// Existing data dirs are converted with the db migrate command.
+	Backend string
+}
+type IndexConfig struct {
+	// EnableAddressIndex builds a secondary index of the account-blocks which involve each address
+	EnableAddressIndex bool
+	// EnableHolderIndex builds a secondary index of the holders of each token, sorted by balance
+	EnableHolderIndex bool
+	// EnableHtlcIndex builds a secondary index of all htlcs, including the unlocked and reclaimed ones
+	EnableHtlcIndex bool
+}
 
 type Config struct {
 	DataPath    string // default ~/.zenon
@@ -61,6 +132,10 @@ type Config struct {
 	Producer *ProducerConfig
 	RPC      RPCConfig
 	Net      NetConfig
+	Index    IndexConfig
+	Pruning  PruningConfig
+	Database DatabaseConfig
+	Metrics  MetricsConfig
 }
 
 func (c *Config) MakePathsAbsolute() error {
@@ -98,7 +173,14 @@ func (c *Config) MakePathsAbsolute() error {
 }
 
 func (c *Config) makeZenonConfig(walletManager *wallet.Manager) (*zenon.Config, error) {
-	pillarCoinbase, err := c.parseProducer(walletManager)
+	pillarSigner, err := c.parseProducer(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	if c.Pruning.Retention != 0 && c.Pruning.Retention < chain.MinPruneRetention {
+		return nil, errors.Errorf("pruning retention must be at least %v momentums", chain.MinPruneRetention)
+	}
+	backend, err := db.ParseBackend(c.Database.Backend)
 	if err != nil {
 		return nil, err
 	}
@@ -106,12 +188,18 @@ func (c *Config) makeZenonConfig(walletManager *wallet.Manager) (*zenon.Config,
 	return &zenon.Config{
 		MinPeers:          c.Net.MinPeers,
 		MinConnectedPeers: c.Net.MinConnectedPeers,
-		ProducingKeyPair:  pillarCoinbase,
-		GenesisConfig:     c.makeGenesisConfig(),
+		ProducingSigner:   pillarSigner,
+		GenesisConfig:     c.MakeGenesisConfig(),
 		DataDir:           c.DataPath,
+
+		EnableAddressIndex: c.Index.EnableAddressIndex,
+		EnableHolderIndex:  c.Index.EnableHolderIndex,
+		EnableHtlcIndex:    c.Index.EnableHtlcIndex,
+		PruneRetention:     c.Pruning.Retention,
+		DBBackend:          backend,
 	}, nil
 }
-func (c *Config) makeGenesisConfig() (genesisConfig store.Genesis) {
+func (c *Config) MakeGenesisConfig() (genesisConfig store.Genesis) {
 	var err error
 	var path string
 
@@ -140,11 +228,42 @@ func (c *Config) makeGenesisConfig() (genesisConfig store.Genesis) {
 		return
 	}
 }
-func (c *Config) parseProducer(walletManager *wallet.Manager) (*wallet.KeyPair, error) {
+func (c *Config) parseProducer(walletManager *wallet.Manager) (signer.Signer, error) {
 	if c.Producer == nil {
 		return nil, nil
 	}
 
+	if c.Producer.RemoteSigner != "" {
+		address, err := c.producerAddress()
+		if err != nil {
+			return nil, err
+		}
+		token := ""
+		if c.Producer.RemoteSignerTokenFile != "" {
+			if token, err = signer.ReadToken(c.Producer.RemoteSignerTokenFile); err != nil {
+				return nil, err
+			}
+		}
+		return signer.NewRemoteSigner(c.Producer.RemoteSigner, address, token)
+	}
+
+	keyPair, err := c.ProducerKeyPair(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	protection, err := signer.OpenProtection(filepath.Join(c.DataPath, signer.DefaultProtectionFile))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open signer protection. Reason:%w", err)
+	}
+	return signer.NewLocalSigner(keyPair, protection), nil
+}
+
+// ProducerKeyPair unlocks the producer key file and derives the key pair of the producer address
+func (c *Config) ProducerKeyPair(walletManager *wallet.Manager) (*wallet.KeyPair, error) {
+	if c.Producer == nil {
+		return nil, errors.Errorf("no producer defined")
+	}
+
 	// Unlock in wallet
 	if _, err := walletManager.GetKeyFile(c.Producer.KeyFilePath); err != nil {
 		log.Error("unable to get keyFile", "keyFilePath", c.Producer.KeyFilePath, "reason", err)
@@ -155,13 +274,9 @@ func (c *Config) parseProducer(walletManager *wallet.Manager) (*wallet.KeyPair,
 		return nil, err
 	}
 
-	// check address field is set & parse it
-	if c.Producer.Address == "" {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:missing")
-	}
-	address, err := types.ParseAddress(c.Producer.Address)
+	address, err := c.producerAddress()
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:%w", err)
+		return nil, err
 	}
 
 	// get keyStore which should already be unlocked
@@ -184,6 +299,18 @@ func (c *Config) parseProducer(walletManager *wallet.Manager) (*wallet.KeyPair,
 	return keyPair, nil
 }
 
+// producerAddress checks that the address field is set & parses it
+func (c *Config) producerAddress() (types.Address, error) {
+	if c.Producer.Address == "" {
+		return types.ZeroAddress, fmt.Errorf("unable to parse producer address. Reason:missing")
+	}
+	address, err := types.ParseAddress(c.Producer.Address)
+	if err != nil {
+		return types.ZeroAddress, fmt.Errorf("unable to parse producer address. Reason:%w", err)
+	}
+	return address, nil
+}
+
 func (c *Config) makeWalletConfig() *wallet.Config {
 	return &wallet.Config{WalletDir: c.WalletPath}
 }
@@ -215,3 +342,19 @@ func (c *Config) WSEndpoint() string {
 	}
 	return fmt.Sprintf("%s:%d", c.RPC.WSHost, c.RPC.WSPort)
 }
+
+func (c *RPCConfig) makePolicy() *rpc.Policy {
+	return rpc.NewPolicy(rpc.PolicyConfig{
+		Allow:             c.Policy.AllowedMethods,
+		Deny:              c.Policy.DeniedMethods,
+		RateLimit:         c.Policy.RateLimit,
+		RateBurst:         c.Policy.RateBurst,
+		MethodRateLimits:  c.Policy.MethodRateLimits,
+		APIKeys:           c.Policy.APIKeys,
+		AdminKeys:         c.Policy.AdminKeys,
+		Private:           api.PrivateMethods,
+		BatchLimit:        c.Policy.BatchLimit,
+		ResponseSizeLimit: c.Policy.ResponseSizeLimit,
+		SubscriptionLimit: c.Policy.SubscriptionLimit,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/p2p"
 )
 
@@ -40,6 +41,13 @@ var DefaultNodeConfig = Config{
 		MaxPendingPeers:   p2p.DefaultMaxPendingPeers,
 		Seeders:           p2p.DefaultSeeders,
 	},
+	Database: DatabaseConfig{
+		Backend: string(db.DefaultBackend),
+	},
+	Metrics: MetricsConfig{
+		Host: "127.0.0.1",
+		Port: p2p.DefaultMetricsPort,
+	},
 }
 
 // DefaultDataDir is the default data directory to use for the databases and other persistence requirements.
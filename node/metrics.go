@@ -0,0 +1,88 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common/metrics"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
+const (
+	metricsShutdownTimeout = 5 * time.Second
+)
+
+// registerMetrics registers the gauges which are computed from the state of the node at each scrape
+func (node *Node) registerMetrics() {
+	metrics.RegisterFunctionalGauge("chain/momentums/height", func() int64 {
+		return int64(node.z.Chain().GetFrontierMomentumStore().Identifier().Height)
+	})
+	metrics.RegisterFunctionalGauge("chain/accountpool/size", func() int64 {
+		return int64(len(node.z.Chain().GetAllUncommittedAccountBlocks()))
+	})
+	metrics.RegisterFunctionalGauge("downloader/state", func() int64 {
+		return int64(node.z.Protocol().SyncInfo().State)
+	})
+	metrics.RegisterFunctionalGauge("downloader/target", func() int64 {
+		return int64(node.z.Protocol().SyncInfo().TargetHeight)
+	})
+	metrics.RegisterFunctionalGauge("p2p/peers/inbound", func() int64 {
+		return node.countPeers(true)
+	})
+	metrics.RegisterFunctionalGauge("p2p/peers/outbound", func() int64 {
+		return node.countPeers(false)
+	})
+}
+func (node *Node) countPeers(inbound bool) int64 {
+	count := int64(0)
+	for _, peer := range node.server.Peers() {
+		if peer.Inbound() == inbound {
+			count += 1
+		}
+	}
+	return count
+}
+
+// startMetrics serves the metrics in the Prometheus text format on /metrics, if enabled
+func (node *Node) startMetrics() error {
+	if !node.config.Metrics.Enabled {
+		return nil
+	}
+	node.registerMetrics()
+	go metrics.CollectProcessMetrics()
+
+	address := fmt.Sprintf("%v:%v", node.config.Metrics.Host, node.config.Metrics.Port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	node.metrics = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  rpc.DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout: rpc.DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:  rpc.DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go func() {
+		if err := node.metrics.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error("metrics server stopped", "reason", err)
+		}
+	}()
+	log.Info("metrics server started", "endpoint", fmt.Sprintf("http://%v/metrics", listener.Addr()))
+	return nil
+}
+func (node *Node) stopMetrics() {
+	if node.metrics == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	if err := node.metrics.Shutdown(ctx); err != nil {
+		log.Error("failed to stop metrics server", "reason", err)
+	}
+	node.metrics = nil
+}
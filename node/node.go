@@ -2,6 +2,7 @@ package node
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
@@ -10,6 +11,7 @@ import (
 	"github.com/prometheus/tsdb/fileutil"
 
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/metrics"
 	"github.com/zenon-network/go-zenon/p2p"
 	api "github.com/zenon-network/go-zenon/rpc"
 	rpc "github.com/zenon-network/go-zenon/rpc/server"
@@ -30,9 +32,10 @@ type Node struct {
 
 	z zenon.Zenon
 
-	rpcAPIs []rpc.API   // List of APIs currently provided by the node
-	http    *httpServer //
-	ws      *httpServer //
+	rpcAPIs []rpc.API    // List of APIs currently provided by the node
+	http    *httpServer  //
+	ws      *httpServer  //
+	metrics *http.Server // serves /metrics, nil if disabled
 
 	// Channel to wait for termination notifications
 	stop        chan struct{}
@@ -43,6 +46,11 @@ type Node struct {
 func NewNode(conf *Config) (*Node, error) {
 	var err error
 
+	// the collection has to be enabled before the modules are constructed
+	if conf.Metrics.Enabled {
+		metrics.Enable()
+	}
+
 	node := &Node{
 		config:        conf,
 		stop:          make(chan struct{}),
@@ -108,11 +116,17 @@ func (node *Node) Start() error {
 	if err := node.server.Start(); err != nil {
 		return err
 	}
-	node.rpcAPIs = api.GetPublicApis(node.z, node.server)
+	node.rpcAPIs = api.GetPublicApis(node.z, node.server, api.Options{
+		DecodeBlockData: node.config.RPC.DecodeBlockData,
+	})
 	if err := node.startRPC(); err != nil {
 		log.Error("failed to start rpc", "reason", err)
 		return err
 	}
+	if err := node.startMetrics(); err != nil {
+		log.Error("failed to start metrics server", "reason", err)
+		return err
+	}
 
 	return nil
 }
@@ -121,6 +135,8 @@ func (node *Node) Stop() error {
 	defer node.lock.Unlock()
 	defer close(node.stop)
 
+	node.stopMetrics()
+
 	log.Info("stopping p2p server ...")
 	node.server.Stop()
 
@@ -4,12 +4,16 @@ package node
 // startup. It's not meant to be called at any time afterwards as it makes certain
 // assumptions about the state of the node.
 func (node *Node) startRPC() error {
+	// The HTTP and WebSocket servers share the rate limits of the clients
+	policy := node.config.RPC.makePolicy()
+
 	// Configure HTTP.
 	if node.config.RPC.HTTPHost != "" {
 		config := httpConfig{
 			CorsAllowedOrigins: node.config.RPC.HTTPCors,
 			Vhosts:             node.config.RPC.HTTPVirtualHosts,
 			Modules:            node.config.RPC.Endpoints,
+			Policy:             policy,
 			prefix:             "",
 		}
 		if err := node.http.setListenAddr(node.config.RPC.HTTPHost, node.config.RPC.HTTPPort); err != nil {
@@ -26,6 +30,7 @@ func (node *Node) startRPC() error {
 		config := wsConfig{
 			Modules: node.config.RPC.Endpoints,
 			Origins: node.config.RPC.WSOrigins,
+			Policy:  policy,
 			prefix:  "",
 		}
 		if err := server.setListenAddr(node.config.RPC.WSHost, node.config.RPC.WSPort); err != nil {
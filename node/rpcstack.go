@@ -24,6 +24,7 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
+	Policy             *rpc.Policy
 	prefix             string // path prefix on which to mount http handler
 }
 
@@ -31,6 +32,7 @@ type httpConfig struct {
 type wsConfig struct {
 	Origins []string
 	Modules []string
+	Policy  *rpc.Policy
 	prefix  string // path prefix on which to mount ws handler
 }
 
@@ -269,6 +271,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetPolicy(config.Policy)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -301,6 +304,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
+	srv.SetPolicy(config.Policy)
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
@@ -12,10 +12,11 @@ import (
 const (
 	DefaultNodeName = "znn-node"
 
-	DefaultListenHost = "0.0.0.0"
-	DefaultListenPort = 35995
-	DefaultHTTPPort   = 35997
-	DefaultWSPort     = 35998
+	DefaultListenHost  = "0.0.0.0"
+	DefaultListenPort  = 35995
+	DefaultHTTPPort    = 35997
+	DefaultWSPort      = 35998
+	DefaultMetricsPort = 35999
 
 	DefaultMinPeers          = 8
 	DefaultMaxPeers          = 60
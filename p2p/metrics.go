@@ -21,38 +21,38 @@ package p2p
 import (
 	"net"
 
-	"github.com/ethereum/go-ethereum/metrics"
-)
-
-var (
-	ingressConnectMeter = metrics.NewMeter()
-	ingressTrafficMeter = metrics.NewMeter()
-	egressConnectMeter  = metrics.NewMeter()
-	egressTrafficMeter  = metrics.NewMeter()
+	"github.com/zenon-network/go-zenon/common/metrics"
 )
 
 // meteredConn is a wrapper around a network TCP connection that meters both the
 // inbound and outbound network traffic.
 type meteredConn struct {
 	*net.TCPConn // Network connection to wrap with metering
+
+	ingressTrafficMeter metrics.Meter
+	egressTrafficMeter  metrics.Meter
 }
 
 // newMeteredConn creates a new metered connection, also bumping the ingress or
 // egress connection meter.
 func newMeteredConn(conn net.Conn, ingress bool) net.Conn {
 	if ingress {
-		ingressConnectMeter.Mark(1)
+		metrics.GetOrRegisterMeter("p2p/ingress/connects").Mark(1)
 	} else {
-		egressConnectMeter.Mark(1)
+		metrics.GetOrRegisterMeter("p2p/egress/connects").Mark(1)
+	}
+	return &meteredConn{
+		TCPConn:             conn.(*net.TCPConn),
+		ingressTrafficMeter: metrics.GetOrRegisterMeter("p2p/ingress/bytes"),
+		egressTrafficMeter:  metrics.GetOrRegisterMeter("p2p/egress/bytes"),
 	}
-	return &meteredConn{conn.(*net.TCPConn)}
 }
 
 // Read delegates a network read to the underlying connection, bumping the ingress
 // traffic meter along the way.
 func (c *meteredConn) Read(b []byte) (n int, err error) {
 	n, err = c.TCPConn.Read(b)
-	ingressTrafficMeter.Mark(int64(n))
+	c.ingressTrafficMeter.Mark(int64(n))
 	return
 }
 
@@ -60,6 +60,6 @@ func (c *meteredConn) Read(b []byte) (n int, err error) {
 // egress traffic meter along the way.
 func (c *meteredConn) Write(b []byte) (n int, err error) {
 	n, err = c.TCPConn.Write(b)
-	egressTrafficMeter.Mark(int64(n))
+	c.egressTrafficMeter.Mark(int64(n))
 	return
 }
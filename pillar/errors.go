@@ -8,4 +8,5 @@ var (
 	ErrNotOurEvent        = errors.Errorf("not our event")
 	ErrEventHasNotStarted = errors.Errorf("current time is before start time")
 	ErrEventEnded         = errors.Errorf("current time is after the event's finish time time")
+	ErrSignDataMismatch   = errors.Errorf("data to sign doesn't match the hash of the template")
 )
@@ -5,6 +5,7 @@ import (
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
 )
 
 type Manager interface {
@@ -20,6 +21,11 @@ type Manager interface {
 	// and be able to wait for it to finish.
 	Process(e consensus.ProducerEvent) common.Task
 
+	// SetCoinBase signs with the key pair held in memory, with a double-sign protection which is only kept in memory
 	SetCoinBase(coinbase *wallet.KeyPair)
+	// SetSigner delegates the signing of momentums and account-blocks to signer
+	SetSigner(signer signer.Signer)
 	GetCoinBase() *types.Address
+	// GetSlotReport returns the outcome of the own slot which started at timestamp, nil if this node has no record of it
+	GetSlotReport(timestamp int64) *SlotReport
 }
@@ -13,11 +13,12 @@ import (
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
 )
 
 type manager struct {
-	log      log15.Logger
-	coinbase *wallet.KeyPair
+	log    log15.Logger
+	signer signer.Signer
 
 	worker *worker
 
@@ -77,10 +78,10 @@ func (m *manager) shouldProcess(e consensus.ProducerEvent) error {
 	if m.broadcaster.SyncInfo().State != protocol.SyncDone {
 		return ErrSyncNotDone
 	}
-	if m.coinbase == nil {
+	if m.signer == nil {
 		return ErrPillarNotDefined
 	}
-	if m.coinbase.Address != e.Producer {
+	if m.signer.Address() != e.Producer {
 		return ErrNotOurEvent
 	}
 	if common.Clock.Now().Before(e.StartTime) {
@@ -94,6 +95,14 @@ func (m *manager) shouldProcess(e consensus.ProducerEvent) error {
 func (m *manager) processSupervised(e consensus.ProducerEvent) {
 	if err := m.shouldProcess(e); err != nil {
 		m.log.Info("do not process current event", "event", e, "reason", err)
+		if m.signer != nil && m.signer.Address() == e.Producer {
+			switch err {
+			case ErrSyncNotDone:
+				m.worker.reportSlot(e, SlotNotSynced, err, nil)
+			case ErrEventEnded:
+				m.worker.reportSlot(e, SlotEventExpired, err, nil)
+			}
+		}
 		return
 	}
 
@@ -114,6 +123,9 @@ func (m *manager) processSupervised(e consensus.ProducerEvent) {
 		if currentTime := time.Now(); currentTime.After(endTime) {
 			m.log.Info("force-stopping producer task")
 			task.ForceStop()
+			if m.worker.slots.get(e.StartTime.Unix()) == nil {
+				m.worker.reportSlot(e, SlotTimeout, nil, nil)
+			}
 			break
 		}
 	}
@@ -130,12 +142,21 @@ func (m *manager) Process(e consensus.ProducerEvent) common.Task {
 }
 
 func (m *manager) SetCoinBase(coinbase *wallet.KeyPair) {
-	m.coinbase = coinbase
-	m.worker.coinbase = coinbase
+	protection, err := signer.OpenProtection("")
+	common.DealWithErr(err)
+	m.SetSigner(signer.NewLocalSigner(coinbase, protection))
+}
+func (m *manager) SetSigner(signer signer.Signer) {
+	m.signer = signer
+	m.worker.signer = signer
+}
+func (m *manager) GetSlotReport(timestamp int64) *SlotReport {
+	return m.worker.slots.get(timestamp)
 }
 func (m *manager) GetCoinBase() *types.Address {
-	if m.coinbase == nil {
+	if m.signer == nil {
 		return nil
 	}
-	return &m.coinbase.Address
+	address := m.signer.Address()
+	return &address
 }
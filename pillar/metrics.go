@@ -0,0 +1,12 @@
+package pillar
+
+import (
+	"github.com/zenon-network/go-zenon/common/metrics"
+)
+
+func producedMomentumsCounter() metrics.Counter {
+	return metrics.GetOrRegisterCounter("pillar/momentums/produced")
+}
+func missedMomentumsCounter() metrics.Counter {
+	return metrics.GetOrRegisterCounter("pillar/momentums/missed")
+}
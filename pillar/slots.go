@@ -0,0 +1,77 @@
+package pillar
+
+import (
+	"sync"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+)
+
+const (
+	// number of own slots for which the outcome is remembered, a few epochs even for a network with few pillars
+	maxRecordedSlots = 30000
+
+	SlotProduced         = "produced"
+	SlotNotSynced        = "not-synced"
+	SlotEventExpired     = "event-expired"
+	SlotGenerateFailed   = "generate-failed"
+	SlotBroadcastTooLate = "broadcast-too-late"
+	SlotTimeout          = "timeout"
+	// outcomes of missed slots for which the node reported a different outcome or has no record
+	SlotNotIncluded  = "not-included"
+	SlotNotProcessed = "not-processed"
+)
+
+// SlotReport is the outcome of a consensus event in which the local pillar was the producer
+type SlotReport struct {
+	Timestamp int64             `json:"timestamp"`
+	Producer  types.Address     `json:"producer"`
+	Outcome   string            `json:"outcome"`
+	Reason    string            `json:"reason,omitempty"`
+	Momentum  *types.HashHeight `json:"momentum,omitempty"`
+}
+
+// slotRecorder keeps the outcome of the last own slots, by timestamp
+type slotRecorder struct {
+	lock  sync.Mutex
+	slots map[int64]*SlotReport
+	order []int64
+}
+
+func newSlotRecorder() *slotRecorder {
+	return &slotRecorder{
+		slots: make(map[int64]*SlotReport),
+	}
+}
+
+func (sr *slotRecorder) record(e consensus.ProducerEvent, outcome string, reason error, momentum *nom.Momentum) {
+	report := &SlotReport{
+		Timestamp: e.StartTime.Unix(),
+		Producer:  e.Producer,
+		Outcome:   outcome,
+	}
+	if reason != nil {
+		report.Reason = reason.Error()
+	}
+	if momentum != nil {
+		identifier := momentum.Identifier()
+		report.Momentum = &identifier
+	}
+
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+	if _, ok := sr.slots[report.Timestamp]; !ok {
+		sr.order = append(sr.order, report.Timestamp)
+	}
+	sr.slots[report.Timestamp] = report
+	if len(sr.order) > maxRecordedSlots {
+		delete(sr.slots, sr.order[0])
+		sr.order = sr.order[1:]
+	}
+}
+func (sr *slotRecorder) get(timestamp int64) *SlotReport {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+	return sr.slots[timestamp]
+}
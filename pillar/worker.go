@@ -5,13 +5,14 @@ import (
 	"time"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/vm"
-	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
 )
 
 // worker takes care of generating receive blocks for contracts.
@@ -24,7 +25,8 @@ type worker struct {
 	children sync.WaitGroup
 
 	contracts []types.Address
-	coinbase  *wallet.KeyPair
+	signer    signer.Signer
+	slots     *slotRecorder
 
 	// modules
 	chain       chain.Chain
@@ -36,6 +38,7 @@ func newWorker(chain chain.Chain, supervisor *vm.Supervisor, broadcaster protoco
 	return &worker{
 		log:         common.PillarLogger.New("submodule", "worker"),
 		contracts:   types.EmbeddedContracts,
+		slots:       newSlotRecorder(),
 		supervisor:  supervisor,
 		chain:       chain,
 		broadcaster: broadcaster,
@@ -90,6 +93,17 @@ func (w *worker) Process(e consensus.ProducerEvent) common.Task {
 	return task
 }
 
+// reportSlot records the outcome of an own slot. Missed slots are also logged, so operators can be alerted.
+func (w *worker) reportSlot(e consensus.ProducerEvent, outcome string, reason error, momentum *nom.Momentum) {
+	w.slots.record(e, outcome, reason, momentum)
+	if outcome == SlotProduced {
+		producedMomentumsCounter().Inc(1)
+		return
+	}
+	missedMomentumsCounter().Inc(1)
+	w.log.Warn("missed momentum slot", "slot-time", e.StartTime.Unix(), "producer", e.Producer, "outcome", outcome, "reason", reason)
+}
+
 func (w *worker) work(task common.TaskResolver, e consensus.ProducerEvent) {
 	var momentumStore store.Momentum
 
@@ -97,6 +111,7 @@ func (w *worker) work(task common.TaskResolver, e consensus.ProducerEvent) {
 	momentum, err := w.generateMomentum(e)
 	if err != nil {
 		w.log.Error("failed to generate momentum", "reason", err)
+		w.reportSlot(e, SlotGenerateFailed, err, nil)
 		return
 	}
 
@@ -108,9 +123,11 @@ func (w *worker) work(task common.TaskResolver, e consensus.ProducerEvent) {
 	}
 	if common.Clock.Now().After(e.StartTime.Add(3 * time.Second)) {
 		w.log.Error("do not broadcast own momentum", "identifier", momentum.Momentum.Identifier(), "reason", "too-late")
+		w.reportSlot(e, SlotBroadcastTooLate, nil, momentum.Momentum)
 	} else {
 		w.log.Info("broadcasting own momentum", "identifier", momentum.Momentum.Identifier())
 		w.broadcaster.CreateMomentum(momentum)
+		w.reportSlot(e, SlotProduced, nil, momentum.Momentum)
 	}
 
 	if task.ShouldStop() {
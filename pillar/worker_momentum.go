@@ -1,8 +1,12 @@
 package pillar
 
 import (
+	"bytes"
+
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/vm"
 )
 
 func (w *worker) generateMomentum(e consensus.ProducerEvent) (*nom.MomentumTransaction, error) {
@@ -29,5 +33,20 @@ func (w *worker) generateMomentum(e consensus.ProducerEvent) (*nom.MomentumTrans
 	return w.supervisor.GenerateMomentum(&nom.DetailedMomentum{
 		Momentum:      m,
 		AccountBlocks: blocks,
-	}, w.coinbase.Signer)
+	}, w.momentumSignFunc(m))
+}
+
+// momentumSignFunc delegates the signing of m to the signer, which receives the whole momentum instead of its hash
+func (w *worker) momentumSignFunc(m *nom.Momentum) vm.SignFunc {
+	return func(data []byte) ([]byte, *types.Address, []byte, error) {
+		if !bytes.Equal(data, m.Hash.Bytes()) {
+			return nil, nil, nil, ErrSignDataMismatch
+		}
+		signature, publicKey, err := w.signer.SignMomentum(m)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		address := w.signer.Address()
+		return signature, &address, publicKey, nil
+	}
 }
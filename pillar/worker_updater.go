@@ -1,10 +1,13 @@
 package pillar
 
 import (
+	"bytes"
+
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
@@ -21,12 +24,13 @@ func (w *worker) updateContracts(momentumStore store.Momentum) error {
 	for _, address := range types.EmbeddedWUpdate {
 		if err := canPerformEmbeddedUpdate(momentumStore, w.chain, address); err == nil {
 			w.log.Info("producing block to update embedded-contract", "contract-address", address)
-			if block, err := w.supervisor.GenerateFromTemplate(&nom.AccountBlock{
+			template := &nom.AccountBlock{
 				BlockType: nom.BlockTypeUserSend,
-				Address:   w.coinbase.Address,
+				Address:   w.signer.Address(),
 				ToAddress: address,
 				Data:      definition.ABICommon.PackMethodPanic(definition.UpdateMethodName),
-			}, w.coinbase.Signer); err != nil {
+			}
+			if block, err := w.supervisor.GenerateFromTemplate(template, w.accountBlockSignFunc(template)); err != nil {
 				return err
 			} else {
 				w.broadcaster.CreateAccountBlock(block)
@@ -38,3 +42,18 @@ func (w *worker) updateContracts(momentumStore store.Momentum) error {
 	}
 	return nil
 }
+
+// accountBlockSignFunc delegates the signing of block to the signer, which receives the whole block instead of its hash
+func (w *worker) accountBlockSignFunc(block *nom.AccountBlock) vm.SignFunc {
+	return func(data []byte) ([]byte, *types.Address, []byte, error) {
+		if !bytes.Equal(data, block.Hash.Bytes()) {
+			return nil, nil, nil, ErrSignDataMismatch
+		}
+		signature, publicKey, err := w.signer.SignAccountBlock(block)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		address := w.signer.Address()
+		return signature, &address, publicKey, nil
+	}
+}
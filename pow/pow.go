@@ -2,7 +2,9 @@ package pow
 
 import (
 	"encoding/binary"
+	"math"
 	"math/big"
+	"time"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
@@ -36,6 +38,25 @@ func GetPoWNonce(difficulty *big.Int, dataHash types.Hash) []byte {
 	return arr[:]
 }
 
+// MeasureHashRate returns the number of nonces per second which GetPoWNonce checks on this host, measured for duration.
+// The expected number of nonces checked to find a valid one is equal to the difficulty.
+func MeasureHashRate(duration time.Duration) uint64 {
+	const batchSize = 1024
+	rng := wallet.GetEntropyCSPRNG(8)
+	calc, target := getTarget(new(big.Int).SetUint64(math.MaxUint64), types.ZeroHash, rng)
+
+	attempts := uint64(0)
+	start := time.Now()
+	for time.Since(start) < duration {
+		for i := 0; i < batchSize; i += 1 {
+			greaterDifficulty(crypto.Hash(calc), target[:])
+			calc = quickInc(calc)
+		}
+		attempts += batchSize
+	}
+	return uint64(float64(attempts) / time.Since(start).Seconds())
+}
+
 func getTarget(difficulty *big.Int, data types.Hash, nonce []byte) ([]byte, [8]byte) {
 	threshold := GetThresholdByDifficulty(difficulty)
 	calc := make([]byte, 40)
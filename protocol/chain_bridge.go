@@ -83,6 +83,10 @@ func (c chainBridge) GetBlock(hash types.Hash) *nom.DetailedMomentum {
 
 	for i := range prefetched {
 		block, _ := store.GetAccountBlock(*momentum.Content[i])
+		if block == nil {
+			// account-blocks of old momentums are not available on pruned nodes
+			return nil
+		}
 		prefetched[i] = block
 	}
 
@@ -163,7 +167,7 @@ func (c chainBridge) InsertChain(momentums []*nom.DetailedMomentum) (int, error)
 		}
 
 		// check that the distance allows rollback
-		if ourFrontier.Height-target.Height > 30 {
+		if ourFrontier.Height-target.Height > chain.StableDepth {
 			return 0, errors.Errorf("can't rollback to %v. Too far. Frontier is %v. Wanted to be able to insert %v", target.Identifier(), ourFrontier.Identifier(), head.Identifier())
 		}
 
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/rollback"
+)
+
+// AdminApi changes the local state of the node. It's not public, so it's only served if listed in the RPC endpoints.
+type AdminApi struct {
+	z   zenon.Zenon
+	log log15.Logger
+}
+
+func NewAdminApi(z zenon.Zenon) *AdminApi {
+	return &AdminApi{
+		z:   z,
+		log: common.RPCLogger.New("module", "admin_api"),
+	}
+}
+
+// RollbackTo removes the momentums which follow the momentum with the given height or hash, and the account-blocks
+// they confirmed. The node syncs the following momentums again from its peers. Stable momentums are only removed if
+// force is set.
+func (a *AdminApi) RollbackTo(to string, force bool) (*rollback.Result, error) {
+	a.log.Warn("rolling back the chain", "to", to, "force", force)
+	result, err := rollback.Rollback(a.z.Chain(), to, force)
+	if err != nil {
+		return nil, err
+	}
+	a.log.Warn("rolled back the chain", "previous", result.Previous, "frontier", result.Frontier, "momentums", result.Momentums)
+	return result, nil
+}
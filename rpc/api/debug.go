@@ -0,0 +1,156 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+// DebugApi replays momentums and account-blocks which are part of the chain to show their effects.
+// Replaying requires the state before the momentum, so only the momentums which weren't pruned can be traced.
+type DebugApi struct {
+	z     zenon.Zenon
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewDebugApi(z zenon.Zenon) *DebugApi {
+	return &DebugApi{
+		z:     z,
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "debug_api"),
+	}
+}
+
+type StorageChange struct {
+	Key    string  `json:"key"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// AccountBlockTrace is the effect of an account-block on the storage and balances of its account.
+//   - Call is the decoded call received by contract receive-blocks
+//   - ReturnedError is the error returned by the embedded method, in which case the sent funds were refunded
+//   - Error is set if the replayed block doesn't match the block in the chain
+type AccountBlockTrace struct {
+	BlockHash      types.Hash                          `json:"blockHash"`
+	Address        types.Address                       `json:"address"`
+	Call           *embedded.DecodedCall               `json:"call"`
+	Changes        []*StorageChange                    `json:"changes"`
+	BalanceChanges map[types.ZenonTokenStandard]string `json:"balanceChanges"`
+	Descendants    []*nom.AccountBlock                 `json:"descendantBlocks"`
+	ReturnedError  string                              `json:"returnedError"`
+	Error          string                              `json:"error"`
+}
+
+type MomentumTrace struct {
+	Hash   types.Hash           `json:"hash"`
+	Height uint64               `json:"height"`
+	Blocks []*AccountBlockTrace `json:"blocks"`
+}
+
+func encodeTraceValue(value []byte) *string {
+	if value == nil {
+		return nil
+	}
+	text := hexutil.Encode(value)
+	return &text
+}
+
+func (d *DebugApi) toRpc(trace *vm.BlockTrace) (*AccountBlockTrace, error) {
+	result := &AccountBlockTrace{
+		BlockHash:      trace.Block.Hash,
+		Address:        trace.Block.Address,
+		Changes:        make([]*StorageChange, len(trace.Changes)),
+		BalanceChanges: make(map[types.ZenonTokenStandard]string, len(trace.BalanceChanges)),
+		Descendants:    trace.Descendants,
+	}
+	for index, change := range trace.Changes {
+		result.Changes[index] = &StorageChange{
+			Key:    hexutil.Encode(change.Key),
+			Before: encodeTraceValue(change.Before),
+			After:  encodeTraceValue(change.After),
+		}
+	}
+	for zts, delta := range trace.BalanceChanges {
+		result.BalanceChanges[zts] = delta.String()
+	}
+	if trace.ReturnedError != nil {
+		result.ReturnedError = trace.ReturnedError.Error()
+	}
+	if trace.BlockError != nil {
+		result.Error = trace.BlockError.Error()
+	}
+
+	if trace.Block.BlockType == nom.BlockTypeContractReceive {
+		sendBlock, err := d.chain.GetFrontierMomentumStore().GetAccountBlockByHash(trace.Block.FromBlockHash)
+		if err != nil && err != db.ErrPruned {
+			return nil, err
+		}
+		if sendBlock != nil {
+			result.Call, _ = embedded.DecodeEmbeddedCallJSON(sendBlock.ToAddress, sendBlock.Data)
+		}
+	}
+	return result, nil
+}
+
+// TraceAccountBlockByHash replays the confirmed account-block. Contract send-blocks are traced with the contract
+// receive-block which generated them.
+func (d *DebugApi) TraceAccountBlockByHash(hash types.Hash) (*AccountBlockTrace, error) {
+	block, err := d.chain.GetFrontierMomentumStore().GetAccountBlockByHash(hash)
+	if err == db.ErrPruned {
+		return nil, ErrDataPruned
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	supervisor := vm.NewSupervisor(d.chain, d.z.Consensus())
+	trace, err := supervisor.TraceBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	return d.toRpc(trace)
+}
+
+// TraceMomentumByHeight replays all the account-blocks confirmed by the momentum at height
+func (d *DebugApi) TraceMomentumByHeight(height uint64) (*MomentumTrace, error) {
+	momentum, err := d.chain.GetFrontierMomentumStore().GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+
+	supervisor := vm.NewSupervisor(d.chain, d.z.Consensus())
+	traces, err := supervisor.TraceMomentum(momentum)
+	if err == db.ErrPruned {
+		return nil, ErrDataPruned
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &MomentumTrace{
+		Hash:   momentum.Hash,
+		Height: momentum.Height,
+		Blocks: make([]*AccountBlockTrace, len(traces)),
+	}
+	for index, trace := range traces {
+		if result.Blocks[index], err = d.toRpc(trace); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
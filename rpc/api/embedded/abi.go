@@ -0,0 +1,111 @@
+package embedded
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/abi"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+type AbiApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewAbiApi(z zenon.Zenon) *AbiApi {
+	return &AbiApi{
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "embedded_abi_api"),
+	}
+}
+
+type AbiInput struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AbiMethod is an entry of the JSON ABI of a contract, together with the signature and the selector of the method
+type AbiMethod struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Inputs    []*AbiInput `json:"inputs"`
+	Signature string      `json:"signature"`
+	Selector  string      `json:"selector"`
+}
+
+// EmbeddedContract lists the methods of an embedded contract which can be called at the frontier momentum
+type EmbeddedContract struct {
+	Address types.Address `json:"address"`
+	Abi     []*AbiMethod  `json:"abi"`
+}
+
+func newEmbeddedContract(address types.Address, contractAbi *abi.ABIContract) *EmbeddedContract {
+	contract := &EmbeddedContract{
+		Address: address,
+		Abi:     make([]*AbiMethod, 0, len(contractAbi.Methods)),
+	}
+	for _, method := range contractAbi.Methods {
+		inputs := make([]*AbiInput, len(method.Inputs))
+		for i, input := range method.Inputs {
+			inputs[i] = &AbiInput{
+				Name: input.Name,
+				Type: input.Type.String(),
+			}
+		}
+		contract.Abi = append(contract.Abi, &AbiMethod{
+			Type:      "function",
+			Name:      method.Name,
+			Inputs:    inputs,
+			Signature: method.Sig(),
+			Selector:  hexutil.Encode(method.Id()),
+		})
+	}
+	sort.Slice(contract.Abi, func(i, j int) bool {
+		return contract.Abi[i].Name < contract.Abi[j].Name
+	})
+	return contract
+}
+
+// GetContracts returns the embedded contracts with the methods enabled by the sporks active at the frontier momentum
+func (a *AbiApi) GetContracts() ([]*EmbeddedContract, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.SporkContract)
+	if err != nil {
+		return nil, err
+	}
+	contracts := embedded.GetEmbeddedContracts(context)
+	list := make([]*EmbeddedContract, 0, len(contracts))
+	for _, address := range types.EmbeddedContracts {
+		if contractAbi, ok := contracts[address]; ok {
+			list = append(list, newEmbeddedContract(address, contractAbi))
+		}
+	}
+	return list, nil
+}
+
+// GetContract returns the methods of the embedded contract found at address which are enabled at the frontier momentum
+func (a *AbiApi) GetContract(address types.Address) (*EmbeddedContract, error) {
+	_, context, err := api.GetFrontierContext(a.chain, address)
+	if err != nil {
+		return nil, err
+	}
+	contractAbi, ok := embedded.GetEmbeddedContracts(context)[address]
+	if !ok {
+		return nil, constants.ErrContractDoesntExist
+	}
+	return newEmbeddedContract(address, contractAbi), nil
+}
+
+// DecodeCall decodes the data of a call made to the embedded contract found at address.
+// Methods added by sporks are decoded regardless of whether the sporks are active, so that old and new blocks can be decoded.
+func (a *AbiApi) DecodeCall(address types.Address, data []byte) (*embedded.DecodedCall, error) {
+	return embedded.DecodeEmbeddedCallJSON(address, data)
+}
@@ -0,0 +1,324 @@
+package embedded
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// economicsMaxEpochCount limits the number of epochs returned at once, since each epoch requires the consensus stats
+	economicsMaxEpochCount = 100
+)
+
+// rewardContracts are the embedded contracts which distribute the network emission, by the name used in LastEpochs
+var rewardContracts = map[string]types.Address{
+	"pillar":    types.PillarContract,
+	"sentinel":  types.SentinelContract,
+	"stake":     types.StakeContract,
+	"liquidity": types.LiquidityContract,
+}
+
+type EconomicsApi struct {
+	chain        chain.Chain
+	cs           consensus.Consensus
+	log          log15.Logger
+	rewardTotals *rewardTotalsCache
+}
+
+func NewEconomicsApi(z zenon.Zenon) *EconomicsApi {
+	return &EconomicsApi{
+		chain:        z.Chain(),
+		cs:           z.Consensus(),
+		log:          common.RPCLogger.New("module", "stats_economics_api"),
+		rewardTotals: newRewardTotalsCache(),
+	}
+}
+
+// rewardTotalsCache keeps, for each reward contract, the totals of the rewards deposited for the epochs which the contract
+// already distributed. The deposits of such an epoch don't change unless the momentum which distributed them is rollbacked,
+// so the totals are read again only after a new epoch is distributed or when the momentum they were read at is rollbacked.
+type rewardTotalsCache struct {
+	lock    sync.Mutex
+	entries map[types.Address]*rewardTotals
+}
+type rewardTotals struct {
+	readAt    types.HashHeight
+	lastEpoch int64
+	totals    map[uint64]*definition.RewardDepositHistory
+}
+
+func newRewardTotalsCache() *rewardTotalsCache {
+	return &rewardTotalsCache{
+		entries: make(map[types.Address]*rewardTotals),
+	}
+}
+
+// get returns the totals by epoch of the contract, whose storage is read at the frontier momentum
+func (c *rewardTotalsCache) get(chain chain.Chain, contract types.Address, frontier *nom.Momentum, storage db.DB, lastEpoch int64) (map[uint64]*definition.RewardDepositHistory, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[contract]; ok && entry.lastEpoch == lastEpoch {
+		momentum, err := chain.GetFrontierMomentumStore().GetMomentumByHeight(entry.readAt.Height)
+		if err != nil {
+			return nil, err
+		}
+		if momentum != nil && momentum.Hash == entry.readAt.Hash {
+			return entry.totals, nil
+		}
+	}
+
+	totals := make(map[uint64]*definition.RewardDepositHistory)
+	if lastEpoch >= 0 {
+		var err error
+		if totals, err = definition.GetRewardDepositHistoryTotals(storage, 0, uint64(lastEpoch)); err != nil {
+			return nil, err
+		}
+	}
+	c.entries[contract] = &rewardTotals{
+		readAt:    frontier.Identifier(),
+		lastEpoch: lastEpoch,
+		totals:    totals,
+	}
+	return totals, nil
+}
+
+// TokenEconomics splits the total supply of ZNN or QSR into
+//   - Locked, the balances of the embedded contracts, by contract
+//   - Circulating, the rest of the total supply
+type TokenEconomics struct {
+	TokenStandard types.ZenonTokenStandard
+	TotalSupply   *big.Int
+	MaxSupply     *big.Int
+	Circulating   *big.Int
+	Locked        map[string]*big.Int
+}
+type TokenEconomicsMarshal struct {
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	TotalSupply   string                   `json:"totalSupply"`
+	MaxSupply     string                   `json:"maxSupply"`
+	Circulating   string                   `json:"circulating"`
+	Locked        map[string]string        `json:"locked"`
+}
+
+func (e *TokenEconomics) MarshalJSON() ([]byte, error) {
+	locked := make(map[string]string, len(e.Locked))
+	for name, amount := range e.Locked {
+		locked[name] = amount.String()
+	}
+	return json.Marshal(&TokenEconomicsMarshal{
+		TokenStandard: e.TokenStandard,
+		TotalSupply:   e.TotalSupply.String(),
+		MaxSupply:     e.MaxSupply.String(),
+		Circulating:   e.Circulating.String(),
+		Locked:        locked,
+	})
+}
+func (e *TokenEconomics) UnmarshalJSON(data []byte) error {
+	aux := new(TokenEconomicsMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	e.TokenStandard = aux.TokenStandard
+	e.TotalSupply = common.StringToBigInt(aux.TotalSupply)
+	e.MaxSupply = common.StringToBigInt(aux.MaxSupply)
+	e.Circulating = common.StringToBigInt(aux.Circulating)
+	e.Locked = make(map[string]*big.Int, len(aux.Locked))
+	for name, amount := range aux.Locked {
+		e.Locked[name] = common.StringToBigInt(amount)
+	}
+	return nil
+}
+
+type EconomicsSupply struct {
+	Znn            *TokenEconomics `json:"znn"`
+	Qsr            *TokenEconomics `json:"qsr"`
+	MomentumHeight uint64          `json:"momentumHeight"`
+}
+
+// GetSupply returns the total, circulating and locked supply of ZNN and QSR at the frontier momentum.
+// Amounts sent to an embedded contract and not received yet are considered circulating.
+func (a *EconomicsApi) GetSupply() (*EconomicsSupply, error) {
+	momentumStore := a.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	supply := &EconomicsSupply{MomentumHeight: frontier.Height}
+	for _, zts := range []types.ZenonTokenStandard{types.ZnnTokenStandard, types.QsrTokenStandard} {
+		tokenInfo, err := momentumStore.GetTokenInfoByTs(zts)
+		if err != nil {
+			return nil, err
+		}
+		locked, totalLocked, err := getLockedSupply(momentumStore, zts)
+		if err != nil {
+			return nil, err
+		}
+		economics := &TokenEconomics{
+			TokenStandard: zts,
+			TotalSupply:   tokenInfo.TotalSupply,
+			MaxSupply:     tokenInfo.MaxSupply,
+			Circulating:   new(big.Int).Sub(tokenInfo.TotalSupply, totalLocked),
+			Locked:        locked,
+		}
+
+		if zts == types.ZnnTokenStandard {
+			supply.Znn = economics
+		} else {
+			supply.Qsr = economics
+		}
+	}
+	return supply, nil
+}
+
+type RewardAmounts struct {
+	Znn *big.Int
+	Qsr *big.Int
+}
+type RewardAmountsMarshal struct {
+	Znn string `json:"znnAmount"`
+	Qsr string `json:"qsrAmount"`
+}
+
+func newRewardAmounts() *RewardAmounts {
+	return &RewardAmounts{
+		Znn: big.NewInt(0),
+		Qsr: big.NewInt(0),
+	}
+}
+func (r *RewardAmounts) add(o *RewardAmounts) {
+	r.Znn.Add(r.Znn, o.Znn)
+	r.Qsr.Add(r.Qsr, o.Qsr)
+}
+func (r *RewardAmounts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&RewardAmountsMarshal{
+		Znn: r.Znn.String(),
+		Qsr: r.Qsr.String(),
+	})
+}
+func (r *RewardAmounts) UnmarshalJSON(data []byte) error {
+	aux := new(RewardAmountsMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	r.Znn = common.StringToBigInt(aux.Znn)
+	r.Qsr = common.StringToBigInt(aux.Qsr)
+	return nil
+}
+
+// EpochEconomics compares the emission scheduled for an epoch with the rewards which were distributed for it, by role.
+// The rewards of an epoch are distributed by each contract after the epoch ends.
+type EpochEconomics struct {
+	Epoch       uint64         `json:"epoch"`
+	Emission    *RewardAmounts `json:"emission"`
+	Distributed *RewardAmounts `json:"distributed"`
+	Pillars     *RewardAmounts `json:"pillars"`
+	Delegators  *RewardAmounts `json:"delegators"`
+	Sentinels   *RewardAmounts `json:"sentinels"`
+	Stakers     *RewardAmounts `json:"stakers"`
+	Liquidity   *RewardAmounts `json:"liquidity"`
+}
+
+// EpochEconomicsList holds the requested epochs together with the last epoch for which each contract distributed rewards
+type EpochEconomicsList struct {
+	LastEpochs map[string]int64  `json:"lastEpochs"`
+	List       []*EpochEconomics `json:"list"`
+}
+
+// GetRewardsByEpoch returns the time series of the emission and reward distribution, starting with startEpoch.
+// The list ends with the last epoch for which any contract distributed rewards.
+func (a *EconomicsApi) GetRewardsByEpoch(startEpoch uint64, count uint64) (*EpochEconomicsList, error) {
+	if count > economicsMaxEpochCount {
+		return nil, api.ErrCountParamTooBig
+	}
+
+	result := &EpochEconomicsList{
+		LastEpochs: make(map[string]int64, len(rewardContracts)),
+		List:       make([]*EpochEconomics, 0),
+	}
+	lastEpoch := int64(-1)
+	totals := make(map[string]map[uint64]*definition.RewardDepositHistory, len(rewardContracts))
+	for name, contract := range rewardContracts {
+		frontier, context, err := api.GetFrontierContext(a.chain, contract)
+		if err != nil {
+			return nil, err
+		}
+		last, err := definition.GetLastEpochUpdate(context.Storage())
+		if err != nil {
+			return nil, err
+		}
+		result.LastEpochs[name] = last.LastEpoch
+		if last.LastEpoch > lastEpoch {
+			lastEpoch = last.LastEpoch
+		}
+		if totals[name], err = a.rewardTotals.get(a.chain, contract, frontier, context.Storage(), last.LastEpoch); err != nil {
+			return nil, err
+		}
+	}
+
+	_, pillarContext, err := api.GetFrontierContext(a.chain, types.PillarContract)
+	if err != nil {
+		return nil, err
+	}
+	reader := a.cs.FrontierPillarReader()
+	deposited := func(name string, epoch uint64) *RewardAmounts {
+		total, ok := totals[name][epoch]
+		if !ok {
+			return newRewardAmounts()
+		}
+		// the totals are shared by the cache
+		return &RewardAmounts{Znn: new(big.Int).Set(total.Znn), Qsr: new(big.Int).Set(total.Qsr)}
+	}
+
+	for epoch := startEpoch; epoch < startEpoch+count && int64(epoch) <= lastEpoch; epoch += 1 {
+		entry := &EpochEconomics{
+			Epoch: epoch,
+			Emission: &RewardAmounts{
+				Znn: big.NewInt(constants.NetworkZnnRewardPerEpoch(epoch)),
+				Qsr: big.NewInt(constants.NetworkQsrRewardPerEpoch(epoch)),
+			},
+			Distributed: newRewardAmounts(),
+			Pillars:     newRewardAmounts(),
+			Delegators:  deposited("pillar", epoch),
+			Sentinels:   deposited("sentinel", epoch),
+			Stakers:     deposited("stake", epoch),
+			Liquidity:   deposited("liquidity", epoch),
+		}
+
+		// the pillar contract deposits the rewards of the pillars and of their delegators together
+		if entry.Delegators.Znn.Sign() != 0 {
+			histories, err := definition.GetPillarEpochHistoryList(pillarContext.Storage(), epoch)
+			if err != nil {
+				return nil, err
+			}
+			pillars, err := implementation.ComputePillarsShareForEpoch(reader, histories, epoch)
+			if err != nil {
+				return nil, err
+			}
+			entry.Pillars.Znn = pillars
+			entry.Delegators.Znn = new(big.Int).Sub(entry.Delegators.Znn, pillars)
+		}
+
+		for _, amounts := range []*RewardAmounts{entry.Pillars, entry.Delegators, entry.Sentinels, entry.Stakers, entry.Liquidity} {
+			entry.Distributed.add(amounts)
+		}
+		result.List = append(result.List, entry)
+	}
+	return result, nil
+}
@@ -1,9 +1,13 @@
 package embedded
 
 import (
+	"encoding/json"
+
 	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
@@ -13,6 +17,13 @@ import (
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
+const (
+	HtlcStatusActive    = "active"
+	HtlcStatusExpired   = "expired"
+	HtlcStatusUnlocked  = "unlocked"
+	HtlcStatusReclaimed = "reclaimed"
+)
+
 type HtlcApi struct {
 	chain chain.Chain
 	z     zenon.Zenon
@@ -29,6 +40,44 @@ func NewHtlcApi(z zenon.Zenon) *HtlcApi {
 	}
 }
 
+// HtlcEntry is an htlc together with its status. Preimage is set once the htlc is unlocked
+type HtlcEntry struct {
+	*definition.HtlcInfo
+	Status   string
+	Preimage []byte
+}
+type HtlcEntryMarshal struct {
+	*definition.HtlcInfoMarshal
+	Status   string `json:"status"`
+	Preimage []byte `json:"preimage"`
+}
+
+func (e *HtlcEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&HtlcEntryMarshal{
+		HtlcInfoMarshal: e.HtlcInfo.ToHtlcInfoMarshal(),
+		Status:          e.Status,
+		Preimage:        e.Preimage,
+	})
+}
+func (e *HtlcEntry) UnmarshalJSON(data []byte) error {
+	e.HtlcInfo = new(definition.HtlcInfo)
+	if err := e.HtlcInfo.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	aux := new(HtlcEntryMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	e.Status = aux.Status
+	e.Preimage = aux.Preimage
+	return nil
+}
+
+type HtlcEntryList struct {
+	Count int          `json:"count"`
+	List  []*HtlcEntry `json:"list"`
+}
+
 func (a *HtlcApi) GetById(id types.Hash) (*definition.HtlcInfo, error) {
 
 	_, context, err := api.GetFrontierContext(a.chain, types.HtlcContract)
@@ -51,3 +100,71 @@ func (a *HtlcApi) GetProxyUnlockStatus(address types.Address) (bool, error) {
 	}
 	return implementation.GetHtlcProxyUnlockStatus(context, address)
 }
+
+// GetByTimeLockedAddress returns the htlcs created by address, sorted by expiration time in descending order.
+// Requires the htlc index to be enabled on the node.
+func (a *HtlcApi) GetByTimeLockedAddress(address types.Address, pageIndex, pageSize uint32) (*HtlcEntryList, error) {
+	return a.getEntries(pageIndex, pageSize, func(htlcIndex *index.HtlcIndex, skip, count int) (*index.HtlcList, error) {
+		return htlcIndex.ByTimeLocked(address, skip, count)
+	})
+}
+
+// GetByHashLockedAddress returns the htlcs which can be unlocked by address, sorted by expiration time in descending order.
+// Requires the htlc index to be enabled on the node.
+func (a *HtlcApi) GetByHashLockedAddress(address types.Address, pageIndex, pageSize uint32) (*HtlcEntryList, error) {
+	return a.getEntries(pageIndex, pageSize, func(htlcIndex *index.HtlcIndex, skip, count int) (*index.HtlcList, error) {
+		return htlcIndex.ByHashLocked(address, skip, count)
+	})
+}
+
+// GetByHashLock returns the htlcs locked with hashLock, sorted by expiration time in descending order.
+// Requires the htlc index to be enabled on the node.
+func (a *HtlcApi) GetByHashLock(hashLock []byte, pageIndex, pageSize uint32) (*HtlcEntryList, error) {
+	return a.getEntries(pageIndex, pageSize, func(htlcIndex *index.HtlcIndex, skip, count int) (*index.HtlcList, error) {
+		return htlcIndex.ByHashLock(hashLock, skip, count)
+	})
+}
+
+func (a *HtlcApi) getEntries(pageIndex, pageSize uint32, query func(*index.HtlcIndex, int, int) (*index.HtlcList, error)) (*HtlcEntryList, error) {
+	htlcIndex := a.z.HtlcIndex()
+	if htlcIndex == nil {
+		return nil, api.ErrHtlcIndexDisabled
+	}
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	// the index would otherwise silently answer from the momentums indexed before the failure
+	if err := htlcIndex.Failure(); err != nil {
+		return nil, errors.Errorf("htlc index can't catch up with the chain: %v", err)
+	}
+
+	frontier, err := a.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	result, err := query(htlcIndex, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*HtlcEntry, 0, len(result.List))
+	for _, htlc := range result.List {
+		entry := &HtlcEntry{HtlcInfo: htlc.HtlcInfo, Preimage: htlc.Preimage}
+		switch htlc.Status {
+		case index.HtlcUnlocked:
+			entry.Status = HtlcStatusUnlocked
+		case index.HtlcReclaimed:
+			entry.Status = HtlcStatusReclaimed
+		default:
+			entry.Status = HtlcStatusActive
+			if frontier.Timestamp.Unix() >= htlc.ExpirationTime {
+				entry.Status = HtlcStatusExpired
+			}
+		}
+		list = append(list, entry)
+	}
+	return &HtlcEntryList{
+		Count: int(result.Count),
+		List:  list,
+	}, nil
+}
@@ -129,6 +129,9 @@ func (a *LiquidityApi) GetLiquidityStakeEntriesByAddress(address types.Address,
 func (a *LiquidityApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
 	return getUncollectedReward(a.chain, types.LiquidityContract, address)
 }
+func (a *LiquidityApi) GetUncollectedRewardAt(address types.Address, height uint64) (*definition.RewardDeposit, error) {
+	return getUncollectedRewardAt(a.chain, types.LiquidityContract, address, height)
+}
 func (a *LiquidityApi) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*RewardHistoryList, error) {
 	if pageSize > api.RpcMaxPageSize {
 		return nil, api.ErrPageSizeParamTooBig
@@ -14,6 +14,7 @@ import (
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -45,6 +46,9 @@ func (a *PillarApi) GetDepositedQsr(address types.Address) (string, error) {
 func (a *PillarApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
 	return getUncollectedReward(a.chain, types.PillarContract, address)
 }
+func (a *PillarApi) GetUncollectedRewardAt(address types.Address, height uint64) (*definition.RewardDeposit, error) {
+	return getUncollectedRewardAt(a.chain, types.PillarContract, address, height)
+}
 func (a *PillarApi) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*RewardHistoryList, error) {
 	if pageSize > api.RpcMaxPageSize {
 		return nil, api.ErrPageSizeParamTooBig
@@ -340,6 +344,16 @@ func (a *PillarApi) GetDelegatedPillar(addr types.Address) (*GetDelegatedPillarR
 	if err != nil {
 		return nil, err
 	}
+	return a.getDelegatedPillar(context, addr)
+}
+func (a *PillarApi) GetDelegatedPillarAt(addr types.Address, height uint64) (*GetDelegatedPillarResponse, error) {
+	_, context, err := api.GetContextByHeight(a.chain, types.PillarContract, height)
+	if err != nil {
+		return nil, err
+	}
+	return a.getDelegatedPillar(context, addr)
+}
+func (a *PillarApi) getDelegatedPillar(context vm_context.AccountVmContext, addr types.Address) (*GetDelegatedPillarResponse, error) {
 	delegationInfo, err := definition.GetDelegationInfo(context.Storage(), addr)
 	if err == constants.ErrDataNonExistent {
 		return nil, nil
@@ -348,7 +362,7 @@ func (a *PillarApi) GetDelegatedPillar(addr types.Address) (*GetDelegatedPillarR
 		return nil, err
 	}
 	if delegationInfo != nil {
-		balance, err := a.chain.GetFrontierMomentumStore().GetAccountStore(addr).GetBalance(types.ZnnTokenStandard)
+		balance, err := context.MomentumStore().GetAccountStore(addr).GetBalance(types.ZnnTokenStandard)
 		if err != nil {
 			return nil, err
 		}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"math/big"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
@@ -13,9 +15,11 @@ import (
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/pow"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -170,8 +174,17 @@ func (a *PlasmaApi) Get(address types.Address) (*PlasmaInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	amount, err := a.chain.GetFrontierMomentumStore().GetStakeBeneficialAmount(address)
+	return a.getPlasmaInfo(context, address)
+}
+func (a *PlasmaApi) GetAt(address types.Address, height uint64) (*PlasmaInfo, error) {
+	_, context, err := api.GetContextByHeight(a.chain, address, height)
+	if err != nil {
+		return nil, err
+	}
+	return a.getPlasmaInfo(context, address)
+}
+func (a *PlasmaApi) getPlasmaInfo(context vm_context.AccountVmContext, address types.Address) (*PlasmaInfo, error) {
+	amount, err := context.MomentumStore().GetStakeBeneficialAmount(address)
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +209,20 @@ func (a *PlasmaApi) GetEntriesByAddress(address types.Address, pageIndex, pageSi
 	if err != nil {
 		return nil, err
 	}
+	return a.getEntriesByAddress(context, address, pageIndex, pageSize)
+}
+func (a *PlasmaApi) GetEntriesByAddressAt(address types.Address, height uint64, pageIndex, pageSize uint32) (*FusionEntryList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetContextByHeight(a.chain, types.PlasmaContract, height)
+	if err != nil {
+		return nil, err
+	}
+	return a.getEntriesByAddress(context, address, pageIndex, pageSize)
+}
+func (a *PlasmaApi) getEntriesByAddress(context vm_context.AccountVmContext, address types.Address, pageIndex, pageSize uint32) (*FusionEntryList, error) {
 	list, amount, err := definition.GetFusionInfoListByOwner(context.Storage(), address)
 	if err != nil {
 		return nil, err
@@ -278,3 +305,103 @@ func (a *PlasmaApi) GetRequiredPoWForAccountBlock(param GetRequiredParam) (*GetR
 		}, nil
 	}
 }
+
+const (
+	// duration of the PoW benchmark which is run before the first estimate
+	hashRateMeasureDuration = 200 * time.Millisecond
+)
+
+var (
+	hashRateOnce sync.Once
+	hashRate     uint64
+)
+
+// getHashRate returns the number of PoW nonces per second checked by this host, measured once
+func getHashRate() uint64 {
+	hashRateOnce.Do(func() {
+		hashRate = pow.MeasureHashRate(hashRateMeasureDuration)
+	})
+	return hashRate
+}
+
+type PlasmaEstimate struct {
+	// BasePlasma is the plasma required by the block, including the cost of the embedded-contract method
+	BasePlasma uint64 `json:"basePlasma"`
+	// AvailablePlasma is the fused plasma which is not used by the unconfirmed blocks of the account
+	AvailablePlasma uint64 `json:"availablePlasma"`
+	// PendingPlasma is the fused plasma used by the unconfirmed blocks of the account
+	PendingPlasma    uint64 `json:"pendingPlasma"`
+	FusionSufficient bool   `json:"fusionSufficient"`
+	// PoWPossible is false if the plasma which is not covered by fusion is above the PoW limit for an account-block
+	PoWPossible        bool   `json:"powPossible"`
+	FusedPlasma        uint64 `json:"fusedPlasma"`
+	RequiredDifficulty uint64 `json:"requiredDifficulty"`
+	// HashRate is the number of nonces per second checked by the node, used for ExpectedPoWTime
+	HashRate uint64 `json:"hashRate"`
+	// ExpectedPoWTime is the expected duration in milliseconds to compute the PoW on the node
+	ExpectedPoWTime uint64 `json:"expectedPoWTime"`
+}
+
+// GetEstimateForAccountBlock returns the plasma required by the unsigned block and how it can be covered by the
+// fused plasma of the account and by PoW. The plasma, difficulty, nonce and signature of the block are ignored.
+func (a *PlasmaApi) GetEstimateForAccountBlock(block *api.AccountBlock) (*PlasmaEstimate, error) {
+	if block == nil {
+		return nil, api.ErrParamIsNull
+	}
+	lb, err := block.ToLedgerBlock()
+	if err != nil {
+		return nil, err
+	}
+	if lb.BlockType != nom.BlockTypeUserSend && lb.BlockType != nom.BlockTypeUserReceive {
+		return nil, errors.New("only user send and user receive blocks can be estimated")
+	}
+
+	frontierMomentum, context, err := api.GetFrontierContext(a.chain, lb.Address)
+	if err != nil {
+		return nil, err
+	}
+	lb.MomentumAcknowledged = frontierMomentum.Identifier()
+
+	basePlasma, err := vm.GetBasePlasmaForAccountBlock(context, lb)
+	if err != nil {
+		return nil, err
+	}
+	availablePlasma, err := vm.AvailablePlasma(context.MomentumStore(), context)
+	if err != nil {
+		return nil, err
+	}
+	committed, err := context.MomentumStore().GetAccountStore(lb.Address).GetChainPlasma()
+	if err != nil {
+		return nil, err
+	}
+	uncommitted, err := context.GetChainPlasma()
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &PlasmaEstimate{
+		BasePlasma:       basePlasma,
+		AvailablePlasma:  availablePlasma,
+		PendingPlasma:    new(big.Int).Sub(uncommitted, committed).Uint64(),
+		FusionSufficient: availablePlasma >= basePlasma,
+		PoWPossible:      true,
+		HashRate:         getHashRate(),
+	}
+	if estimate.FusionSufficient {
+		estimate.FusedPlasma = basePlasma
+		return estimate, nil
+	}
+
+	// the available fused plasma is used together with the PoW
+	estimate.FusedPlasma = availablePlasma
+	difficulty, err := vm.GetDifficultyForPlasma(basePlasma - availablePlasma)
+	if err != nil {
+		estimate.PoWPossible = false
+		return estimate, nil
+	}
+	estimate.RequiredDifficulty = difficulty
+	if estimate.HashRate != 0 {
+		estimate.ExpectedPoWTime = difficulty * 1000 / estimate.HashRate
+	}
+	return estimate, nil
+}
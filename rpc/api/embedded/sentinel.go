@@ -98,6 +98,9 @@ func (api *SentinelApi) GetDepositedQsr(address types.Address) (string, error) {
 func (api *SentinelApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
 	return getUncollectedReward(api.chain, types.SentinelContract, address)
 }
+func (api *SentinelApi) GetUncollectedRewardAt(address types.Address, height uint64) (*definition.RewardDeposit, error) {
+	return getUncollectedRewardAt(api.chain, types.SentinelContract, address, height)
+}
 func (api *SentinelApi) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*RewardHistoryList, error) {
 	if pageSize > rpcapi.RpcMaxPageSize {
 		return nil, rpcapi.ErrPageSizeParamTooBig
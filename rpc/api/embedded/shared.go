@@ -30,6 +30,13 @@ func getUncollectedReward(chain chain.Chain, contract types.Address, address typ
 	}
 	return definition.GetRewardDeposit(context.Storage(), &address)
 }
+func getUncollectedRewardAt(chain chain.Chain, contract types.Address, address types.Address, height uint64) (*definition.RewardDeposit, error) {
+	_, context, err := api.GetContextByHeight(chain, contract, height)
+	if err != nil {
+		return nil, err
+	}
+	return definition.GetRewardDeposit(context.Storage(), &address)
+}
 
 type RewardHistoryEntry struct {
 	Epoch int64    `json:"epoch"`
@@ -13,6 +13,7 @@ import (
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -37,6 +38,9 @@ func NewStakeApi(z zenon.Zenon) *StakeApi {
 func (a *StakeApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
 	return getUncollectedReward(a.chain, types.StakeContract, address)
 }
+func (a *StakeApi) GetUncollectedRewardAt(address types.Address, height uint64) (*definition.RewardDeposit, error) {
+	return getUncollectedRewardAt(a.chain, types.StakeContract, address, height)
+}
 func (a *StakeApi) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*RewardHistoryList, error) {
 	if pageSize > api.RpcMaxPageSize {
 		return nil, api.ErrPageSizeParamTooBig
@@ -147,6 +151,20 @@ func (a *StakeApi) GetEntriesByAddress(address types.Address, pageIndex, pageSiz
 	if err != nil {
 		return nil, err
 	}
+	return a.getEntriesByAddress(context, address, pageIndex, pageSize)
+}
+func (a *StakeApi) GetEntriesByAddressAt(address types.Address, height uint64, pageIndex, pageSize uint32) (*StakeList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetContextByHeight(a.chain, types.StakeContract, height)
+	if err != nil {
+		return nil, err
+	}
+	return a.getEntriesByAddress(context, address, pageIndex, pageSize)
+}
+func (a *StakeApi) getEntriesByAddress(context vm_context.AccountVmContext, address types.Address, pageIndex, pageSize uint32) (*StakeList, error) {
 	list, total, totalWeighted, err := definition.GetStakeListByAddress(context.Storage(), address)
 	if err != nil {
 		return nil, err
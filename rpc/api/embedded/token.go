@@ -1,15 +1,22 @@
 package embedded
 
 import (
+	"encoding/json"
+	"math/big"
+
 	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -87,6 +94,16 @@ func (a *TokenAPI) GetByZts(zts types.ZenonTokenStandard) (*api.Token, error) {
 	if err != nil {
 		return nil, err
 	}
+	return a.getByZts(context, zts)
+}
+func (a *TokenAPI) GetByZtsAt(zts types.ZenonTokenStandard, height uint64) (*api.Token, error) {
+	_, context, err := api.GetContextByHeight(a.chain, types.TokenContract, height)
+	if err != nil {
+		return nil, err
+	}
+	return a.getByZts(context, zts)
+}
+func (a *TokenAPI) getByZts(context vm_context.AccountVmContext, zts types.ZenonTokenStandard) (*api.Token, error) {
 	tokenInfo, err := definition.GetTokenInfo(context.Storage(), zts)
 	if err == constants.ErrDataNonExistent {
 		return nil, nil
@@ -99,3 +116,225 @@ func (a *TokenAPI) GetByZts(zts types.ZenonTokenStandard) (*api.Token, error) {
 	}
 	return nil, nil
 }
+
+type TokenHolder struct {
+	Address types.Address `json:"address"`
+	Balance *big.Int      `json:"balance"`
+}
+type TokenHolderMarshal struct {
+	Address types.Address `json:"address"`
+	Balance string        `json:"balance"`
+}
+
+func (h *TokenHolder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&TokenHolderMarshal{
+		Address: h.Address,
+		Balance: h.Balance.String(),
+	})
+}
+func (h *TokenHolder) UnmarshalJSON(data []byte) error {
+	aux := new(TokenHolderMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	h.Address = aux.Address
+	h.Balance = common.StringToBigInt(aux.Balance)
+	return nil
+}
+
+type TokenHolderList struct {
+	Count         int            `json:"count"`
+	List          []*TokenHolder `json:"list"`
+	IndexedHeight uint64         `json:"indexedHeight"`
+}
+
+// holderSnapshot returns a consistent view of the holder index, once the index is built
+func (a *TokenAPI) holderSnapshot() (*index.HolderSnapshot, error) {
+	holderIndex := a.z.HolderIndex()
+	if holderIndex == nil {
+		return nil, api.ErrHolderIndexDisabled
+	}
+	select {
+	case <-holderIndex.Built():
+	default:
+		if err := holderIndex.Failure(); err != nil {
+			return nil, errors.Errorf("holder index can't be built: %v", err)
+		}
+		return nil, api.ErrHolderIndexNotBuilt
+	}
+	return holderIndex.Snapshot()
+}
+
+// GetHolders returns the accounts which hold zts, including embedded contracts, sorted by balance in descending order.
+// Requires the holder index to be enabled on the node.
+func (a *TokenAPI) GetHolders(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) (*TokenHolderList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	snapshot, err := a.holderSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+
+	indexed, err := snapshot.Frontier()
+	if err != nil {
+		return nil, err
+	}
+	count, err := snapshot.HolderCount(zts)
+	if err != nil {
+		return nil, err
+	}
+	start, end := api.GetRange(pageIndex, pageSize, uint32(count))
+	holders, err := snapshot.Holders(zts, int(start), int(end-start))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*TokenHolder, len(holders))
+	for i, holder := range holders {
+		list[i] = &TokenHolder{Address: holder.Address, Balance: holder.Balance}
+	}
+	return &TokenHolderList{
+		Count:         int(count),
+		List:          list,
+		IndexedHeight: indexed.Height,
+	}, nil
+}
+
+// lockingContracts are the embedded contracts reported separately in the supply breakdown.
+// The balances of the other embedded contracts are reported together.
+var lockingContracts = map[types.Address]string{
+	types.StakeContract:     "stake",
+	types.PillarContract:    "pillar",
+	types.SentinelContract:  "sentinel",
+	types.PlasmaContract:    "plasma",
+	types.LiquidityContract: "liquidity",
+	types.BridgeContract:    "bridge",
+	types.HtlcContract:      "htlc",
+}
+
+const otherEmbeddedContracts = "otherEmbedded"
+
+// TokenSupply splits the total supply of a token into
+//   - Circulating, the sum of the balances of all non-embedded accounts
+//   - Locked, the balances of the embedded contracts, by contract
+//   - Unreceived, the amount of the send-blocks which are not received yet
+type TokenSupply struct {
+	TokenStandard types.ZenonTokenStandard
+	TotalSupply   *big.Int
+	MaxSupply     *big.Int
+	Circulating   *big.Int
+	Locked        map[string]*big.Int
+	Unreceived    *big.Int
+	IndexedHeight uint64
+}
+type TokenSupplyMarshal struct {
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	TotalSupply   string                   `json:"totalSupply"`
+	MaxSupply     string                   `json:"maxSupply"`
+	Circulating   string                   `json:"circulating"`
+	Locked        map[string]string        `json:"locked"`
+	Unreceived    string                   `json:"unreceived"`
+	IndexedHeight uint64                   `json:"indexedHeight"`
+}
+
+func (s *TokenSupply) MarshalJSON() ([]byte, error) {
+	locked := make(map[string]string, len(s.Locked))
+	for name, amount := range s.Locked {
+		locked[name] = amount.String()
+	}
+	return json.Marshal(&TokenSupplyMarshal{
+		TokenStandard: s.TokenStandard,
+		TotalSupply:   s.TotalSupply.String(),
+		MaxSupply:     s.MaxSupply.String(),
+		Circulating:   s.Circulating.String(),
+		Locked:        locked,
+		Unreceived:    s.Unreceived.String(),
+		IndexedHeight: s.IndexedHeight,
+	})
+}
+func (s *TokenSupply) UnmarshalJSON(data []byte) error {
+	aux := new(TokenSupplyMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	s.TokenStandard = aux.TokenStandard
+	s.TotalSupply = common.StringToBigInt(aux.TotalSupply)
+	s.MaxSupply = common.StringToBigInt(aux.MaxSupply)
+	s.Circulating = common.StringToBigInt(aux.Circulating)
+	s.Locked = make(map[string]*big.Int, len(aux.Locked))
+	for name, amount := range aux.Locked {
+		s.Locked[name] = common.StringToBigInt(amount)
+	}
+	s.Unreceived = common.StringToBigInt(aux.Unreceived)
+	s.IndexedHeight = aux.IndexedHeight
+	return nil
+}
+
+// GetSupplyBreakdown returns where the supply of zts sits. Requires the holder index to be enabled on the node.
+func (a *TokenAPI) GetSupplyBreakdown(zts types.ZenonTokenStandard) (*TokenSupply, error) {
+	snapshot, err := a.holderSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+
+	indexed, err := snapshot.Frontier()
+	if err != nil {
+		return nil, err
+	}
+	circulating, err := snapshot.Circulating(zts)
+	if err != nil {
+		return nil, err
+	}
+	// use the state the index was built from, so the amounts add up to the total supply
+	momentumStore := a.chain.GetMomentumStore(*indexed)
+	if momentumStore == nil {
+		return nil, api.ErrStateNotAvailable
+	}
+	tokenInfo, err := momentumStore.GetTokenInfoByTs(zts)
+	if err == constants.ErrDataNonExistent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locked, totalLocked, err := getLockedSupply(momentumStore, zts)
+	if err != nil {
+		return nil, err
+	}
+	unreceived := new(big.Int).Sub(tokenInfo.TotalSupply, circulating)
+	return &TokenSupply{
+		TokenStandard: zts,
+		TotalSupply:   tokenInfo.TotalSupply,
+		MaxSupply:     tokenInfo.MaxSupply,
+		Circulating:   circulating,
+		Locked:        locked,
+		Unreceived:    unreceived.Sub(unreceived, totalLocked),
+		IndexedHeight: indexed.Height,
+	}, nil
+}
+
+// getLockedSupply returns the balances of zts held by the embedded contracts, named as in lockingContracts, and their sum
+func getLockedSupply(momentumStore store.Momentum, zts types.ZenonTokenStandard) (map[string]*big.Int, *big.Int, error) {
+	locked := map[string]*big.Int{otherEmbeddedContracts: big.NewInt(0)}
+	for _, name := range lockingContracts {
+		locked[name] = big.NewInt(0)
+	}
+	total := big.NewInt(0)
+	for _, contract := range types.EmbeddedContracts {
+		balance, err := momentumStore.GetAccountStore(contract).GetBalance(zts)
+		if err != nil {
+			return nil, nil, err
+		}
+		name, ok := lockingContracts[contract]
+		if !ok {
+			name = otherEmbeddedContracts
+		}
+		locked[name].Add(locked[name], balance)
+		total.Add(total, balance)
+	}
+	return locked, total, nil
+}
@@ -9,5 +9,15 @@ var (
 	ErrPageIndexParamTooBig = common.NewErrorWCode(-32000, "page-index parameter is too big")
 	ErrCountParamTooBig     = common.NewErrorWCode(-32000, "count parameter is too big")
 	ErrHeightParamIsZero    = common.NewErrorWCode(-32000, "height parameter must be strictly greater than zero")
+	ErrCountParamIsZero     = common.NewErrorWCode(-32000, "count parameter must be strictly greater than zero")
 	ErrParamIsNull          = common.NewErrorWCode(-32000, "parameter must not be null")
+	ErrMomentumNotFound     = common.NewErrorWCode(-32000, "momentum at the requested height does not exist")
+	ErrStateNotAvailable    = common.NewErrorWCode(-32000, "state at the requested momentum is no longer retained by this node")
+	ErrAddressIndexDisabled = common.NewErrorWCode(-32000, "address index is not enabled on this node")
+	ErrHolderIndexDisabled  = common.NewErrorWCode(-32000, "holder index is not enabled on this node")
+	ErrHolderIndexNotBuilt  = common.NewErrorWCode(-32000, "holder index is not built yet")
+	ErrHtlcIndexDisabled    = common.NewErrorWCode(-32000, "htlc index is not enabled on this node")
+	ErrDataPruned           = common.NewErrorWCode(-32000, "data was pruned by this node")
+
+	ErrInvalidUnreceivedCursor = common.NewErrorWCode(-32000, "invalid unreceived-blocks cursor")
 )
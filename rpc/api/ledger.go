@@ -1,14 +1,18 @@
 package api
 
 import (
+	"math/big"
 	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/zenon"
@@ -28,6 +32,10 @@ type LedgerApi struct {
 	z     zenon.Zenon
 	chain chain.Chain
 	log   log15.Logger
+
+	// DecodeBlockData adds the decoded calls to embedded contracts to the returned account-blocks
+	// when the call doesn't pass its own decode option
+	DecodeBlockData bool
 }
 
 const (
@@ -73,15 +81,55 @@ func (l *LedgerApi) PublishRawTransaction(block *AccountBlock) error {
 	return nil
 }
 
+// SimulateTransaction applies the block, and the contract receive for sends to embedded contracts, on top of the
+// frontier and returns the outcome. The changes are discarded and the block doesn't need to be signed.
+func (l *LedgerApi) SimulateTransaction(block *AccountBlock) (*TransactionSimulation, error) {
+	defer common.RecoverStack()
+	if block == nil {
+		return nil, ErrParamIsNull
+	}
+
+	if block.ChainIdentifier != 0 && block.ChainIdentifier != l.chain.ChainIdentifier() {
+		return nil, errors.Errorf("the block has a different network Id (%d) from the node (%d)", block.ChainIdentifier, l.chain.ChainIdentifier())
+	}
+
+	lb, err := block.ToLedgerBlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenIdValid(l.chain, &lb.TokenStandard); err != nil {
+		return nil, err
+	}
+
+	supervisor := vm.NewSupervisor(l.z.Chain(), l.z.Consensus())
+	simulation, err := supervisor.SimulateBlock(lb)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransactionSimulation{
+		Block:           simulation.Block,
+		ContractReceive: simulation.ContractReceive,
+		BalanceChanges:  simulation.BalanceChanges,
+	}
+	if simulation.BlockError != nil {
+		result.Error = simulation.BlockError.Error()
+	}
+	if simulation.ContractError != nil {
+		result.ContractError = simulation.ContractError.Error()
+	}
+	return result, nil
+}
+
 // Unconfirmed AccountBlocks
-func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageIndex, pageSize uint32, decode *bool) (*AccountBlockList, error) {
 	if pageSize > RpcMaxPageSize {
 		return nil, ErrPageSizeParamTooBig
 	}
 
 	unreceived := l.chain.GetUncommittedAccountBlocksByAddress(address)
 	start, end := GetRange(pageIndex, pageSize, uint32(len(unreceived)))
-	a, err := ledgerAccountBlocksToRpc(l.chain, unreceived[start:end])
+	a, err := l.accountBlocksToRpc(unreceived[start:end], decode)
 
 	if err != nil {
 		return nil, err
@@ -95,7 +143,8 @@ func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageInd
 }
 
 // AccountBlocks
-func (l *LedgerApi) GetFrontierAccountBlock(address types.Address) (*AccountBlock, error) {
+// The methods returning account-blocks take an optional last decode parameter, which overrides DecodeBlockData for the call.
+func (l *LedgerApi) GetFrontierAccountBlock(address types.Address, decode *bool) (*AccountBlock, error) {
 	accountStore := l.chain.GetFrontierAccountStore(address)
 	block, err := accountStore.Frontier()
 	if err != nil {
@@ -104,11 +153,14 @@ func (l *LedgerApi) GetFrontierAccountBlock(address types.Address) (*AccountBloc
 	if block == nil {
 		return nil, nil
 	}
-	return ledgerAccountBlockToRpc(l.chain, block)
+	return l.accountBlockToRpc(block, decode)
 }
-func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash) (*AccountBlock, error) {
+func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash, decode *bool) (*AccountBlock, error) {
 	momentumStore := l.chain.GetFrontierMomentumStore()
 	block, err := momentumStore.GetAccountBlockByHash(blockHash)
+	if err == db.ErrPruned {
+		return nil, ErrDataPruned
+	}
 	if err != nil {
 		l.log.Error("GetAccountBlockByHash failed", "reason", err, "method-called", "momentumStore.GetAccountBlockByHash")
 		return nil, err
@@ -117,9 +169,9 @@ func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash) (*AccountBlock,
 		return nil, nil
 	}
 
-	return ledgerAccountBlockToRpc(l.chain, block)
+	return l.accountBlockToRpc(block, decode)
 }
-func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, count uint64) (*AccountBlockList, error) {
+func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, count uint64, decode *bool) (*AccountBlockList, error) {
 	if height == 0 {
 		return nil, ErrHeightParamIsZero
 	}
@@ -146,7 +198,7 @@ func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, coun
 		return nil, err
 	}
 
-	list, err := ledgerAccountBlocksToRpc(l.chain, accountBlocks)
+	list, err := l.accountBlocksToRpc(accountBlocks, decode)
 	if err != nil {
 		l.log.Error("GetAccountBlocksByHeight failed", "reason", err, "method-called", "ledgerAccountBlocksToRpc")
 		return nil, err
@@ -157,7 +209,7 @@ func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, coun
 		Count: int(frontier.Height),
 	}, nil
 }
-func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pageSize uint32, decode *bool) (*AccountBlockList, error) {
 	if pageSize > RpcMaxPageSize {
 		return nil, ErrPageSizeParamTooBig
 	}
@@ -190,7 +242,7 @@ func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pag
 		}, nil
 	}
 
-	ans, err := l.GetAccountBlocksByHeight(address, uint64(startHeight), uint64(count))
+	ans, err := l.GetAccountBlocksByHeight(address, uint64(startHeight), uint64(count), decode)
 	if err != nil {
 		return nil, err
 	}
@@ -200,11 +252,79 @@ func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pag
 	}
 	return ans, nil
 }
+
+// GetIndexedAccountBlocksByAddress returns the account-blocks published by the address or sent to it, in ascending order
+// of the confirmation momentum. Requires the address index to be enabled on the node.
+// Pass the returned cursor to get the next page; an empty cursor means there are no more blocks.
+func (l *LedgerApi) GetIndexedAccountBlocksByAddress(address types.Address, filter *index.Filter, cursor string, count uint64, decode *bool) (*IndexedAccountBlockList, error) {
+	addressIndex := l.z.AddressIndex()
+	if addressIndex == nil {
+		return nil, ErrAddressIndexDisabled
+	}
+	if count > RpcMaxCountSize {
+		return nil, ErrCountParamTooBig
+	}
+	if count == 0 {
+		return nil, ErrCountParamIsZero
+	}
+	// the index would otherwise silently answer from the momentums indexed before the failure
+	if err := addressIndex.Failure(); err != nil {
+		return nil, errors.Errorf("address index can't catch up with the chain: %v", err)
+	}
+
+	indexed, err := addressIndex.Frontier()
+	if err != nil {
+		return nil, err
+	}
+	entries, next, err := addressIndex.Query(address, filter, cursor, int(count))
+	if err != nil {
+		return nil, err
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	list := make([]*AccountBlock, 0, len(entries))
+	for _, entry := range entries {
+		block, err := momentumStore.GetAccountBlockByHash(entry.BlockHash)
+		if err != nil {
+			l.log.Error("GetIndexedAccountBlocksByAddress failed", "reason", err, "method-called", "GetAccountBlockByHash")
+			return nil, err
+		}
+		// the index can be ahead of the store for a moment while a momentum is rollbacked
+		if block == nil {
+			continue
+		}
+		rpcBlock, err := l.accountBlockToRpc(block, decode)
+		if err != nil {
+			l.log.Error("GetIndexedAccountBlocksByAddress failed", "reason", err, "method-called", "ledgerAccountBlockToRpc")
+			return nil, err
+		}
+		list = append(list, rpcBlock)
+	}
+
+	return &IndexedAccountBlockList{
+		List:          list,
+		Cursor:        next,
+		IndexedHeight: indexed.Height,
+	}, nil
+}
+
 func (l *LedgerApi) GetAccountInfoByAddress(address types.Address) (*AccountInfo, error) {
 	l.log.Info("GetAccountInfoByAddress")
 
 	momentumStore := l.chain.GetFrontierMomentumStore()
 	accountStore := l.chain.GetFrontierAccountStore(address)
+	return l.getAccountInfo(momentumStore, accountStore, address)
+}
+func (l *LedgerApi) GetAccountInfoByAddressAt(address types.Address, height uint64) (*AccountInfo, error) {
+	l.log.Info("GetAccountInfoByAddressAt", "address", address, "height", height)
+
+	momentumStore, err := GetMomentumStoreByHeight(l.chain, height)
+	if err != nil {
+		return nil, err
+	}
+	return l.getAccountInfo(momentumStore, momentumStore.GetAccountStore(address), address)
+}
+func (l *LedgerApi) getAccountInfo(momentumStore store.Momentum, accountStore store.Account, address types.Address) (*AccountInfo, error) {
 	frontierAccountBlock, err := accountStore.Frontier()
 	if err != nil {
 		l.log.Error("GetFrontierAccountBlock failed, error is "+err.Error(), "method", "GetAccountInfoByAddress")
@@ -242,7 +362,7 @@ func (l *LedgerApi) GetAccountInfoByAddress(address types.Address) (*AccountInfo
 		BalanceInfoMap: balanceInfoMap,
 	}, nil
 }
-func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageIndex, pageSize uint32, decode *bool) (*AccountBlockList, error) {
 	l.log.Info("GetUnreceivedBlocksByAddress", "address", address, "page", pageIndex, "size", pageSize)
 	if pageSize > unreceivedMaxPageSize {
 		return nil, ErrPageSizeParamTooBig
@@ -278,7 +398,7 @@ func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageInde
 	}
 
 	start, end := GetRange(pageIndex, pageSize, uint32(len(blockList)))
-	a, err := ledgerAccountBlocksToRpc(l.chain, blockList[start:end])
+	a, err := l.accountBlocksToRpc(blockList[start:end], decode)
 
 	if err != nil {
 		return nil, err
@@ -291,6 +411,117 @@ func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageInde
 	}, nil
 }
 
+// GetAllUnreceivedBlocksByAddress pages through every block which was sent to the address and was not received yet,
+// in ascending order of the hash. Pass the returned cursor to get the next page; an empty cursor means there are no more blocks.
+// Blocks received by the account but not confirmed by a momentum yet are skipped, so a page can contain less than count blocks.
+func (l *LedgerApi) GetAllUnreceivedBlocksByAddress(address types.Address, cursor string, count uint64, decode *bool) (*UnreceivedAccountBlockList, error) {
+	l.log.Info("GetAllUnreceivedBlocksByAddress", "address", address, "cursor", cursor, "count", count)
+	if count > RpcMaxCountSize {
+		return nil, ErrCountParamTooBig
+	}
+	if count == 0 {
+		return nil, ErrCountParamIsZero
+	}
+
+	var after *types.Hash
+	if cursor != "" {
+		hash, err := types.HexToHash(cursor)
+		if err != nil {
+			return nil, ErrInvalidUnreceivedCursor
+		}
+		after = &hash
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	accountStore := l.chain.GetFrontierAccountStore(address)
+	hashList, err := momentumStore.GetAccountMailbox(address).GetUnreceivedAccountBlockHashesAfter(after, count)
+	if err != nil {
+		return nil, err
+	}
+
+	blockList := make([]*nom.AccountBlock, 0, len(hashList))
+	for _, hash := range hashList {
+		if accountStore.IsReceived(hash) {
+			continue
+		}
+		block, err := momentumStore.GetAccountBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, errors.Errorf("can't find unreceived block %v", hash)
+		}
+		blockList = append(blockList, block)
+	}
+
+	list, err := l.accountBlocksToRpc(blockList, decode)
+	if err != nil {
+		return nil, err
+	}
+	next := ""
+	if uint64(len(hashList)) == count {
+		next = hashList[len(hashList)-1].String()
+	}
+	return &UnreceivedAccountBlockList{
+		List:   list,
+		Cursor: next,
+	}, nil
+}
+
+// GetUnreceivedSummaryByAddress returns the number of blocks which were sent to the address and were not received yet,
+// together with the number of blocks and the pending amount for each token.
+func (l *LedgerApi) GetUnreceivedSummaryByAddress(address types.Address) (*UnreceivedSummary, error) {
+	l.log.Info("GetUnreceivedSummaryByAddress", "address", address)
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	accountStore := l.chain.GetFrontierAccountStore(address)
+	mailbox := momentumStore.GetAccountMailbox(address)
+
+	summary := &UnreceivedSummary{
+		Totals: make(map[types.ZenonTokenStandard]*UnreceivedTokenTotal),
+	}
+	var after *types.Hash
+	for {
+		hashList, err := mailbox.GetUnreceivedAccountBlockHashesAfter(after, unreceivedQuerySize)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range hashList {
+			if accountStore.IsReceived(hash) {
+				continue
+			}
+			block, err := momentumStore.GetAccountBlockByHash(hash)
+			if err != nil {
+				return nil, err
+			}
+			if block == nil {
+				return nil, errors.Errorf("can't find unreceived block %v", hash)
+			}
+
+			total, ok := summary.Totals[block.TokenStandard]
+			if !ok {
+				tokenInfo, err := momentumStore.GetTokenInfoByTs(block.TokenStandard)
+				if err != nil {
+					return nil, err
+				}
+				total = &UnreceivedTokenTotal{
+					TokenInfo: LedgerTokenInfoToRpc(tokenInfo),
+					Amount:    big.NewInt(0),
+				}
+				summary.Totals[block.TokenStandard] = total
+			}
+			total.Count += 1
+			total.Amount.Add(total.Amount, block.Amount)
+			summary.Count += 1
+		}
+
+		if len(hashList) < unreceivedQuerySize {
+			return summary, nil
+		}
+		after = &hashList[len(hashList)-1]
+	}
+}
+
 // Momentum
 func (l *LedgerApi) GetFrontierMomentum() (*Momentum, error) {
 	momentum, err := l.chain.GetFrontierMomentumStore().GetFrontierMomentum()
@@ -384,7 +615,7 @@ func (l *LedgerApi) GetMomentumsByPage(pageIndex, pageSize uint32) (*MomentumLis
 	}
 	return ans, nil
 }
-func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64) (*DetailedMomentumList, error) {
+func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64, decode *bool) (*DetailedMomentumList, error) {
 	l.log.Info("GetDetailedMomentumsByHeight", "height", height, "count", count)
 	if count > RpcMaxCountSize {
 		return nil, ErrCountParamTooBig
@@ -394,5 +625,47 @@ func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64) (*Detaile
 	if err != nil {
 		return nil, err
 	}
-	return momentumListToDetailedList(l.chain, ans)
+	detailed, err := momentumListToDetailedList(l.chain, ans)
+	if err != nil {
+		return nil, err
+	}
+	if l.decodeData(decode) {
+		for _, momentum := range detailed.List {
+			for _, block := range momentum.AccountBlocks {
+				block.addDecodedData()
+			}
+		}
+	}
+	return detailed, nil
+}
+
+// decodeData returns whether the calls to embedded contracts are decoded for a call which passed decode,
+// which defaults to the DecodeBlockData of the node when missing
+func (l *LedgerApi) decodeData(decode *bool) bool {
+	if decode == nil {
+		return l.DecodeBlockData
+	}
+	return *decode
+}
+func (l *LedgerApi) accountBlockToRpc(block *nom.AccountBlock, decode *bool) (*AccountBlock, error) {
+	rpcBlock, err := ledgerAccountBlockToRpc(l.chain, block)
+	if err != nil {
+		return nil, err
+	}
+	if l.decodeData(decode) {
+		rpcBlock.addDecodedData()
+	}
+	return rpcBlock, nil
+}
+func (l *LedgerApi) accountBlocksToRpc(list []*nom.AccountBlock, decode *bool) ([]*AccountBlock, error) {
+	blocks, err := ledgerAccountBlocksToRpc(l.chain, list)
+	if err != nil {
+		return nil, err
+	}
+	if l.decodeData(decode) {
+		for _, block := range blocks {
+			block.addDecodedData()
+		}
+	}
+	return blocks, nil
 }
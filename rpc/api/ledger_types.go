@@ -7,7 +7,9 @@ import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
@@ -36,6 +38,11 @@ type AccountBlock struct {
 	TokenInfo          *Token                          `json:"token"`
 	ConfirmationDetail *AccountBlockConfirmationDetail `json:"confirmationDetail"`
 	PairedAccountBlock *AccountBlock                   `json:"pairedAccountBlock"`
+
+	// DecodedData is the call made to an embedded contract by the block, if any.
+	// DecodedDescendants holds the calls made by the descendant blocks, in the same order.
+	DecodedData        *embedded.DecodedCall   `json:"decodedData,omitempty"`
+	DecodedDescendants []*embedded.DecodedCall `json:"decodedDescendants,omitempty"`
 }
 
 type AccountBlockMarshal struct {
@@ -43,12 +50,16 @@ type AccountBlockMarshal struct {
 	TokenInfo          *TokenMarshal                   `json:"token"`
 	ConfirmationDetail *AccountBlockConfirmationDetail `json:"confirmationDetail"`
 	PairedAccountBlock *AccountBlockMarshal            `json:"pairedAccountBlock"`
+	DecodedData        *embedded.DecodedCall           `json:"decodedData,omitempty"`
+	DecodedDescendants []*embedded.DecodedCall         `json:"decodedDescendants,omitempty"`
 }
 
 func (block *AccountBlock) ToAccountBlockMarshal() *AccountBlockMarshal {
 	aux := &AccountBlockMarshal{
 		AccountBlockMarshal: *block.AccountBlock.ToNomMarshalJson(),
 		ConfirmationDetail:  block.ConfirmationDetail,
+		DecodedData:         block.DecodedData,
+		DecodedDescendants:  block.DecodedDescendants,
 	}
 	if block.TokenInfo != nil {
 		aux.TokenInfo = block.TokenInfo.ToTokenMarshal()
@@ -77,12 +88,16 @@ func (block *AccountBlock) UnmarshalJSON(data []byte) error {
 	if aux.PairedAccountBlock != nil {
 		block.PairedAccountBlock = aux.PairedAccountBlock.FromApiMarshalJson()
 	}
+	block.DecodedData = aux.DecodedData
+	block.DecodedDescendants = aux.DecodedDescendants
 	return nil
 }
 
 func (a *AccountBlockMarshal) FromApiMarshalJson() *AccountBlock {
 	aux := &AccountBlock{
 		ConfirmationDetail: a.ConfirmationDetail,
+		DecodedData:        a.DecodedData,
+		DecodedDescendants: a.DecodedDescendants,
 	}
 	block := a.FromNomMarshalJson()
 	aux.AccountBlock = *block
@@ -265,6 +280,112 @@ func (abl *AccountBlockList) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+type IndexedAccountBlockList struct {
+	List          []*AccountBlock `json:"list"`
+	Cursor        string          `json:"cursor"`
+	IndexedHeight uint64          `json:"indexedHeight"`
+}
+
+type IndexedAccountBlockListMarshal struct {
+	List          []*AccountBlockMarshal `json:"list"`
+	Cursor        string                 `json:"cursor"`
+	IndexedHeight uint64                 `json:"indexedHeight"`
+}
+
+func (iabl *IndexedAccountBlockList) MarshalJSON() ([]byte, error) {
+	list := &AccountBlockList{List: iabl.List}
+	return json.Marshal(&IndexedAccountBlockListMarshal{
+		List:          list.ToAccountBlockListMarshal().List,
+		Cursor:        iabl.Cursor,
+		IndexedHeight: iabl.IndexedHeight,
+	})
+}
+func (iabl *IndexedAccountBlockList) UnmarshalJSON(data []byte) error {
+	aux := new(IndexedAccountBlockListMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	iabl.List = make([]*AccountBlock, 0, len(aux.List))
+	for _, accBl := range aux.List {
+		iabl.List = append(iabl.List, accBl.FromApiMarshalJson())
+	}
+	iabl.Cursor = aux.Cursor
+	iabl.IndexedHeight = aux.IndexedHeight
+	return nil
+}
+
+type UnreceivedAccountBlockList struct {
+	List   []*AccountBlock `json:"list"`
+	Cursor string          `json:"cursor"`
+}
+
+type UnreceivedAccountBlockListMarshal struct {
+	List   []*AccountBlockMarshal `json:"list"`
+	Cursor string                 `json:"cursor"`
+}
+
+func (uabl *UnreceivedAccountBlockList) MarshalJSON() ([]byte, error) {
+	list := &AccountBlockList{List: uabl.List}
+	return json.Marshal(&UnreceivedAccountBlockListMarshal{
+		List:   list.ToAccountBlockListMarshal().List,
+		Cursor: uabl.Cursor,
+	})
+}
+func (uabl *UnreceivedAccountBlockList) UnmarshalJSON(data []byte) error {
+	aux := new(UnreceivedAccountBlockListMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	uabl.List = make([]*AccountBlock, 0, len(aux.List))
+	for _, accBl := range aux.List {
+		uabl.List = append(uabl.List, accBl.FromApiMarshalJson())
+	}
+	uabl.Cursor = aux.Cursor
+	return nil
+}
+
+type UnreceivedSummary struct {
+	Count  int                                                `json:"count"`
+	Totals map[types.ZenonTokenStandard]*UnreceivedTokenTotal `json:"totals"`
+}
+type UnreceivedTokenTotal struct {
+	TokenInfo *Token   `json:"token"`
+	Count     int      `json:"count"`
+	Amount    *big.Int `json:"amount"`
+}
+
+type UnreceivedTokenTotalMarshal struct {
+	TokenInfo *TokenMarshal `json:"token"`
+	Count     int           `json:"count"`
+	Amount    string        `json:"amount"`
+}
+
+func (t *UnreceivedTokenTotal) MarshalJSON() ([]byte, error) {
+	aux := &UnreceivedTokenTotalMarshal{
+		Count:  t.Count,
+		Amount: t.Amount.String(),
+	}
+	if t.TokenInfo != nil {
+		aux.TokenInfo = t.TokenInfo.ToTokenMarshal()
+	}
+	return json.Marshal(aux)
+}
+func (t *UnreceivedTokenTotal) UnmarshalJSON(data []byte) error {
+	aux := new(UnreceivedTokenTotalMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	if aux.TokenInfo != nil {
+		t.TokenInfo = aux.TokenInfo.FromTokenMarshal()
+	}
+	t.Count = aux.Count
+	t.Amount = common.StringToBigInt(aux.Amount)
+	return nil
+}
+
 type MomentumList struct {
 	List  []*Momentum `json:"list"`
 	Count int         `json:"count"`
@@ -274,6 +395,61 @@ type DetailedMomentumList struct {
 	Count int                 `json:"count"`
 }
 
+type TransactionSimulation struct {
+	Block           *nom.AccountBlock                                       `json:"block"`
+	ContractReceive *nom.AccountBlock                                       `json:"contractReceive"`
+	BalanceChanges  map[types.Address]map[types.ZenonTokenStandard]*big.Int `json:"balanceChanges"`
+	Error           string                                                  `json:"error"`
+	ContractError   string                                                  `json:"contractError"`
+}
+
+type TransactionSimulationMarshal struct {
+	Block           *nom.AccountBlock                                     `json:"block"`
+	ContractReceive *nom.AccountBlock                                     `json:"contractReceive"`
+	BalanceChanges  map[types.Address]map[types.ZenonTokenStandard]string `json:"balanceChanges"`
+	Error           string                                                `json:"error"`
+	ContractError   string                                                `json:"contractError"`
+}
+
+func (t *TransactionSimulation) ToTransactionSimulationMarshal() *TransactionSimulationMarshal {
+	aux := &TransactionSimulationMarshal{
+		Block:           t.Block,
+		ContractReceive: t.ContractReceive,
+		BalanceChanges:  make(map[types.Address]map[types.ZenonTokenStandard]string, len(t.BalanceChanges)),
+		Error:           t.Error,
+		ContractError:   t.ContractError,
+	}
+	for address, changes := range t.BalanceChanges {
+		aux.BalanceChanges[address] = make(map[types.ZenonTokenStandard]string, len(changes))
+		for zts, delta := range changes {
+			aux.BalanceChanges[address][zts] = delta.String()
+		}
+	}
+	return aux
+}
+func (t *TransactionSimulation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.ToTransactionSimulationMarshal())
+}
+func (t *TransactionSimulation) UnmarshalJSON(data []byte) error {
+	aux := new(TransactionSimulationMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	t.Block = aux.Block
+	t.ContractReceive = aux.ContractReceive
+	t.BalanceChanges = make(map[types.Address]map[types.ZenonTokenStandard]*big.Int, len(aux.BalanceChanges))
+	for address, changes := range aux.BalanceChanges {
+		t.BalanceChanges[address] = make(map[types.ZenonTokenStandard]*big.Int, len(changes))
+		for zts, delta := range changes {
+			t.BalanceChanges[address][zts] = common.StringToBigInt(delta)
+		}
+	}
+	t.Error = aux.Error
+	t.ContractError = aux.ContractError
+	return nil
+}
+
 func (block *AccountBlock) ToLedgerBlock() (*nom.AccountBlock, error) {
 	return block.AccountBlock.Copy(), nil
 }
@@ -324,6 +500,10 @@ func (block *AccountBlock) prefetchPaired(chain chain.Chain) error {
 	} else {
 		paired, err = store.GetAccountBlockByHash(block.FromBlockHash)
 	}
+	if err == db.ErrPruned {
+		// the paired block is no longer retained by this node
+		paired, err = nil, nil
+	}
 	if err != nil {
 		return err
 	}
@@ -377,6 +557,31 @@ func (block *AccountBlock) addAllExtraInfo(chain chain.Chain) error {
 	return nil
 }
 
+// addDecodedData decodes the calls made to embedded contracts by the block, by its descendant blocks and by the paired block.
+// Data which isn't a valid call of an embedded contract is left undecoded.
+func (block *AccountBlock) addDecodedData() {
+	block.DecodedData = decodeEmbeddedCall(&block.AccountBlock)
+	if len(block.DescendantBlocks) != 0 {
+		block.DecodedDescendants = make([]*embedded.DecodedCall, len(block.DescendantBlocks))
+		for index, descendant := range block.DescendantBlocks {
+			block.DecodedDescendants[index] = decodeEmbeddedCall(descendant)
+		}
+	}
+	if block.PairedAccountBlock != nil {
+		block.PairedAccountBlock.addDecodedData()
+	}
+}
+func decodeEmbeddedCall(block *nom.AccountBlock) *embedded.DecodedCall {
+	if !block.IsSendBlock() || !types.IsEmbeddedAddress(block.ToAddress) || len(block.Data) == 0 {
+		return nil
+	}
+	call, err := embedded.DecodeEmbeddedCallJSON(block.ToAddress, block.Data)
+	if err != nil {
+		return nil
+	}
+	return call
+}
+
 func momentumListToDetailedList(chain chain.Chain, list *MomentumList) (*DetailedMomentumList, error) {
 	ans := &DetailedMomentumList{
 		Count: list.Count,
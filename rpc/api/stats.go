@@ -9,9 +9,12 @@ import (
 	"github.com/shirou/gopsutil/mem"
 
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	consensusApi "github.com/zenon-network/go-zenon/consensus/api"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
 	"github.com/zenon-network/go-zenon/p2p/discover"
+	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -125,3 +128,94 @@ func (api *StatsApi) NetworkInfo() (*NetworkInfoResponse, error) {
 func (api *StatsApi) SyncInfo() (*protocol.SyncInfo, error) {
 	return api.z.Broadcaster().SyncInfo(), nil
 }
+
+type MissedMomentum struct {
+	Timestamp int64         `json:"timestamp"`
+	Producer  types.Address `json:"producer"`
+	// Local is the outcome of the slot on this node, only present if the pillar is producing on this node
+	Local *pillar.SlotReport `json:"local"`
+}
+type PillarProductionResponse struct {
+	Epoch       uint64            `json:"epoch"`
+	Name        string            `json:"name"`
+	ExpectedNum uint64            `json:"expectedNum"`
+	ProducedNum uint64            `json:"producedNum"`
+	Missed      []*MissedMomentum `json:"missed"`
+}
+
+// PillarProduction returns the number of momentums the pillar was expected to produce in the epoch, the number
+// of produced ones and the exact slots which were missed. If the pillar is producing on this node, the missed slots
+// contain the reason for which this node didn't produce the momentum. Returns nil for epochs which didn't start yet.
+func (api *StatsApi) PillarProduction(pillarName string, epoch uint64) (*PillarProductionResponse, error) {
+	production, err := api.z.Consensus().EpochProduction(epoch, pillarName)
+	if err != nil {
+		return nil, err
+	}
+	if production == nil {
+		return nil, nil
+	}
+
+	result := &PillarProductionResponse{
+		Epoch:       production.Epoch,
+		Name:        production.Name,
+		ExpectedNum: production.ExpectedNum,
+		ProducedNum: production.ProducedNum,
+		Missed:      make([]*MissedMomentum, len(production.Missed)),
+	}
+	for i, slot := range production.Missed {
+		result.Missed[i] = &MissedMomentum{
+			Timestamp: slot.Timestamp,
+			Producer:  slot.Producer,
+			Local:     api.localSlotReport(slot),
+		}
+	}
+	return result, nil
+}
+func (api *StatsApi) localSlotReport(slot *consensusApi.MissedSlot) *pillar.SlotReport {
+	producer := api.z.Producer()
+	if producer == nil {
+		return nil
+	}
+	if coinbase := producer.GetCoinBase(); coinbase == nil || *coinbase != slot.Producer {
+		return nil
+	}
+
+	report := producer.GetSlotReport(slot.Timestamp)
+	if report == nil {
+		return &pillar.SlotReport{
+			Timestamp: slot.Timestamp,
+			Producer:  slot.Producer,
+			Outcome:   pillar.SlotNotProcessed,
+			Reason:    "no record of the slot, the node was probably offline",
+		}
+	}
+	if report.Outcome == pillar.SlotProduced {
+		notIncluded := *report
+		notIncluded.Outcome = pillar.SlotNotIncluded
+		notIncluded.Reason = "the momentum was broadcasted but it's not part of the chain"
+		return &notIncluded
+	}
+	return report
+}
+
+// ProducerSchedule returns the momentum production schedule of the current and of the next epoch, relative to the
+// frontier momentum. Only the slots of pillarName are returned, unless it's empty. Slots which are not final can still
+// change, since the delegations on which their election is based are not part of the chain yet.
+func (api *StatsApi) ProducerSchedule(pillarName string) ([]*consensusApi.ProducerSchedule, error) {
+	frontier, err := api.z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	cs := api.z.Consensus()
+	current := cs.FrontierPillarReader().EpochTicker().ToTick(*frontier.Timestamp)
+
+	result := make([]*consensusApi.ProducerSchedule, 0, 2)
+	for _, epoch := range []uint64{current, current + 1} {
+		schedule, err := cs.ProducerSchedule(epoch, pillarName)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, schedule)
+	}
+	return result, nil
+}
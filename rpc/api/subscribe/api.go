@@ -3,6 +3,7 @@ package subscribe
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/inconshreveable/log15"
 
@@ -11,11 +12,14 @@ import (
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded"
 )
 
 const (
 	acChanSize    = 100
 	mChanSize     = 100
+	eChanSize     = 100
 	installSize   = 100
 	uninstallSize = 100
 )
@@ -30,34 +34,66 @@ type Momentum struct {
 	Height uint64     `json:"height"`
 }
 type AccountBlock struct {
-	BlockType uint64        `json:"blockType"`
-	Hash      types.Hash    `json:"hash"`
-	Height    uint64        `json:"height"`
-	Address   types.Address `json:"address"`
-	ToAddress types.Address `json:"toAddress"`
-	FromHash  types.Hash    `json:"fromHash"`
+	BlockType      uint64        `json:"blockType"`
+	Hash           types.Hash    `json:"hash"`
+	Height         uint64        `json:"height"`
+	Address        types.Address `json:"address"`
+	ToAddress      types.Address `json:"toAddress"`
+	FromHash       types.Hash    `json:"fromHash"`
+	MomentumHeight uint64        `json:"momentumHeight"`
 }
 
-func newAccountBlock(block *nom.AccountBlock) []*AccountBlock {
+func newAccountBlock(block *nom.AccountBlock, momentumHeight uint64) []*AccountBlock {
 	all := make([]*AccountBlock, 1, len(block.DescendantBlocks)+1)
 	all[0] = &AccountBlock{
-		BlockType: block.BlockType,
-		Hash:      block.Hash,
-		Height:    block.Height,
-		Address:   block.Address,
-		ToAddress: block.ToAddress,
-		FromHash:  block.FromBlockHash,
+		BlockType:      block.BlockType,
+		Hash:           block.Hash,
+		Height:         block.Height,
+		Address:        block.Address,
+		ToAddress:      block.ToAddress,
+		FromHash:       block.FromBlockHash,
+		MomentumHeight: momentumHeight,
 	}
 	for _, dBlock := range block.DescendantBlocks {
-		all = append(all, newAccountBlock(dBlock)...)
+		all = append(all, newAccountBlock(dBlock, momentumHeight)...)
 	}
 	return all
 }
 
+// EmbeddedEvent is a successful call of an embedded contract method, decoded from the contract-receive block. Args
+// are encoded like the decoded data of the ledger API, see embedded.DecodeEmbeddedCallJSON
+type EmbeddedEvent struct {
+	Contract         types.Address          `json:"contract"`
+	Method           string                 `json:"method"`
+	Args             map[string]interface{} `json:"args"`
+	Sender           types.Address          `json:"sender"`
+	SendBlockHash    types.Hash             `json:"sendBlockHash"`
+	ReceiveBlockHash types.Hash             `json:"receiveBlockHash"`
+	MomentumHeight   uint64                 `json:"momentumHeight"`
+}
+
+func newEmbeddedEvent(sendBlock, receiveBlock *nom.AccountBlock, momentumHeight uint64) (*EmbeddedEvent, error) {
+	call, err := embedded.DecodeEmbeddedCallJSON(sendBlock.ToAddress, sendBlock.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddedEvent{
+		Contract:         receiveBlock.Address,
+		Method:           call.Method,
+		Args:             call.Args,
+		Sender:           sendBlock.Address,
+		SendBlockHash:    sendBlock.Hash,
+		ReceiveBlockHash: receiveBlock.Hash,
+		MomentumHeight:   momentumHeight,
+	}, nil
+}
+
 type Api struct {
 	chain     chain.Chain
 	log       log15.Logger
 	installCh chan *Subscription // add subscription
+	resumers  *resumers
+	stopped   chan struct{}
 }
 type Server struct {
 	*Api
@@ -66,8 +102,11 @@ type Server struct {
 	uninstallCh   chan *Subscription // remove subscription
 	acCh          chan []*AccountBlock
 	mCh           chan *Momentum
-	stopped       chan struct{}
+	eCh           chan []*EmbeddedEvent
 	subscriptions map[SubscriptionType]map[rpc.ID]*Subscription
+	// number of installed EmbeddedEvents subscriptions, read by InsertMomentum to skip decoding events nobody receives.
+	// Closed subscriptions are counted until the next broadcast uninstalls them.
+	embeddedSubscriptions int32
 
 	wg sync.WaitGroup
 }
@@ -82,12 +121,14 @@ func GetSubscribeServer(chain chain.Chain) *Server {
 				chain:     chain,
 				log:       common.RPCLogger.New("module", "subscribe_api"),
 				installCh: make(chan *Subscription, installSize),
+				resumers:  newResumers(),
+				stopped:   make(chan struct{}),
 			},
 
 			acCh:          make(chan []*AccountBlock, acChanSize),
 			mCh:           make(chan *Momentum, mChanSize),
+			eCh:           make(chan []*EmbeddedEvent, eChanSize),
 			uninstallCh:   make(chan *Subscription, uninstallSize),
-			stopped:       make(chan struct{}),
 			subscriptions: make(map[SubscriptionType]map[rpc.ID]*Subscription),
 		}
 	}
@@ -134,11 +175,14 @@ func (s *Server) Stop() error {
 	singleton = nil
 	s.log.Debug("wg.Wait() api Server.Stop()")
 	s.wg.Wait()
+	s.resumers.wg.Wait()
 	s.log.Debug("wg.Wait() api Server.Stop() finish")
 	return nil
 }
 
 func (s *Server) InsertMomentum(detailed *nom.DetailedMomentum) {
+	s.resumers.wakeAll()
+
 	select {
 	case s.mCh <- &Momentum{
 		Hash:   detailed.Momentum.Hash,
@@ -150,16 +194,51 @@ func (s *Server) InsertMomentum(detailed *nom.DetailedMomentum) {
 
 	abEvents := make([]*AccountBlock, 0, len(detailed.AccountBlocks))
 	for _, block := range detailed.AccountBlocks {
-		abEvents = append(abEvents, newAccountBlock(block)...)
+		abEvents = append(abEvents, newAccountBlock(block, detailed.Momentum.Height)...)
 	}
 	select {
 	case s.acCh <- abEvents:
 	default:
 		s.log.Error("can't insert account-blocks for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
 	}
-	return
+
+	if atomic.LoadInt32(&s.embeddedSubscriptions) == 0 {
+		return
+	}
+	eEvents := s.embeddedEvents(detailed)
+	if len(eEvents) == 0 {
+		return
+	}
+	select {
+	case s.eCh <- eEvents:
+	default:
+		s.log.Error("can't insert embedded-events for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+	}
+}
+func (s *Server) embeddedEvents(detailed *nom.DetailedMomentum) []*EmbeddedEvent {
+	events := make([]*EmbeddedEvent, 0)
+	momentumStore := s.chain.GetFrontierMomentumStore()
+	for _, block := range detailed.AccountBlocks {
+		// failed calls are rolled back and don't change the state of the contract
+		if !vm.IsSuccessfulEmbeddedReceive(block) {
+			continue
+		}
+		sendBlock, err := momentumStore.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil || sendBlock == nil {
+			s.log.Error("can't find send-block for embedded-event", "reason", err, "receive-block-hash", block.Hash)
+			continue
+		}
+		event, err := newEmbeddedEvent(sendBlock, block, detailed.Momentum.Height)
+		if err != nil {
+			// plain transfers to embedded contracts don't call any method
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
 }
 func (s *Server) DeleteMomentum(*nom.DetailedMomentum) {
+	s.resumers.wakeAll()
 }
 
 func (s *Server) work() {
@@ -172,6 +251,8 @@ func (s *Server) work() {
 		case <-s.stopped:
 			log.Info("stopped")
 			s.subscriptions = nil
+			atomic.StoreInt32(&s.embeddedSubscriptions, 0)
+			resetSubscriptionGauges()
 			return
 		case sub := <-s.installCh:
 			s.install(sub)
@@ -181,6 +262,8 @@ func (s *Server) work() {
 			s.broadcastMomentums(momentums)
 		case blocks := <-s.acCh:
 			s.broadcastBlocks(blocks)
+		case events := <-s.eCh:
+			s.broadcastEmbeddedEvents(events)
 		}
 	}
 }
@@ -193,10 +276,18 @@ type BroadcastStats struct {
 func (s *Server) install(subscription *Subscription) {
 	s.log.Info("install", "id", subscription.rpc.ID)
 	s.subscriptions[subscription.options.subscriptionType][subscription.rpc.ID] = subscription
+	s.updateSubscriptionCount(subscription.options.subscriptionType)
 }
 func (s *Server) uninstall(subscription *Subscription) {
 	s.log.Info("uninstall", "id", subscription.rpc.ID)
 	delete(s.subscriptions[subscription.options.subscriptionType], subscription.rpc.ID)
+	s.updateSubscriptionCount(subscription.options.subscriptionType)
+}
+func (s *Server) updateSubscriptionCount(subscriptionType SubscriptionType) {
+	if subscriptionType == EmbeddedEventsSubscription {
+		atomic.StoreInt32(&s.embeddedSubscriptions, int32(len(s.subscriptions[subscriptionType])))
+	}
+	s.updateSubscriptionGauge(subscriptionType)
 }
 func (s *Server) broadcast(subscription *Subscription, data interface{}, stats *BroadcastStats) {
 	if subscription.Closed() {
@@ -259,6 +350,32 @@ func (s *Server) broadcastBlocks(blocks []*AccountBlock) {
 	s.log.Info("finish broadcasting account-blocks", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
 }
 
+func (s *Server) broadcastEmbeddedEvents(events []*EmbeddedEvent) {
+	if len(events) == 0 {
+		return
+	}
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	for _, f := range s.subscriptions[EmbeddedEventsSubscription] {
+		filtered := make([]*EmbeddedEvent, 0, len(events))
+		for _, event := range events {
+			if f.options.contract != nil && *f.options.contract != event.Contract {
+				continue
+			}
+			if f.options.methods != nil && !f.options.methods[event.Method] {
+				continue
+			}
+			filtered = append(filtered, event)
+		}
+		if len(filtered) != 0 {
+			s.broadcast(f, filtered, stats)
+		}
+	}
+
+	s.log.Info("finish broadcasting embedded-events", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+
 func (s *Api) subscribe(ctx context.Context, options *subscriptionOptions) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -269,19 +386,61 @@ func (s *Api) subscribe(ctx context.Context, options *subscriptionOptions) (*rpc
 	return subscription.rpc, nil
 }
 
-func (s *Api) Momentums(ctx context.Context) (*rpc.Subscription, error) {
+// resume replays the chain from the resume point before delivering new events, if from is not nil
+func (s *Api) resume(ctx context.Context, options *subscriptionOptions, from *ResumeFrom) (*rpc.Subscription, error) {
+	if from == nil {
+		return s.subscribe(ctx, options)
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	subscription := NewSubscription(notifier, options)
+	r, err := newResumer(s.chain, subscription, s.stopped, from)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resumers.start(r); err != nil {
+		return nil, err
+	}
+	return subscription.rpc, nil
+}
+
+// Momentums notifies about new momentums.
+// If from is set, the momentums starting with the resume point are delivered first, followed by the new momentums
+// without gaps or duplicates. Resumed subscriptions are also notified with a Rollback when delivered momentums are removed,
+// and with a ResumeFailure when the replay can't continue.
+func (s *Api) Momentums(ctx context.Context, from *ResumeFrom) (*rpc.Subscription, error) {
 	s.log.Info("new subscription", "type", "Momentums")
-	return s.subscribe(ctx, NewMomentumsSubscription())
+	return s.resume(ctx, NewMomentumsSubscription(), from)
 }
-func (s *Api) AllAccountBlocks(ctx context.Context) (*rpc.Subscription, error) {
+
+// AllAccountBlocks notifies about the account-blocks of new momentums. See Momentums for from.
+func (s *Api) AllAccountBlocks(ctx context.Context, from *ResumeFrom) (*rpc.Subscription, error) {
 	s.log.Info("new subscription", "type", "AllAccountBlocks")
-	return s.subscribe(ctx, NewBlocksSubscription())
+	return s.resume(ctx, NewBlocksSubscription(), from)
 }
-func (s *Api) AccountBlocksByAddress(ctx context.Context, address types.Address) (*rpc.Subscription, error) {
+
+// AccountBlocksByAddress notifies about the account-blocks of the address in new momentums. See Momentums for from.
+func (s *Api) AccountBlocksByAddress(ctx context.Context, address types.Address, from *ResumeFrom) (*rpc.Subscription, error) {
 	s.log.Info("new subscription", "type", "AccountBlocksByAddress")
-	return s.subscribe(ctx, NewBlocksByAddressSubscription(address))
+	return s.resume(ctx, NewBlocksByAddressSubscription(address), from)
 }
 func (s *Api) UnreceivedAccountBlocksByAddress(ctx context.Context, address types.Address) (*rpc.Subscription, error) {
 	s.log.Info("new subscription", "type", "UnreceivedAccountBlocksByAddress")
 	return s.subscribe(ctx, NewToUnreceivedBlocksSubscription(address))
 }
+
+// EmbeddedEvents notifies about successful calls of embedded contract methods,
+// e.g. pillar registrations, delegations, wrap requests, htlc unlocks or spork activations.
+//   - contract restricts the events to a single embedded contract; nil means all contracts
+//   - methods restricts the events to the given method names; empty means all methods
+func (s *Api) EmbeddedEvents(ctx context.Context, contract *types.Address, methods []string) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "EmbeddedEvents")
+	if contract != nil {
+		if _, err := embedded.GetEmbeddedABI(*contract); err != nil {
+			return nil, err
+		}
+	}
+	return s.subscribe(ctx, NewEmbeddedEventsSubscription(contract, methods))
+}
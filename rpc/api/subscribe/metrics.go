@@ -0,0 +1,32 @@
+package subscribe
+
+import (
+	"github.com/zenon-network/go-zenon/common/metrics"
+)
+
+var (
+	// name of the gauge with the number of active subscriptions of each type
+	subscriptionGauges = map[SubscriptionType]string{
+		AllAccountBlocksSubscription:                 "rpc/subscriptions/allAccountBlocks",
+		AccountBlocksSubscriptionByAddress:           "rpc/subscriptions/accountBlocksByAddress",
+		UnreceivedAccountBlocksSubscriptionByAddress: "rpc/subscriptions/unreceivedAccountBlocksByAddress",
+		MomentumsSubscription:                        "rpc/subscriptions/momentums",
+		EmbeddedEventsSubscription:                   "rpc/subscriptions/embeddedEvents",
+	}
+)
+
+// resumedSubscriptionGauge counts the subscriptions which were resumed from a momentum, of any type
+func resumedSubscriptionGauge() metrics.Gauge {
+	return metrics.GetOrRegisterGauge("rpc/subscriptions/resumed")
+}
+
+func (s *Server) updateSubscriptionGauge(subscriptionType SubscriptionType) {
+	if name, ok := subscriptionGauges[subscriptionType]; ok {
+		metrics.GetOrRegisterGauge(name).Update(int64(len(s.subscriptions[subscriptionType])))
+	}
+}
+func resetSubscriptionGauges() {
+	for _, name := range subscriptionGauges {
+		metrics.GetOrRegisterGauge(name).Update(0)
+	}
+}
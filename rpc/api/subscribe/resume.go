@@ -0,0 +1,286 @@
+package subscribe
+
+import (
+	"sync"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// number of momentums read from the store at once while replaying
+	replayBatchSize = 100
+	// number of delivered momentums remembered to find the momentum to which the chain was rollbacked
+	maxRollbackDepth = 1000
+	// number of resumed subscriptions which can replay the chain at the same time
+	maxResumers = 64
+)
+
+var (
+	ErrResumeFromIsEmpty      = common.NewErrorWCode(-32000, "resume point must have either a height or a hash")
+	ErrResumeMomentumNotFound = common.NewErrorWCode(-32000, "momentum to resume from is not part of the chain; resume from a height instead")
+	ErrTooManyResumers        = common.NewErrorWCode(-32000, "too many resumed subscriptions; try again later")
+	ErrResumeBlocksPruned     = common.NewErrorWCode(-32000, "account-blocks to replay were pruned by this node; resume from a later height")
+	ErrRollbackTooDeep        = common.NewErrorWCode(-32000, "chain was rollbacked below the momentums remembered by the subscription; resume from a height instead")
+)
+
+// ResumeFrom is the point from which a subscription replays the chain before delivering new events.
+//   - Height replays the chain starting with the momentum at Height
+//   - Hash is the last momentum seen by the client; the chain is replayed starting with the next momentum
+type ResumeFrom struct {
+	Height uint64      `json:"height"`
+	Hash   *types.Hash `json:"hash"`
+}
+
+// Rollback is delivered to resumed subscriptions when momentums which were already delivered are removed from the chain.
+// RollbackTo is the last momentum which is still part of the chain; the events which follow it are delivered again.
+type Rollback struct {
+	RollbackTo Momentum `json:"rollbackTo"`
+}
+
+// ResumeFailure is delivered to resumed subscriptions which can't continue. No event follows it,
+// so the client has to subscribe again.
+type ResumeFailure struct {
+	Error string `json:"error"`
+}
+
+// resumer delivers the events of a resumed subscription by reading the momentums from the store, in order.
+// Since the store is the only source, no momentum is skipped or delivered twice, regardless of when the
+// live events are broadcast. New momentums and rollbacks only wake up the resumer.
+type resumer struct {
+	log          log15.Logger
+	chain        chain.Chain
+	subscription *Subscription
+	stopped      chan struct{}
+	wake         chan struct{}
+
+	// next is the height of the next momentum to deliver
+	next uint64
+	// delivered holds the last delivered momentums, in ascending order of height
+	delivered []types.HashHeight
+	// forgotten is the last delivered momentum which is no longer remembered, zero if none
+	forgotten types.HashHeight
+}
+
+func newResumer(chain chain.Chain, subscription *Subscription, stopped chan struct{}, from *ResumeFrom) (*resumer, error) {
+	r := &resumer{
+		log:          subscription.log.New("submodule", "resumer"),
+		chain:        chain,
+		subscription: subscription,
+		stopped:      stopped,
+		wake:         make(chan struct{}, 1),
+		delivered:    make([]types.HashHeight, 0),
+	}
+	if from.Hash != nil {
+		momentum, err := chain.GetFrontierMomentumStore().GetMomentumByHash(*from.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if momentum == nil {
+			return nil, ErrResumeMomentumNotFound
+		}
+		r.next = momentum.Height + 1
+		r.delivered = append(r.delivered, momentum.Identifier())
+	} else if from.Height != 0 {
+		r.next = from.Height
+	} else {
+		return nil, ErrResumeFromIsEmpty
+	}
+	return r, nil
+}
+
+// resumers holds the running resumers, which are woken up on every momentum event
+type resumers struct {
+	lock sync.Mutex
+	set  map[*resumer]struct{}
+	wg   sync.WaitGroup
+}
+
+func newResumers() *resumers {
+	return &resumers{
+		set: make(map[*resumer]struct{}),
+	}
+}
+func (rs *resumers) start(r *resumer) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	if len(rs.set) >= maxResumers {
+		return ErrTooManyResumers
+	}
+	rs.set[r] = struct{}{}
+	resumedSubscriptionGauge().Update(int64(len(rs.set)))
+
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		r.run()
+
+		rs.lock.Lock()
+		defer rs.lock.Unlock()
+		delete(rs.set, r)
+		resumedSubscriptionGauge().Update(int64(len(rs.set)))
+	}()
+	return nil
+}
+func (rs *resumers) wakeAll() {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	for r := range rs.set {
+		r.Wake()
+	}
+}
+
+// Wake makes the resumer check the chain for new momentums and rollbacks
+func (r *resumer) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *resumer) run() {
+	defer common.RecoverStack()
+	r.log.Info("replaying chain", "from-height", r.next)
+	for {
+		if err := r.deliver(); err != nil {
+			r.log.Error("failed to deliver events", "reason", err)
+			r.subscription.Notify([]interface{}{&ResumeFailure{Error: err.Error()}})
+			return
+		}
+		if r.subscription.Closed() {
+			return
+		}
+		select {
+		case <-r.wake:
+		case <-r.stopped:
+			return
+		case err := <-r.subscription.rpc.Err():
+			r.log.Info("unsubscribing due to rpc-sub", "reason", err)
+			return
+		case <-r.subscription.notifier.Closed():
+			r.log.Info("unsubscribing", "reason", "notifier-closed")
+			return
+		}
+	}
+}
+
+// deliver notifies the rollback of delivered momentums, if any, followed by the events of all momentums up to the frontier
+func (r *resumer) deliver() error {
+	momentumStore := r.chain.GetFrontierMomentumStore()
+	if err := r.checkRollback(momentumStore); err != nil {
+		return err
+	}
+
+	frontier := momentumStore.Identifier().Height
+	for r.next <= frontier {
+		if r.subscription.Closed() || r.isStopped() {
+			return nil
+		}
+		momentums, err := momentumStore.GetMomentumsByHeight(r.next, true, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, momentum := range momentums {
+			if momentum == nil || momentum.Height > frontier {
+				return nil
+			}
+			if err := r.notify(momentumStore, momentum); err != nil {
+				return err
+			}
+			r.delivered = append(r.delivered, momentum.Identifier())
+			if len(r.delivered) > maxRollbackDepth {
+				r.forgotten = r.delivered[0]
+				r.delivered = r.delivered[1:]
+			}
+			r.next = momentum.Height + 1
+		}
+	}
+	return nil
+}
+func (r *resumer) isStopped() bool {
+	select {
+	case <-r.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *resumer) checkRollback(momentumStore store.Momentum) error {
+	rollbacked := false
+	for len(r.delivered) != 0 {
+		last := r.delivered[len(r.delivered)-1]
+		momentum, err := momentumStore.GetMomentumByHeight(last.Height)
+		if err != nil {
+			return err
+		}
+		if momentum != nil && momentum.Hash == last.Hash {
+			break
+		}
+		r.delivered = r.delivered[:len(r.delivered)-1]
+		r.next = last.Height
+		rollbacked = true
+	}
+	if !rollbacked {
+		return nil
+	}
+
+	momentum, err := momentumStore.GetMomentumByHeight(r.next - 1)
+	if err != nil {
+		return err
+	}
+	// the momentums below the last forgotten one can't be checked
+	if len(r.delivered) == 0 && r.forgotten.Height != 0 && (momentum == nil || momentum.Hash != r.forgotten.Hash) {
+		return ErrRollbackTooDeep
+	}
+	if momentum == nil {
+		return nil
+	}
+	r.log.Info("notifying rollback", "rollback-to", momentum.Identifier())
+	r.delivered = append(r.delivered[:0], momentum.Identifier())
+	r.subscription.Notify([]interface{}{&Rollback{
+		RollbackTo: Momentum{
+			Hash:   momentum.Hash,
+			Height: momentum.Height,
+		},
+	}})
+	return nil
+}
+
+func (r *resumer) notify(momentumStore store.Momentum, momentum *nom.Momentum) error {
+	options := r.subscription.options
+	if options.subscriptionType == MomentumsSubscription {
+		r.subscription.Notify([]interface{}{&Momentum{
+			Hash:   momentum.Hash,
+			Height: momentum.Height,
+		}})
+		return nil
+	}
+
+	detailed, err := momentumStore.PrefetchMomentum(momentum)
+	if err != nil {
+		return err
+	}
+	blocks := make([]*AccountBlock, 0)
+	for _, block := range detailed.AccountBlocks {
+		// the bodies of pruned account-blocks can't be replayed
+		if block == nil {
+			return ErrResumeBlocksPruned
+		}
+		for _, event := range newAccountBlock(block, momentum.Height) {
+			if options.subscriptionType == AccountBlocksSubscriptionByAddress && event.Address != options.address {
+				continue
+			}
+			blocks = append(blocks, event)
+		}
+	}
+	if len(blocks) != 0 {
+		r.subscription.Notify(blocks)
+	}
+	return nil
+}
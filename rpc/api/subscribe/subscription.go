@@ -18,6 +18,7 @@ const (
 	AccountBlocksSubscriptionByAddress
 	UnreceivedAccountBlocksSubscriptionByAddress
 	MomentumsSubscription
+	EmbeddedEventsSubscription
 	LastSubscriptionType
 )
 
@@ -25,6 +26,8 @@ type subscriptionOptions struct {
 	subscriptionType SubscriptionType
 	createTime       time.Time
 	address          types.Address
+	contract         *types.Address
+	methods          map[string]bool
 }
 
 func newSubscription(subscriptionType SubscriptionType) *subscriptionOptions {
@@ -49,6 +52,17 @@ func NewToUnreceivedBlocksSubscription(addr types.Address) *subscriptionOptions
 func NewMomentumsSubscription() *subscriptionOptions {
 	return newSubscription(MomentumsSubscription)
 }
+func NewEmbeddedEventsSubscription(contract *types.Address, methods []string) *subscriptionOptions {
+	sub := newSubscription(EmbeddedEventsSubscription)
+	sub.contract = contract
+	if len(methods) != 0 {
+		sub.methods = make(map[string]bool, len(methods))
+		for _, method := range methods {
+			sub.methods[method] = true
+		}
+	}
+	return sub
+}
 
 type Subscription struct {
 	log      log15.Logger
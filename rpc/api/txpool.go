@@ -0,0 +1,286 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// PoolStatusReady is the status of the uncommitted account-blocks which fit in the next momentum
+	PoolStatusReady = "ready"
+	// PoolStatusQueued is the status of the uncommitted account-blocks which wait for a later momentum, since the next
+	// one is full
+	PoolStatusQueued = "queued"
+
+	BlockStatusPending   = "pending"
+	BlockStatusConfirmed = "confirmed"
+	BlockStatusRejected  = "rejected"
+	BlockStatusUnknown   = "unknown"
+)
+
+// TxPoolApi shows the uncommitted account-blocks which wait in the account pool to be confirmed by a momentum,
+// and the account-blocks recently dropped by the pool
+type TxPoolApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewTxPoolApi(z zenon.Zenon) *TxPoolApi {
+	return &TxPoolApi{
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "txpool_api"),
+	}
+}
+
+// AccountBlockSummary is the part of an account-block needed to follow it in the pool. PlasmaRatio is the ratio of
+// TotalPlasma to BasePlasma, which decides between two account-blocks at the same height.
+type AccountBlockSummary struct {
+	Hash                 types.Hash               `json:"hash"`
+	Height               uint64                   `json:"height"`
+	Address              types.Address            `json:"address"`
+	ToAddress            types.Address            `json:"toAddress"`
+	BlockType            uint64                   `json:"blockType"`
+	TokenStandard        types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount               string                   `json:"amount"`
+	MomentumAcknowledged types.HashHeight         `json:"momentumAcknowledged"`
+	TotalPlasma          uint64                   `json:"totalPlasma"`
+	BasePlasma           uint64                   `json:"basePlasma"`
+	PlasmaRatio          float64                  `json:"plasmaRatio"`
+}
+
+// PoolAccountBlock is an uncommitted account-block. AddedAt is the unix timestamp at which the block was added to the
+// pool, and Age the number of seconds since then.
+type PoolAccountBlock struct {
+	AccountBlockSummary
+	AddedAt int64  `json:"addedAt"`
+	Age     int64  `json:"age"`
+	Status  string `json:"status"`
+}
+
+type PoolAccountBlockList struct {
+	List  []*PoolAccountBlock `json:"list"`
+	Count int                 `json:"count"`
+	More  bool                `json:"more"`
+}
+
+// RejectedAccountBlock is an account-block dropped by the pool. ReplacedBy is set for the blocks rolled back to insert
+// a conflicting block.
+type RejectedAccountBlock struct {
+	Block      *AccountBlockSummary `json:"block"`
+	Reason     string               `json:"reason"`
+	ReplacedBy *types.HashHeight    `json:"replacedBy"`
+	RejectedAt int64                `json:"rejectedAt"`
+}
+
+type RejectedAccountBlockList struct {
+	List  []*RejectedAccountBlock `json:"list"`
+	Count int                     `json:"count"`
+	More  bool                    `json:"more"`
+}
+
+type TxPoolStatus struct {
+	Pending   int `json:"pending"`
+	Ready     int `json:"ready"`
+	Queued    int `json:"queued"`
+	Addresses int `json:"addresses"`
+	Rejected  int `json:"rejected"`
+}
+
+// AddressPoolStatus is the account-chain of the address in the pool. Frontier is the last uncommitted account-block,
+// or the confirmed frontier if the address has no uncommitted account-blocks.
+type AddressPoolStatus struct {
+	Address           types.Address       `json:"address"`
+	ConfirmedFrontier types.HashHeight    `json:"confirmedFrontier"`
+	Frontier          types.HashHeight    `json:"frontier"`
+	Pending           []*PoolAccountBlock `json:"pending"`
+}
+
+// AccountBlockStatus tells where the account-block is. Status is one of BlockStatusPending, BlockStatusConfirmed,
+// BlockStatusRejected and BlockStatusUnknown.
+//   - Pending is set for uncommitted account-blocks
+//   - ConfirmationHeight is set for confirmed account-blocks
+//   - Rejections lists when the account-block was dropped by the pool, even if it was added again since
+type AccountBlockStatus struct {
+	Hash               types.Hash              `json:"hash"`
+	Status             string                  `json:"status"`
+	Pending            *PoolAccountBlock       `json:"pending"`
+	ConfirmationHeight uint64                  `json:"confirmationHeight"`
+	Rejections         []*RejectedAccountBlock `json:"rejections"`
+}
+
+func toAccountBlockSummary(block *nom.AccountBlock) *AccountBlockSummary {
+	result := &AccountBlockSummary{
+		Hash:                 block.Hash,
+		Height:               block.Height,
+		Address:              block.Address,
+		ToAddress:            block.ToAddress,
+		BlockType:            block.BlockType,
+		TokenStandard:        block.TokenStandard,
+		Amount:               block.Amount.String(),
+		MomentumAcknowledged: block.MomentumAcknowledged,
+		TotalPlasma:          block.TotalPlasma,
+		BasePlasma:           block.BasePlasma,
+	}
+	if block.BasePlasma != 0 {
+		result.PlasmaRatio = float64(block.TotalPlasma) / float64(block.BasePlasma)
+	}
+	return result
+}
+
+// pending returns the uncommitted account-blocks of the address, or of all addresses if address is nil, the longest
+// waiting first
+func (t *TxPoolApi) pending(address *types.Address) []*PoolAccountBlock {
+	now := time.Now()
+	list := make([]*PoolAccountBlock, 0)
+	for _, pooled := range t.chain.GetPooledAccountBlocks() {
+		if address != nil && pooled.Block.Address != *address {
+			continue
+		}
+		block := &PoolAccountBlock{
+			AccountBlockSummary: *toAccountBlockSummary(pooled.Block),
+			Status:              PoolStatusQueued,
+		}
+		if pooled.Ready {
+			block.Status = PoolStatusReady
+		}
+		if !pooled.Added.IsZero() {
+			block.AddedAt = pooled.Added.Unix()
+			block.Age = int64(now.Sub(pooled.Added) / time.Second)
+		}
+		list = append(list, block)
+	}
+	// the pool orders the account-blocks by address and height, which breaks the ties
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].AddedAt < list[j].AddedAt
+	})
+	return list
+}
+
+func toRejected(rejected *chain.RejectedAccountBlock) *RejectedAccountBlock {
+	return &RejectedAccountBlock{
+		Block:      toAccountBlockSummary(rejected.Block),
+		Reason:     rejected.Reason.Error(),
+		ReplacedBy: rejected.ReplacedBy,
+		RejectedAt: rejected.Time.Unix(),
+	}
+}
+
+func (t *TxPoolApi) GetStatus() (*TxPoolStatus, error) {
+	pending := t.pending(nil)
+	status := &TxPoolStatus{
+		Pending:  len(pending),
+		Rejected: len(t.chain.GetRejectedAccountBlocks()),
+	}
+	addresses := make(map[types.Address]bool)
+	for _, block := range pending {
+		addresses[block.Address] = true
+		if block.Status == PoolStatusReady {
+			status.Ready += 1
+		} else {
+			status.Queued += 1
+		}
+	}
+	status.Addresses = len(addresses)
+	return status, nil
+}
+
+// GetContent returns the uncommitted account-blocks of all addresses, the longest waiting first
+func (t *TxPoolApi) GetContent(pageIndex, pageSize uint32) (*PoolAccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+
+	pending := t.pending(nil)
+	start, end := GetRange(pageIndex, pageSize, uint32(len(pending)))
+	return &PoolAccountBlockList{
+		List:  pending[start:end],
+		Count: len(pending),
+		More:  end < uint32(len(pending)),
+	}, nil
+}
+
+func (t *TxPoolApi) GetContentByAddress(address types.Address) (*AddressPoolStatus, error) {
+	confirmed, err := t.chain.GetFrontierMomentumStore().GetFrontierAccountBlock(address)
+	if err != nil {
+		return nil, err
+	}
+	frontier, err := t.chain.GetFrontierAccountStore(address).Frontier()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &AddressPoolStatus{
+		Address: address,
+		Pending: t.pending(&address),
+	}
+	if confirmed != nil {
+		status.ConfirmedFrontier = confirmed.Identifier()
+	}
+	if frontier != nil {
+		status.Frontier = frontier.Identifier()
+	}
+	return status, nil
+}
+
+// GetRejected returns the account-blocks recently dropped by the pool, the most recently dropped first
+func (t *TxPoolApi) GetRejected(pageIndex, pageSize uint32) (*RejectedAccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+
+	rejected := t.chain.GetRejectedAccountBlocks()
+	start, end := GetRange(pageIndex, pageSize, uint32(len(rejected)))
+	list := make([]*RejectedAccountBlock, 0, end-start)
+	for _, entry := range rejected[start:end] {
+		list = append(list, toRejected(entry))
+	}
+	return &RejectedAccountBlockList{
+		List:  list,
+		Count: len(rejected),
+		More:  end < uint32(len(rejected)),
+	}, nil
+}
+
+// GetAccountBlockStatus tells whether the account-block waits in the pool, was confirmed or was dropped by the pool
+func (t *TxPoolApi) GetAccountBlockStatus(hash types.Hash) (*AccountBlockStatus, error) {
+	status := &AccountBlockStatus{
+		Hash:       hash,
+		Status:     BlockStatusUnknown,
+		Rejections: make([]*RejectedAccountBlock, 0),
+	}
+	for _, rejected := range t.chain.GetRejectedAccountBlocks() {
+		if rejected.Block.Hash == hash {
+			status.Status = BlockStatusRejected
+			status.Rejections = append(status.Rejections, toRejected(rejected))
+		}
+	}
+
+	momentumStore := t.chain.GetFrontierMomentumStore()
+	block, err := momentumStore.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		status.Status = BlockStatusConfirmed
+		if status.ConfirmationHeight, err = momentumStore.GetBlockConfirmationHeight(hash); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+
+	for _, pending := range t.pending(nil) {
+		if pending.Hash == hash {
+			status.Status = BlockStatusPending
+			status.Pending = pending
+		}
+	}
+	return status, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
 )
@@ -42,6 +43,49 @@ func GetFrontierContext(c chain.Chain, addr types.Address) (*nom.Momentum, vm_co
 	return frontier, context, nil
 }
 
+// GetMomentumStoreByHeight returns the momentum store as it was right after the momentum at the given height was inserted.
+// Returns ErrStateNotAvailable if the underlying versioned DB no longer retains that state.
+func GetMomentumStoreByHeight(c chain.Chain, height uint64) (store.Momentum, error) {
+	if height == 0 {
+		return nil, ErrHeightParamIsZero
+	}
+
+	momentum, err := c.GetFrontierMomentumStore().GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, ErrMomentumNotFound
+	}
+
+	momentumStore := c.GetMomentumStore(momentum.Identifier())
+	if momentumStore == nil {
+		return nil, ErrStateNotAvailable
+	}
+	return momentumStore, nil
+}
+
+// GetContextByHeight is the historical counterpart of GetFrontierContext.
+// The account context only contains blocks confirmed by momentums up to, and including, the given height.
+func GetContextByHeight(c chain.Chain, addr types.Address, height uint64) (*nom.Momentum, vm_context.AccountVmContext, error) {
+	store, err := GetMomentumStoreByHeight(c, height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	momentum, err := store.GetFrontierMomentum()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	context := vm_context.NewAccountContext(
+		store,
+		store.GetAccountStore(addr),
+		nil,
+	)
+	return momentum, context, nil
+}
+
 func checkTokenIdValid(chain chain.Chain, ts *types.ZenonTokenStandard) error {
 	store := chain.GetFrontierMomentumStore()
 	if ts != nil && (*ts) != types.ZeroTokenStandard {
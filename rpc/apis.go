@@ -9,14 +9,25 @@ import (
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
-func getApi(z zenon.Zenon, p2p *p2p.Server, apiModule string) []rpc.API {
+// PrivateMethods are the methods served over HTTP and WebSocket only to the clients with an admin key, see rpc.PolicyConfig
+var PrivateMethods = []string{"admin.*"}
+
+// Options changes the behavior of the APIs
+type Options struct {
+	// DecodeBlockData adds the decoded calls to embedded contracts to the account-blocks returned by the ledger API
+	DecodeBlockData bool
+}
+
+func getApi(z zenon.Zenon, p2p *p2p.Server, options Options, apiModule string) []rpc.API {
 	switch apiModule {
 	case "ledger":
+		ledgerApi := api.NewLedgerApi(z)
+		ledgerApi.DecodeBlockData = options.DecodeBlockData
 		return []rpc.API{
 			{
 				Namespace: "ledger",
 				Version:   "1.0",
-				Service:   api.NewLedgerApi(z),
+				Service:   ledgerApi,
 				Public:    true,
 			},
 		}
@@ -97,6 +108,12 @@ func getApi(z zenon.Zenon, p2p *p2p.Server, apiModule string) []rpc.API {
 				Service:   embedded.NewLiquidityApi(z),
 				Public:    true,
 			},
+			{
+				Namespace: "embedded.abi",
+				Version:   "1.0",
+				Service:   embedded.NewAbiApi(z),
+				Public:    true,
+			},
 		}
 	case "stats":
 		return []rpc.API{
@@ -106,18 +123,54 @@ func getApi(z zenon.Zenon, p2p *p2p.Server, apiModule string) []rpc.API {
 				Service:   api.NewStatsApi(z, p2p),
 				Public:    true,
 			},
+			{
+				Namespace: "stats.economics",
+				Version:   "1.0",
+				Service:   embedded.NewEconomicsApi(z),
+				Public:    true,
+			},
+		}
+	case "txpool":
+		return []rpc.API{
+			{
+				Namespace: "txpool",
+				Version:   "1.0",
+				Service:   api.NewTxPoolApi(z),
+				Public:    true,
+			},
+		}
+	case "debug":
+		// not public, the namespace is served only if listed in the RPC endpoints
+		return []rpc.API{
+			{
+				Namespace: "debug",
+				Version:   "1.0",
+				Service:   api.NewDebugApi(z),
+				Public:    false,
+			},
+		}
+	case "admin":
+		// not public, the namespace is served only if listed in the RPC endpoints, and only to the clients allowed
+		// by PrivateMethods
+		return []rpc.API{
+			{
+				Namespace: "admin",
+				Version:   "1.0",
+				Service:   api.NewAdminApi(z),
+				Public:    false,
+			},
 		}
 	default:
 		return []rpc.API{}
 	}
 }
-func GetApis(z zenon.Zenon, p2p *p2p.Server, apiModule ...string) []rpc.API {
+func GetApis(z zenon.Zenon, p2p *p2p.Server, options Options, apiModule ...string) []rpc.API {
 	var apis []rpc.API
 	for _, m := range apiModule {
-		apis = append(apis, getApi(z, p2p, m)...)
+		apis = append(apis, getApi(z, p2p, options, m)...)
 	}
 	return apis
 }
-func GetPublicApis(z zenon.Zenon, p2p *p2p.Server) []rpc.API {
-	return GetApis(z, p2p, "ledger", "ledgerSubscribe", "embedded", "stats")
+func GetPublicApis(z zenon.Zenon, p2p *p2p.Server, options Options) []rpc.API {
+	return GetApis(z, p2p, options, "ledger", "ledgerSubscribe", "embedded", "stats", "txpool", "debug", "admin")
 }
@@ -54,8 +54,12 @@ var (
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
+	_ Error = new(methodDeniedError)
+	_ Error = new(limitExceededError)
 )
 
+var errInvalidAPIKey = &limitExceededError{"invalid API key"}
+
 const defaultErrorCode = -32000
 
 type methodNotFoundError struct{ method string }
@@ -101,3 +105,29 @@ type invalidParamsError struct{ message string }
 func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
+
+// the method is denied by the policy of the server
+type methodDeniedError struct {
+	method  string
+	private bool
+}
+
+func (e *methodDeniedError) ErrorCode() int { return -32004 }
+
+func (e *methodDeniedError) Error() string {
+	if e.private {
+		return fmt.Sprintf("the method %s is only allowed for clients with an admin key", e.method)
+	}
+	return fmt.Sprintf("the method %s is not allowed", e.method)
+}
+
+// a limit of the policy of the server was exceeded
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+func errResponseTooLarge(limit int) error {
+	return &limitExceededError{fmt.Sprintf("response too large, the limit is %d bytes", limit)}
+}
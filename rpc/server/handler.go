@@ -19,6 +19,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -61,6 +62,8 @@ type handler struct {
 	conn           jsonWriter                     // where responses will be sent
 	log            log.Logger
 	allowSubscribe bool
+	policy         *Policy  // restricts the calls served, nil serves all calls
+	peer           PeerInfo // the client of the connection
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -84,6 +87,7 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		allowSubscribe: true,
 		serverSubs:     make(map[ID]*Subscription),
 		log:            log.Root(),
+		peer:           conn.peerInfo(),
 	}
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
@@ -101,6 +105,19 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		})
 		return
 	}
+	if limit := h.policy.batchLimit(); limit != 0 && len(msgs) > limit {
+		h.startCallProc(func(cp *callProc) {
+			err := &limitExceededError{fmt.Sprintf("batch too large, the limit is %d calls", limit)}
+			answers := make([]*jsonrpcMessage, 0, len(msgs))
+			for _, msg := range msgs {
+				if msg.hasValidID() {
+					answers = append(answers, msg.errorResponse(err))
+				}
+			}
+			h.conn.writeJSON(cp.ctx, answers)
+		})
+		return
+	}
 
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
@@ -115,8 +132,20 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
 		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		size, limit := 0, h.policy.responseSizeLimit()
 		for _, msg := range calls {
+			// the calls which follow the one exceeding the response size limit are not executed
+			if limit != 0 && size > limit {
+				if msg.hasValidID() {
+					answers = append(answers, msg.errorResponse(errResponseTooLarge(limit)))
+				}
+				continue
+			}
 			if answer := h.handleCallMsg(cp, msg); answer != nil {
+				size += len(answer.Result)
+				if limit != 0 && size > limit {
+					answer = msg.errorResponse(errResponseTooLarge(limit))
+				}
 				answers = append(answers, answer)
 			}
 		}
@@ -137,6 +166,9 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	}
 	h.startCallProc(func(cp *callProc) {
 		answer := h.handleCallMsg(cp, msg)
+		if limit := h.policy.responseSizeLimit(); answer != nil && limit != 0 && len(answer.Result) > limit {
+			answer = msg.errorResponse(errResponseTooLarge(limit))
+		}
 		h.addSubscriptions(cp.notifiers)
 		if answer != nil {
 			h.conn.writeJSON(cp.ctx, answer)
@@ -204,6 +236,13 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	}
 }
 
+// subscriptionCount returns the number of active subscriptions of the connection
+func (h *handler) subscriptionCount() int {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+	return len(h.serverSubs)
+}
+
 // cancelServerSubscriptions removes all subscriptions and closes their error channels.
 func (h *handler) cancelServerSubscriptions(err error) {
 	h.subLock.Lock()
@@ -321,6 +360,11 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !msg.isUnsubscribe() {
+		if err := h.policy.allowCall(h.peer, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -343,13 +387,15 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
 	if callb != h.unsubscribeCb {
-		rpcRequestGauge.Inc(1)
+		rpcRequestGauge().Inc(1)
+		newRPCCallCounter(msg.Method).Inc(1)
 		if answer.Error != nil {
-			failedReqeustGauge.Inc(1)
+			failedReqeustGauge().Inc(1)
+			newRPCErrorCounter(msg.Method, answer.Error.Code).Inc(1)
 		} else {
-			successfulRequestGauge.Inc(1)
+			successfulRequestGauge().Inc(1)
 		}
-		rpcServingTimer.UpdateSince(start)
+		rpcServingTimer().UpdateSince(start)
 		newRPCServingTimer(msg.Method, answer.Error == nil).UpdateSince(start)
 	}
 	return answer
@@ -360,6 +406,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if !h.allowSubscribe {
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
+	if limit := h.policy.subscriptionLimit(); limit != 0 && h.subscriptionCount()+len(cp.notifiers) >= limit {
+		return msg.errorResponse(&limitExceededError{fmt.Sprintf("too many subscriptions, the limit is %d", limit)})
+	}
 
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
@@ -56,6 +56,10 @@ func (hc *httpConn) remoteAddr() string {
 	return hc.url
 }
 
+func (hc *httpConn) peerInfo() PeerInfo {
+	return PeerInfo{RemoteAddr: hc.url}
+}
+
 func (hc *httpConn) readBatch() ([]*jsonrpcMessage, bool, error) {
 	<-hc.closeCh
 	return nil, false, io.EOF
@@ -210,7 +214,9 @@ type httpServerConn struct {
 func newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
 	body := io.LimitReader(r.Body, maxRequestContentLength)
 	conn := &httpServerConn{Reader: body, Writer: w, r: r}
-	return NewCodec(conn)
+	codec := NewCodec(conn).(*jsonCodec)
+	codec.setPeerInfo(newPeerInfo(r))
+	return codec
 }
 
 // Close does nothing and always returns nil.
@@ -165,6 +165,7 @@ type ConnRemoteAddr interface {
 // support for parsing arguments and serializing (result) objects.
 type jsonCodec struct {
 	remote  string
+	apiKey  string
 	closer  sync.Once                 // close closed channel once
 	closeCh chan interface{}          // closed on Close
 	decode  func(v interface{}) error // decoder to allow multiple transports
@@ -202,6 +203,16 @@ func (c *jsonCodec) remoteAddr() string {
 	return c.remote
 }
 
+func (c *jsonCodec) peerInfo() PeerInfo {
+	return PeerInfo{RemoteAddr: c.remote, APIKey: c.apiKey}
+}
+
+// setPeerInfo identifies the client of connections which don't know the remote address, such as WebSocket connections
+func (c *jsonCodec) setPeerInfo(peer PeerInfo) {
+	c.remote = peer.RemoteAddr
+	c.apiKey = peer.APIKey
+}
+
 func (c *jsonCodec) readBatch() (messages []*jsonrpcMessage, batch bool, err error) {
 	// Decode the next JSON object in the input stream.
 	// This verifies basic syntax, etc.
@@ -18,22 +18,48 @@ package server
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/zenon-network/go-zenon/common/metrics"
 )
 
-var (
-	rpcRequestGauge        = metrics.NewRegisteredGauge("rpc/requests", nil)
-	successfulRequestGauge = metrics.NewRegisteredGauge("rpc/success", nil)
-	failedReqeustGauge     = metrics.NewRegisteredGauge("rpc/failure", nil)
-	rpcServingTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
-)
+func rpcRequestGauge() metrics.Gauge {
+	return metrics.GetOrRegisterGauge("rpc/requests")
+}
+func successfulRequestGauge() metrics.Gauge {
+	return metrics.GetOrRegisterGauge("rpc/success")
+}
+func failedReqeustGauge() metrics.Gauge {
+	return metrics.GetOrRegisterGauge("rpc/failure")
+}
+func rpcServingTimer() metrics.Timer {
+	return metrics.GetOrRegisterTimer("rpc/duration/all")
+}
 
 func newRPCServingTimer(method string, valid bool) metrics.Timer {
 	flag := "success"
 	if !valid {
 		flag = "failure"
 	}
-	m := fmt.Sprintf("rpc/duration/%s/%s", method, flag)
-	return metrics.GetOrRegisterTimer(m, nil)
+	m := fmt.Sprintf("rpc/duration/%s/%s", metricName(method), flag)
+	return metrics.GetOrRegisterTimer(m)
+}
+
+// newRPCCallCounter counts the calls of a method
+func newRPCCallCounter(method string) metrics.Counter {
+	return metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/calls/%s", metricName(method)))
+}
+
+// newRPCErrorCounter counts the calls of a method which failed with the given error code.
+// Error codes are mostly negative, the sign is dropped since it's not valid in a metric name.
+func newRPCErrorCounter(method string, code int) metrics.Counter {
+	if code < 0 {
+		code = -code
+	}
+	return metrics.GetOrRegisterCounter(fmt.Sprintf("rpc/errors/%s/%d", metricName(method), code))
+}
+
+// metricName makes the method name a valid Prometheus metric name
+func metricName(method string) string {
+	return strings.ReplaceAll(method, ".", "_")
 }
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// APIKeyHeader is the HTTP header in which clients send their API key.
+	// WebSocket clients which can't set headers can use the apiKey query parameter of the URL instead, which is only
+	// read from the upgrade request so the keys don't end up in the URLs logged for each HTTP call.
+	APIKeyHeader   = "X-Api-Key"
+	apiKeyQueryArg = "apiKey"
+
+	// limiterSweepInterval is how often the buckets which are full again are dropped, to bound the memory of the limiters
+	limiterSweepInterval = time.Minute
+)
+
+// PeerInfo identifies the client of a connection
+type PeerInfo struct {
+	// RemoteAddr is the address of the client, in the host:port form for HTTP and WebSocket connections
+	RemoteAddr string
+	// APIKey is the key sent by the client, empty if none was sent
+	APIKey string
+}
+
+func newPeerInfo(r *http.Request) PeerInfo {
+	return PeerInfo{
+		RemoteAddr: r.RemoteAddr,
+		APIKey:     r.Header.Get(APIKeyHeader),
+	}
+}
+
+// newWebsocketPeerInfo is newPeerInfo for a WebSocket upgrade request, which can also carry the API key in the query
+func newWebsocketPeerInfo(r *http.Request) PeerInfo {
+	peer := newPeerInfo(r)
+	if peer.APIKey == "" {
+		peer.APIKey = r.URL.Query().Get(apiKeyQueryArg)
+	}
+	return peer
+}
+
+// IP returns the host part of RemoteAddr
+func (p PeerInfo) IP() string {
+	host, _, err := net.SplitHostPort(p.RemoteAddr)
+	if err != nil {
+		return p.RemoteAddr
+	}
+	return host
+}
+
+// PolicyConfig restricts which methods can be called and how much each client can consume. Zero values disable the limits.
+type PolicyConfig struct {
+	// Allow and Deny filter the methods served. Entries are either method names, such as "ledger.publishRawTransaction",
+	// or namespaces followed by ".*", such as "ledger.*". Deny takes precedence over Allow, and all the methods which
+	// are not denied are allowed if Allow is empty.
+	Allow []string
+	Deny  []string
+
+	// RateLimit is the number of calls per second allowed for each client IP. Bursts of up to RateBurst calls are
+	// allowed by all the limits, or of one second of calls if more.
+	RateLimit float64
+	RateBurst int
+	// MethodRateLimits limits the calls per second of each client IP to the methods matching the pattern,
+	// in addition to RateLimit. Patterns have the same form as in Allow.
+	MethodRateLimits map[string]float64
+	// APIKeys maps each accepted API key to its number of calls per second, which replaces the limits of the client IP.
+	// Calls with an API key which is not in APIKeys are rejected.
+	APIKeys map[string]float64
+	// Private lists the methods which are served only to the clients sending one of the AdminKeys. Patterns have the same
+	// form as in Allow. Clients connected from the loopback interface need an admin key too, since behind a reverse proxy
+	// every client seems to connect from it.
+	Private []string
+	// AdminKeys are the API keys which unlock the Private methods. Calls made with an admin key to the other methods
+	// are limited as the calls without a key, unless the key is also in APIKeys.
+	AdminKeys []string
+
+	// BatchLimit is the maximum number of calls in a batch
+	BatchLimit int
+	// ResponseSizeLimit is the maximum size in bytes of the result of a call, or of all the results of a batch.
+	// The size is only known once the method returned, so the limit bounds the bandwidth of the responses but not
+	// the work done to compute them, which is bounded by the page sizes the methods accept.
+	ResponseSizeLimit int
+	// SubscriptionLimit is the maximum number of active subscriptions of a WebSocket connection
+	SubscriptionLimit int
+}
+
+// Policy applies a PolicyConfig to the calls served by one or more servers, which share the rate limits.
+// A nil Policy doesn't restrict anything.
+type Policy struct {
+	config PolicyConfig
+
+	ipLimiter      *limiter
+	keyLimiters    map[string]*limiter
+	methodLimiters map[string]*limiter
+	adminKeys      map[string]bool
+}
+
+func NewPolicy(config PolicyConfig) *Policy {
+	p := &Policy{
+		config:         config,
+		keyLimiters:    make(map[string]*limiter, len(config.APIKeys)),
+		methodLimiters: make(map[string]*limiter, len(config.MethodRateLimits)),
+		adminKeys:      make(map[string]bool, len(config.AdminKeys)),
+	}
+	if config.RateLimit > 0 {
+		p.ipLimiter = newLimiter(config.RateLimit, config.RateBurst)
+	}
+	for key, rate := range config.APIKeys {
+		p.keyLimiters[key] = newLimiter(rate, config.RateBurst)
+	}
+	for pattern, rate := range config.MethodRateLimits {
+		p.methodLimiters[pattern] = newLimiter(rate, config.RateBurst)
+	}
+	for _, key := range config.AdminKeys {
+		if key != "" {
+			p.adminKeys[key] = true
+		}
+	}
+	return p
+}
+
+// allowCall returns the error sent back to the client instead of calling method, or nil if the call can proceed
+func (p *Policy) allowCall(peer PeerInfo, method string) error {
+	if p == nil {
+		return nil
+	}
+	if !p.methodAllowed(method) {
+		return &methodDeniedError{method: method}
+	}
+	if p.methodPrivate(method) {
+		if !p.adminKeys[peer.APIKey] {
+			return &methodDeniedError{method: method, private: true}
+		}
+		return nil
+	}
+
+	now := time.Now()
+	if peer.APIKey != "" {
+		keyLimiter, ok := p.keyLimiters[peer.APIKey]
+		if !ok && !p.adminKeys[peer.APIKey] {
+			return errInvalidAPIKey
+		}
+		if ok {
+			if !keyLimiter.allow(peer.APIKey, now) {
+				return &limitExceededError{"rate limit exceeded"}
+			}
+			return nil
+		}
+	}
+
+	ip := peer.IP()
+	if p.ipLimiter != nil && !p.ipLimiter.allow(ip, now) {
+		return &limitExceededError{"rate limit exceeded"}
+	}
+	for pattern, methodLimiter := range p.methodLimiters {
+		if matchMethod(pattern, method) && !methodLimiter.allow(ip, now) {
+			return &limitExceededError{"rate limit exceeded for " + method}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) methodAllowed(method string) bool {
+	for _, pattern := range p.config.Deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	if len(p.config.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.config.Allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) methodPrivate(method string) bool {
+	for _, pattern := range p.config.Private {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) batchLimit() int {
+	if p == nil {
+		return 0
+	}
+	return p.config.BatchLimit
+}
+func (p *Policy) responseSizeLimit() int {
+	if p == nil {
+		return 0
+	}
+	return p.config.ResponseSizeLimit
+}
+func (p *Policy) subscriptionLimit() int {
+	if p == nil {
+		return 0
+	}
+	return p.config.SubscriptionLimit
+}
+
+// matchMethod checks if method is the method named by pattern or is part of the namespace.* pattern
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, serviceMethodSeparator+"*") {
+		namespace := strings.TrimSuffix(pattern, "*")
+		return strings.HasPrefix(method, namespace) && !strings.Contains(method[len(namespace):], serviceMethodSeparator)
+	}
+	return pattern == method
+}
+
+// limiter is a token bucket limiter for each key, which allows rate calls per second with bursts of up to burst calls
+type limiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	return &limiter{
+		rate:      rate,
+		burst:     math.Max(float64(burst), math.Max(1, math.Ceil(rate))),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *limiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > limiterSweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens -= 1
+	return true
+}
+
+// sweep drops the buckets which are full again, since they behave the same as new buckets
+func (l *limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,175 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/server"
+)
+
+type policyTestService struct{}
+
+func (s *policyTestService) Echo(text string) string {
+	return text
+}
+func (s *policyTestService) Publish() bool {
+	return true
+}
+
+func newPolicyTestClient(t *testing.T, config server.PolicyConfig, headers http.Header) (*server.Client, func()) {
+	srv := server.NewServer()
+	srv.SetPolicy(server.NewPolicy(config))
+	common.FailIfErr(t, srv.RegisterName("test", new(policyTestService)))
+	httpSrv := httptest.NewServer(srv)
+
+	client, err := server.DialHTTP(httpSrv.URL)
+	common.FailIfErr(t, err)
+	for key, values := range headers {
+		client.SetHeader(key, values[0])
+	}
+	return client, func() {
+		client.Close()
+		httpSrv.Close()
+		srv.Stop()
+	}
+}
+
+func callError(client *server.Client, method string, args ...interface{}) string {
+	var result interface{}
+	if err := client.Call(&result, method, args...); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func TestPolicy_Methods(t *testing.T) {
+	client, stop := newPolicyTestClient(t, server.PolicyConfig{
+		Allow: []string{"test.*"},
+		Deny:  []string{"test.publish"},
+	}, nil)
+	defer stop()
+
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "")
+	common.ExpectString(t, callError(client, "test.publish"), "the method test.publish is not allowed")
+	common.ExpectString(t, callError(client, "rpc.modules"), "the method rpc.modules is not allowed")
+}
+
+func TestPolicy_RateLimits(t *testing.T) {
+	client, stop := newPolicyTestClient(t, server.PolicyConfig{
+		RateLimit:        0.001,
+		RateBurst:        3,
+		MethodRateLimits: map[string]float64{"test.publish": 0.001},
+	}, nil)
+	defer stop()
+
+	// method limits use the same burst
+	common.ExpectString(t, callError(client, "test.publish"), "")
+	common.ExpectString(t, callError(client, "test.publish"), "")
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "")
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "rate limit exceeded")
+
+	keyClient, stopKey := newPolicyTestClient(t, server.PolicyConfig{
+		RateLimit: 0.001,
+		RateBurst: 1,
+		APIKeys:   map[string]float64{"secret": 1000},
+	}, http.Header{server.APIKeyHeader: []string{"secret"}})
+	defer stopKey()
+	for i := 0; i < 10; i += 1 {
+		common.ExpectString(t, callError(keyClient, "test.echo", "znn"), "")
+	}
+
+	badKeyClient, stopBadKey := newPolicyTestClient(t, server.PolicyConfig{
+		APIKeys: map[string]float64{"secret": 1000},
+	}, http.Header{server.APIKeyHeader: []string{"guess"}})
+	defer stopBadKey()
+	common.ExpectString(t, callError(badKeyClient, "test.echo", "znn"), "invalid API key")
+}
+
+func TestPolicy_APIKeyQuery(t *testing.T) {
+	srv := server.NewServer()
+	srv.SetPolicy(server.NewPolicy(server.PolicyConfig{
+		RateLimit: 0.001,
+		RateBurst: 1,
+		APIKeys:   map[string]float64{"secret": 1000},
+	}))
+	common.FailIfErr(t, srv.RegisterName("test", new(policyTestService)))
+	defer srv.Stop()
+	httpSrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	defer httpSrv.Close()
+
+	// the key in the query of the WebSocket URL replaces the limits of the client IP
+	wsClient, err := server.DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(httpSrv.URL, "http")+"?apiKey=secret", "")
+	common.FailIfErr(t, err)
+	defer wsClient.Close()
+	for i := 0; i < 10; i += 1 {
+		common.ExpectString(t, callError(wsClient, "test.echo", "znn"), "")
+	}
+
+	// the query of plain HTTP calls is ignored
+	keySrv := httptest.NewServer(srv)
+	defer keySrv.Close()
+	client, err := server.DialHTTP(keySrv.URL + "?apiKey=secret")
+	common.FailIfErr(t, err)
+	defer client.Close()
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "")
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "rate limit exceeded")
+}
+
+func TestPolicy_Sizes(t *testing.T) {
+	client, stop := newPolicyTestClient(t, server.PolicyConfig{
+		BatchLimit:        3,
+		ResponseSizeLimit: 20,
+	}, nil)
+	defer stop()
+
+	common.ExpectString(t, callError(client, "test.echo", strings.Repeat("z", 30)), "response too large, the limit is 20 bytes")
+
+	batch := make([]server.BatchElem, 4)
+	for i := range batch {
+		batch[i] = server.BatchElem{Method: "test.echo", Args: []interface{}{"znn"}, Result: new(string)}
+	}
+	common.FailIfErr(t, client.BatchCall(batch))
+	for _, elem := range batch {
+		common.ExpectString(t, elem.Error.Error(), "batch too large, the limit is 3 calls")
+	}
+
+	// the third result exceeds the limit
+	batch = batch[:3]
+	for i := range batch {
+		batch[i] = server.BatchElem{Method: "test.echo", Args: []interface{}{"znn-qsr"}, Result: new(string)}
+	}
+	common.FailIfErr(t, client.BatchCall(batch))
+	common.ExpectTrue(t, batch[0].Error == nil && batch[1].Error == nil)
+	common.ExpectString(t, batch[2].Error.Error(), "response too large, the limit is 20 bytes")
+}
+
+func TestPolicy_Private(t *testing.T) {
+	srv := server.NewServer()
+	srv.SetPolicy(server.NewPolicy(server.PolicyConfig{
+		Private:   []string{"test.publish"},
+		APIKeys:   map[string]float64{"secret": 1000},
+		AdminKeys: []string{"admin"},
+	}))
+	common.FailIfErr(t, srv.RegisterName("test", new(policyTestService)))
+	defer srv.Stop()
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	// clients connected from localhost need an admin key too
+	client, err := server.DialHTTP(httpSrv.URL)
+	common.FailIfErr(t, err)
+	defer client.Close()
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "")
+	common.ExpectString(t, callError(client, "test.publish"), "the method test.publish is only allowed for clients with an admin key")
+
+	client.SetHeader(server.APIKeyHeader, "secret")
+	common.ExpectString(t, callError(client, "test.publish"), "the method test.publish is only allowed for clients with an admin key")
+
+	client.SetHeader(server.APIKeyHeader, "admin")
+	common.ExpectString(t, callError(client, "test.publish"), "")
+	common.ExpectString(t, callError(client, "test.echo", "znn"), "")
+}
@@ -46,6 +46,7 @@ type Server struct {
 	idgen    func() ID
 	run      int32
 	codecs   mapset.Set
+	policy   *Policy
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -66,6 +67,11 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetPolicy restricts the calls served to the clients of the server. It must be called before serving any request.
+func (s *Server) SetPolicy(policy *Policy) {
+	s.policy = policy
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -83,7 +89,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(codec, s.idgen, &s.services, s.policy)
 	<-codec.closed()
 	c.Close()
 }
@@ -99,6 +105,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services)
 	h.allowSubscribe = false
+	h.policy = s.policy
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -52,6 +52,8 @@ type jsonWriter interface {
 	closed() <-chan interface{}
 	// RemoteAddr returns the peer address of the connection.
 	remoteAddr() string
+	// peerInfo identifies the client of the connection for the Policy of the server.
+	peerInfo() PeerInfo
 }
 
 type BlockNumber int64
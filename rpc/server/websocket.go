@@ -60,6 +60,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn)
+		codec.(*websocketCodec).setPeerInfo(newWebsocketPeerInfo(r))
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -0,0 +1,169 @@
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PackMethodJSON packs the call of method name with the arguments given as JSON values.
+// Integers are JSON numbers or decimal strings, bytes are 0x-prefixed hex strings,
+// addresses, token standards and hashes are strings in their usual text form and arrays are JSON arrays.
+func (abi ABIContract) PackMethodJSON(name string, args []json.RawMessage) ([]byte, error) {
+	method, exist := abi.Methods[name]
+	if !exist {
+		return nil, errMethodNotFound(name)
+	}
+	values, err := method.Inputs.decodeJSON(args)
+	if err != nil {
+		return nil, err
+	}
+	return abi.PackMethod(name, values...)
+}
+
+// UnpackMethodJSON is the inverse of PackMethodJSON: it decodes the method called by data and its arguments, by name,
+// as JSON values. Integers of 64 bits or more are decimal strings.
+func (abi ABIContract) UnpackMethodJSON(data []byte) (*Method, map[string]interface{}, error) {
+	method, err := abi.MethodById(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return nil, nil, err
+	}
+	args := make(map[string]interface{}, len(values))
+	for i, argument := range method.Inputs {
+		args[argument.Name] = argument.Type.encodeJSON(reflect.ValueOf(values[i]))
+	}
+	return method, args, nil
+}
+
+func (arguments Arguments) decodeJSON(args []json.RawMessage) ([]interface{}, error) {
+	if len(args) != len(arguments) {
+		return nil, fmt.Errorf("argument count mismatch: %d for %d", len(args), len(arguments))
+	}
+	values := make([]interface{}, len(args))
+	for i, argument := range arguments {
+		value, err := argument.Type.decodeJSON(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("abi: invalid value for argument '%s': %v", argument.Name, err)
+		}
+		values[i] = value.Interface()
+	}
+	return values, nil
+}
+
+func (t Type) decodeJSON(raw json.RawMessage) (reflect.Value, error) {
+	switch t.T {
+	case IntTy, UintTy:
+		return t.decodeJSONNumber(raw)
+	case BytesTy, FixedBytesTy:
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return reflect.Value{}, err
+		}
+		bytes, err := hexutil.Decode(text)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if t.T == BytesTy {
+			return reflect.ValueOf(bytes), nil
+		}
+		if len(bytes) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes but got %d", t.Size, len(bytes))
+		}
+		value := reflect.New(t.Type).Elem()
+		reflect.Copy(value, reflect.ValueOf(bytes))
+		return value, nil
+	case SliceTy, ArrayTy:
+		var elements []json.RawMessage
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return reflect.Value{}, err
+		}
+		var value reflect.Value
+		if t.T == SliceTy {
+			value = reflect.MakeSlice(t.Type, len(elements), len(elements))
+		} else if len(elements) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expected %d elements but got %d", t.Size, len(elements))
+		} else {
+			value = reflect.New(t.Type).Elem()
+		}
+		for i, element := range elements {
+			elementValue, err := t.Elem.decodeJSON(element)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(elementValue)
+		}
+		return value, nil
+	default:
+		// bool, string, address, tokenStandard and hash
+		value := reflect.New(t.Type)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return value.Elem(), nil
+	}
+}
+func (t Type) decodeJSONNumber(raw json.RawMessage) (reflect.Value, error) {
+	text := strings.Trim(string(raw), `"`)
+	number, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("invalid number %v", string(raw))
+	}
+	if t.T == UintTy && number.Sign() < 0 {
+		return reflect.Value{}, fmt.Errorf("negative number %v for unsigned type %v", number, t)
+	}
+	if t.Type == bigT {
+		if number.BitLen() > 256 {
+			return reflect.Value{}, fmt.Errorf("number %v overflows %v", number, t)
+		}
+		return reflect.ValueOf(number), nil
+	}
+
+	value := reflect.New(t.Type).Elem()
+	if t.T == UintTy {
+		if !number.IsUint64() || value.OverflowUint(number.Uint64()) {
+			return reflect.Value{}, fmt.Errorf("number %v overflows %v", number, t)
+		}
+		value.SetUint(number.Uint64())
+	} else {
+		if !number.IsInt64() || value.OverflowInt(number.Int64()) {
+			return reflect.Value{}, fmt.Errorf("number %v overflows %v", number, t)
+		}
+		value.SetInt(number.Int64())
+	}
+	return value, nil
+}
+
+func (t Type) encodeJSON(value reflect.Value) interface{} {
+	switch t.T {
+	case IntTy, UintTy:
+		if t.Type == bigT {
+			return value.Interface().(*big.Int).String()
+		}
+		// JSON numbers lose precision above 2^53
+		if t.Size == 64 {
+			return fmt.Sprint(value.Interface())
+		}
+		return value.Interface()
+	case BytesTy, FixedBytesTy:
+		bytes := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(bytes), value)
+		return hexutil.Encode(bytes)
+	case SliceTy, ArrayTy:
+		elements := make([]interface{}, value.Len())
+		for i := range elements {
+			elements[i] = t.Elem.encodeJSON(value.Index(i))
+		}
+		return elements
+	default:
+		// bool, string, address, tokenStandard and hash
+		return value.Interface()
+	}
+}
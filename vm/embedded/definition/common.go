@@ -447,6 +447,46 @@ func GetRewardDepositHistory(context db.DB, epoch uint64, address *types.Address
 	}
 }
 
+// GetRewardDepositHistoryTotals returns, for each epoch in [from, to], the sum of the rewards deposited for all addresses.
+// Epochs without deposits are missing from the result.
+func GetRewardDepositHistoryTotals(context db.DB, from, to uint64) (map[uint64]*RewardDepositHistory, error) {
+	iterator := context.NewIterator(rewardDepositHistoryKeyPrefix)
+	defer iterator.Release()
+	totals := make(map[uint64]*RewardDepositHistory)
+
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		entry, err := parseRewardDepositHistoryEntry(iterator.Key(), iterator.Value())
+		if err == constants.ErrDataNonExistent {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Epoch < from || entry.Epoch > to {
+			continue
+		}
+
+		total, ok := totals[entry.Epoch]
+		if !ok {
+			total = &RewardDepositHistory{
+				Epoch: entry.Epoch,
+				Znn:   big.NewInt(0),
+				Qsr:   big.NewInt(0),
+			}
+			totals[entry.Epoch] = total
+		}
+		total.Znn.Add(total.Znn, entry.Znn)
+		total.Qsr.Add(total.Qsr, entry.Qsr)
+	}
+	return totals, nil
+}
+
 type PillarVote struct {
 	Id   types.Hash `json:"id"`
 	Name string     `json:"name"`
@@ -117,7 +117,16 @@ type UnlockHtlcParam struct {
 }
 
 func (h *HtlcInfo) Save(context db.DB) error {
-	data, err := ABIHtlc.PackVariable(
+	data, err := h.Serialize()
+	if err != nil {
+		return err
+	}
+	return context.Put(getHtlcInfoKey(h.Id), data)
+}
+
+// Serialize packs the htlc info, without the id, the same way it's kept in the contract storage
+func (h *HtlcInfo) Serialize() ([]byte, error) {
+	return ABIHtlc.PackVariable(
 		variableNameHtlcInfo,
 		h.TimeLocked,
 		h.HashLocked,
@@ -128,10 +137,16 @@ func (h *HtlcInfo) Save(context db.DB) error {
 		h.KeyMaxSize,
 		h.HashLock,
 	)
-	if err != nil {
-		return err
+}
+
+// DeserializeHtlcInfo unpacks the htlc info packed by Serialize
+func DeserializeHtlcInfo(id types.Hash, data []byte) (*HtlcInfo, error) {
+	info := new(HtlcInfo)
+	if err := ABIHtlc.UnpackVariable(info, variableNameHtlcInfo, data); err != nil {
+		return nil, err
 	}
-	return context.Put(getHtlcInfoKey(h.Id), data)
+	info.Id = id
+	return info, nil
 }
 func (h *HtlcInfo) Delete(context db.DB) error {
 	return context.Delete(getHtlcInfoKey(h.Id))
@@ -182,6 +197,32 @@ func GetHtlcInfo(context db.DB, id types.Hash) (*HtlcInfo, error) {
 	}
 }
 
+// GetAllHtlcInfo returns the htlcs which were neither unlocked nor reclaimed yet
+func GetAllHtlcInfo(context db.DB) ([]*HtlcInfo, error) {
+	iterator := context.NewIterator(htlcInfoKeyPrefix)
+	defer iterator.Release()
+
+	list := make([]*HtlcInfo, 0)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		// the hash lock is unpacked as a slice of the value, which is reused by the iterator
+		value := append([]byte{}, iterator.Value()...)
+		if info, err := parseHtlcInfo(iterator.Key(), value); err == nil {
+			list = append(list, info)
+		} else if err == constants.ErrDataNonExistent {
+			continue
+		} else {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
 type HtlcInfoMarshal struct {
 	Id             types.Hash               `json:"id"`
 	TimeLocked     types.Address            `json:"timeLocked"`
@@ -36,6 +36,10 @@ var (
 	acceleratorEmbedded        = getAccelerator()
 	htlcEmbedded               = getHtlc()
 	bridgeAndLiquidityEmbedded = getBridgeAndLiquidity()
+
+	// latestEmbedded are the embedded contracts with the methods added by all the sporks. It has to be updated
+	// together with getEmbeddedContracts when a spork adds methods
+	latestEmbedded = htlcEmbedded
 )
 
 func getHtlc() map[types.Address]*embeddedImplementation {
@@ -206,6 +210,35 @@ func getOrigin() map[types.Address]*embeddedImplementation {
 	}
 }
 
+// getEmbeddedContracts returns the embedded contracts, with the methods which can be called given the enforced sporks
+func getEmbeddedContracts(context vm_context.AccountVmContext) map[types.Address]*embeddedImplementation {
+	if context.IsHtlcSporkEnforced() {
+		return htlcEmbedded
+	} else if context.IsBridgeAndLiquiditySporkEnforced() {
+		return bridgeAndLiquidityEmbedded
+	} else if context.IsAcceleratorSporkEnforced() {
+		return acceleratorEmbedded
+	} else {
+		return originEmbedded
+	}
+}
+
+// GetEmbeddedContracts returns the ABI of each embedded contract, restricted to the methods which can be called given the
+// sporks enforced in context. The variables of the contracts are left out.
+func GetEmbeddedContracts(context vm_context.AccountVmContext) map[types.Address]*abi.ABIContract {
+	contracts := make(map[types.Address]*abi.ABIContract)
+	for address, p := range getEmbeddedContracts(context) {
+		contractAbi := &abi.ABIContract{Methods: make(map[string]abi.Method, len(p.m))}
+		for name := range p.m {
+			if method, ok := p.abi.Methods[name]; ok {
+				contractAbi.Methods[name] = method
+			}
+		}
+		contracts[address] = contractAbi
+	}
+	return contracts
+}
+
 // GetEmbeddedMethod finds method instance of embedded contract by address and abiSelector
 // - returns constants.ErrNotContractAddress in case address is not an embedded address (bad prefix)
 // - returns constants.ErrContractDoesntExist in case the address doesn't link to a valid embedded contract
@@ -215,20 +248,8 @@ func GetEmbeddedMethod(context vm_context.AccountVmContext, address types.Addres
 		return nil, constants.ErrNotContractAddress
 	}
 
-	var contractsMap map[types.Address]*embeddedImplementation
-
-	if context.IsHtlcSporkEnforced() {
-		contractsMap = htlcEmbedded
-	} else if context.IsBridgeAndLiquiditySporkEnforced() {
-		contractsMap = bridgeAndLiquidityEmbedded
-	} else if context.IsAcceleratorSporkEnforced() {
-		contractsMap = acceleratorEmbedded
-	} else {
-		contractsMap = originEmbedded
-	}
-
 	// contract address must exist in map
-	if p, found := contractsMap[address]; found {
+	if p, found := getEmbeddedContracts(context)[address]; found {
 		// contract must implement the method
 		if method, err := p.abi.MethodById(abiSelector); err == nil {
 			// method must exist in the map
@@ -242,3 +263,78 @@ func GetEmbeddedMethod(context vm_context.AccountVmContext, address types.Addres
 		return nil, constants.ErrContractDoesntExist
 	}
 }
+
+// GetLatestEmbeddedMethod finds method instance of embedded contract by address and abiSelector, considering all the methods added by sporks
+// - returns the same errors as GetEmbeddedMethod
+func GetLatestEmbeddedMethod(address types.Address, abiSelector []byte) (Method, error) {
+	if !types.IsEmbeddedAddress(address) {
+		return nil, constants.ErrNotContractAddress
+	}
+	if p, found := latestEmbedded[address]; found {
+		if method, err := p.abi.MethodById(abiSelector); err == nil {
+			if c, ok := p.m[method.Name]; ok {
+				return c, nil
+			}
+		}
+		return nil, constants.ErrContractMethodNotFound
+	}
+	return nil, constants.ErrContractDoesntExist
+}
+
+// GetEmbeddedABI returns the ABI of the embedded contract found at address, including all the methods added by sporks
+// - returns constants.ErrNotContractAddress in case address is not an embedded address (bad prefix)
+// - returns constants.ErrContractDoesntExist in case the address doesn't link to a valid embedded contract
+func GetEmbeddedABI(address types.Address) (*abi.ABIContract, error) {
+	if !types.IsEmbeddedAddress(address) {
+		return nil, constants.ErrNotContractAddress
+	}
+	if p, found := latestEmbedded[address]; found {
+		return &p.abi, nil
+	}
+	return nil, constants.ErrContractDoesntExist
+}
+
+// DecodeEmbeddedCall decodes the method and the arguments of a call made to the embedded contract found at address
+func DecodeEmbeddedCall(address types.Address, data []byte) (*abi.Method, []interface{}, error) {
+	contractAbi, err := GetEmbeddedABI(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	method, err := contractAbi.MethodById(data)
+	if err != nil {
+		return nil, nil, constants.ErrContractMethodNotFound
+	}
+	args, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return nil, nil, constants.ErrUnpackError
+	}
+	return method, args, nil
+}
+
+// DecodedCall is the readable form of a call made to an embedded contract, with the arguments by name, as JSON values
+type DecodedCall struct {
+	Contract types.Address          `json:"contract"`
+	Method   string                 `json:"method"`
+	Args     map[string]interface{} `json:"args"`
+}
+
+// DecodeEmbeddedCallJSON decodes a call made to the embedded contract found at address, like DecodeEmbeddedCall,
+// with the arguments in the format accepted by abi.ABIContract.PackMethodJSON
+func DecodeEmbeddedCallJSON(address types.Address, data []byte) (*DecodedCall, error) {
+	contractAbi, err := GetEmbeddedABI(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := contractAbi.MethodById(data); err != nil {
+		return nil, constants.ErrContractMethodNotFound
+	}
+	method, args, err := contractAbi.UnpackMethodJSON(data)
+	if err != nil {
+		return nil, constants.ErrUnpackError
+	}
+	return &DecodedCall{
+		Contract: address,
+		Method:   method.Name,
+		Args:     args,
+	}, nil
+}
@@ -7,6 +7,9 @@ import (
 	"testing"
 
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	cabi "github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
 func TestDumpContractsABIMethods(t *testing.T) {
@@ -82,3 +85,22 @@ func TestDumpContractsABIMethods(t *testing.T) {
 {"address":"z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0", "name":"UpdateToken", "id":"2a3cf32c", "signature":"UpdateToken(tokenStandard,address,bool,bool)"}
 ]`)
 }
+
+func TestDecodeEmbeddedCall(t *testing.T) {
+	method, args, err := DecodeEmbeddedCall(types.PillarContract, cabi.ABIPillars.PackMethodPanic(cabi.DelegateMethodName, "TEST-pillar"))
+	common.FailIfErr(t, err)
+	common.Expect(t, method.Name, cabi.DelegateMethodName)
+	common.Expect(t, fmt.Sprint(args), "[TEST-pillar]")
+
+	// methods added by sporks are decoded as well
+	id := types.NewHash([]byte{1})
+	method, args, err = DecodeEmbeddedCall(types.HtlcContract, cabi.ABIHtlc.PackMethodPanic(cabi.UnlockHtlcMethodName, id, []byte{2}))
+	common.FailIfErr(t, err)
+	common.Expect(t, method.Name, cabi.UnlockHtlcMethodName)
+	common.Expect(t, args[0], id)
+
+	_, _, err = DecodeEmbeddedCall(types.PillarContract, []byte{})
+	common.ExpectError(t, err, constants.ErrContractMethodNotFound)
+	_, _, err = DecodeEmbeddedCall(types.Address{}, []byte{})
+	common.ExpectError(t, err, constants.ErrNotContractAddress)
+}
@@ -403,17 +403,7 @@ func computeDetailedPillarReward(context vm_context.AccountVmContext, epoch uint
 			continue
 		}
 
-		toGiveN := big.NewInt(0)
-		// toGive = (pillar.GiveBlockRewardPercentage * reward.BlockReward + pillar.GiveDelegateRewardPercentage * reward.DelegationReward) / 100
-		tmp := big.NewInt(int64(pillar.GiveBlockRewardPercentage))
-		tmp.Mul(tmp, reward.BlockReward)
-		toGiveN.Add(toGiveN, tmp)
-
-		tmp.SetInt64(int64(pillar.GiveDelegateRewardPercentage))
-		tmp.Mul(tmp, reward.DelegationReward)
-		toGiveN.Add(toGiveN, tmp)
-
-		toGiveN.Quo(toGiveN, common.Big100)
+		toGiveN := computePillarRewardToGive(reward, pillar.GiveBlockRewardPercentage, pillar.GiveDelegateRewardPercentage)
 		toGive[pillar.Name] = toGiveN
 
 		// rewards to pillar, total - toGive
@@ -485,9 +475,63 @@ func computeDetailedPillarReward(context vm_context.AccountVmContext, epoch uint
 	return nil
 }
 
+// part of the pillar reward which is given to the delegators
+func computePillarRewardToGive(reward *pillarEpochReward, giveBlockRewardPercentage, giveDelegateRewardPercentage uint8) *big.Int {
+	toGive := big.NewInt(0)
+	// toGive = (pillar.GiveBlockRewardPercentage * reward.BlockReward + pillar.GiveDelegateRewardPercentage * reward.DelegationReward) / 100
+	tmp := big.NewInt(int64(giveBlockRewardPercentage))
+	tmp.Mul(tmp, reward.BlockReward)
+	toGive.Add(toGive, tmp)
+
+	tmp.SetInt64(int64(giveDelegateRewardPercentage))
+	tmp.Mul(tmp, reward.DelegationReward)
+	toGive.Add(toGive, tmp)
+
+	toGive.Quo(toGive, common.Big100)
+	return toGive
+}
+
+// ComputePillarsShareForEpoch returns the ZNN deposited by the pillar contract for epoch to the reward addresses of the
+// pillars, as opposed to their delegators. histories are the pillar percentages saved by the contract for epoch.
+func ComputePillarsShareForEpoch(reader api.PillarReader, histories []*definition.PillarEpochHistory, epoch uint64) (*big.Int, error) {
+	pillarReward, err := computePillarsRewardForEpoch(reader, epoch)
+	if err != nil {
+		return nil, err
+	}
+	details, err := reader.GetPillarDelegationsByEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	share := big.NewInt(0)
+	for _, history := range histories {
+		reward, ok := pillarReward[history.Name]
+		if !ok {
+			continue
+		}
+		toGive := computePillarRewardToGive(reward, history.GiveBlockRewardPercentage, history.GiveDelegateRewardPercentage)
+		share.Add(share, reward.TotalReward)
+		share.Sub(share, toGive)
+
+		detail, ok := details[history.Name]
+		if !ok {
+			continue
+		}
+		backersAmount := big.NewInt(0)
+		for _, amount := range detail.Backers {
+			backersAmount.Add(backersAmount, amount)
+		}
+		// no weight, all rewards go to pillar reward address
+		if backersAmount.Sign() == 0 {
+			share.Add(share, toGive)
+		}
+	}
+	return share, nil
+}
+
 // raw reward for all pillars in one epoch
-func computePillarsRewardForEpoch(context vm_context.AccountVmContext, epoch uint64) (m map[string]*pillarEpochReward, err error) {
-	detailList, err := context.EpochStats(epoch)
+func computePillarsRewardForEpoch(reader api.PillarReader, epoch uint64) (m map[string]*pillarEpochReward, err error) {
+	detailList, err := reader.EpochStats(epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func TestAbi_rpc(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	abiApi := embedded.NewAbiApi(z)
+	ledgerApi := api.NewLedgerApi(z)
+	ledgerApi.DecodeBlockData = true
+
+	common.Json(abiApi.GetContract(types.PlasmaContract)).Equals(t, `
+{
+	"address": "z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp",
+	"abi": [
+		{
+			"type": "function",
+			"name": "CancelFuse",
+			"inputs": [
+				{
+					"name": "id",
+					"type": "hash"
+				}
+			],
+			"signature": "CancelFuse(hash)",
+			"selector": "0xf9ca9dc3"
+		},
+		{
+			"type": "function",
+			"name": "Fuse",
+			"inputs": [
+				{
+					"name": "address",
+					"type": "address"
+				}
+			],
+			"signature": "Fuse(address)",
+			"selector": "0x5ac942e8"
+		}
+	]
+}`)
+	_, err := abiApi.GetContract(types.HtlcContract)
+	common.ExpectError(t, err, constants.ErrContractDoesntExist)
+
+	z.InsertSendBlock(issue(g.User1.Address, "test.tok3n_na-m3", "TEST", "", big.NewInt(100), big.NewInt(1000), 1, true, true, false), nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	frontier, err := z.Chain().GetFrontierAccountStore(types.TokenContract).Frontier()
+	common.FailIfErr(t, err)
+	common.Json(ledgerApi.GetAccountBlockByHash(frontier.Hash, nil)).SubJson(&struct {
+		PairedAccountBlock struct {
+			DecodedData interface{} `json:"decodedData"`
+		} `json:"pairedAccountBlock"`
+		DecodedDescendants interface{} `json:"decodedDescendants"`
+	}{}).Equals(t, `
+{
+	"pairedAccountBlock": {
+		"decodedData": {
+			"args": {
+				"decimals": 1,
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": false,
+				"maxSupply": "1000",
+				"tokenDomain": "",
+				"tokenName": "test.tok3n_na-m3",
+				"tokenSymbol": "TEST",
+				"totalSupply": "100"
+			},
+			"contract": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+			"method": "IssueToken"
+		}
+	},
+	"decodedDescendants": [
+		null
+	]
+}`)
+	// the call can turn the decoding off
+	decode := false
+	common.Json(ledgerApi.GetAccountBlockByHash(frontier.Hash, &decode)).SubJson(&struct {
+		DecodedData        interface{} `json:"decodedData"`
+		DecodedDescendants interface{} `json:"decodedDescendants"`
+	}{}).Equals(t, `
+{
+	"decodedData": null,
+	"decodedDescendants": null
+}`)
+
+	common.Json(abiApi.DecodeCall(types.TokenContract, []byte{1, 2, 3, 4})).Error(t, constants.ErrContractMethodNotFound)
+	// 64 bit integers are strings
+	common.Json(abiApi.DecodeCall(types.StakeContract, definition.ABIStake.PackMethodPanic(definition.StakeMethodName, int64(constants.StakeTimeMaxSec)))).Equals(t, `
+{
+	"contract": "z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62",
+	"method": "Stake",
+	"args": {
+		"durationInSec": "43200"
+	}
+}`)
+}
+
+func TestAbi_rpcDecodePerCall(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	ledgerApi := api.NewLedgerApi(z)
+
+	z.InsertSendBlock(issue(g.User1.Address, "test.tok3n_na-m3", "TEST", "", big.NewInt(100), big.NewInt(1000), 1, true, true, false), nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	blocks, err := ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10, nil)
+	common.FailIfErr(t, err)
+	common.Json(blocks.List[len(blocks.List)-1].DecodedData, nil).Equals(t, `null`)
+
+	decode := true
+	blocks, err = ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10, &decode)
+	common.FailIfErr(t, err)
+	common.Json(blocks.List[len(blocks.List)-1].DecodedData, nil).SubJson(&struct {
+		Method string `json:"method"`
+	}{}).Equals(t, `
+{
+	"method": "IssueToken"
+}`)
+}
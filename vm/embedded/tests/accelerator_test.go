@@ -1977,7 +1977,7 @@ t=2001-09-09T03:47:10+0000 lvl=info msg="received donation" module=embedded cont
 }`)
 	z.InsertNewMomentum() // cemented send block
 	z.InsertNewMomentum() // cemented token-receive-block
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 5)).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 5, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -2264,7 +2264,7 @@ t=2001-09-09T03:47:10+0000 lvl=info msg="received donation" module=embedded cont
 }`)
 	z.InsertNewMomentum() // cemented send block
 	z.InsertNewMomentum() // cemented token-receive-block
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 5)).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 5, nil)).Equals(t, `
 {
 	"list": [],
 	"count": 0,
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// - test that the account-blocks confirmed by the removed momentums are removed with them
+// - test that stable momentums are only removed if forced
+// - test that the node inserts momentums on top of the new frontier momentum
+func TestAdmin_RollbackTo(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	adminApi := api.NewAdminApi(z)
+	ledgerApi := api.NewLedgerApi(z)
+
+	z.InsertMomentumsTo(5)
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertMomentumsTo(40)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 11900*g.Zexp)
+
+	_, err := adminApi.RollbackTo("5", false)
+	common.ExpectTrue(t, err != nil)
+	_, err = adminApi.RollbackTo("41", true)
+	common.ExpectString(t, err.Error(), "momentum 41 is not part of the chain")
+
+	result, err := adminApi.RollbackTo("20", false)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, result.Momentums, 20)
+	common.Json(adminApi.RollbackTo("5", true)).HideHashes().Equals(t, `
+{
+	"previous": {
+		"hash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+		"height": 20
+	},
+	"frontier": {
+		"hash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+		"height": 5
+	},
+	"momentums": 15
+}`)
+	common.Json(ledgerApi.GetAccountBlockByHash(send.Hash, nil)).Equals(t, `null`)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 12000*g.Zexp)
+
+	z.InsertMomentumsTo(10)
+	frontier, err := ledgerApi.GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, frontier.Height, 10)
+}
+
+// savePointLogs keeps only the logs of the consensus points, the rest of the consensus logs depend on the election cache
+func savePointLogs() *common.Expecter {
+	buffer := new(bytes.Buffer)
+	format := log15.LogfmtFormat()
+	common.ConsensusLogger.SetHandler(log15.MatchFilterHandler("submodule", "points", log15.StreamHandler(buffer, log15.FormatFunc(func(r *log15.Record) []byte {
+		r.Time = common.Clock.Now()
+		return format.Format(r)
+	}))))
+	return common.LateCaller(func() (string, error) {
+		return buffer.String(), nil
+	})
+}
+
+// - test that a fork which crosses an epoch boundary discards the points of the removed momentums
+// - test that the points of the epoch are computed again from the momentums of the new branch
+func TestAdmin_RollbackAcrossEpoch(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	adminApi := api.NewAdminApi(z)
+
+	z.InsertMomentumsTo(370)
+	common.Json(z.Consensus().FrontierPillarReader().EpochStats(0)).Equals(t, `
+{
+	"epoch": 0,
+	"pillars": {
+		"TEST-pillar-1": {
+			"epoch": 0,
+			"blockNum": 119,
+			"exceptedBlockNum": 120,
+			"weight": 2100000000000,
+			"name": "TEST-pillar-1"
+		},
+		"TEST-pillar-cool": {
+			"epoch": 0,
+			"blockNum": 120,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-cool"
+		},
+		"TEST-pillar-znn": {
+			"epoch": 0,
+			"blockNum": 120,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-znn"
+		}
+	},
+	"totalWeight": 2500000000000,
+	"totalBlocks": 359
+}`)
+
+	logs := savePointLogs()
+	_, err := adminApi.RollbackTo("355", true)
+	common.FailIfErr(t, err)
+	common.Json(z.Consensus().FrontierPillarReader().EpochStats(0)).Equals(t, `
+{
+	"epoch": 0,
+	"pillars": {
+		"TEST-pillar-1": {
+			"epoch": 0,
+			"blockNum": 116,
+			"exceptedBlockNum": 120,
+			"weight": 2100000000000,
+			"name": "TEST-pillar-1"
+		},
+		"TEST-pillar-cool": {
+			"epoch": 0,
+			"blockNum": 118,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-cool"
+		},
+		"TEST-pillar-znn": {
+			"epoch": 0,
+			"blockNum": 120,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-znn"
+		}
+	},
+	"totalWeight": 2500000000000,
+	"totalBlocks": 354
+}`)
+
+	// the new branch misses the last slots of the epoch
+	z.SkipMomentumSlots(5)
+	z.InsertMomentumsTo(370)
+	common.Json(z.Consensus().FrontierPillarReader().EpochStats(0)).Equals(t, `
+{
+	"epoch": 0,
+	"pillars": {
+		"TEST-pillar-1": {
+			"epoch": 0,
+			"blockNum": 116,
+			"exceptedBlockNum": 120,
+			"weight": 2100000000000,
+			"name": "TEST-pillar-1"
+		},
+		"TEST-pillar-cool": {
+			"epoch": 0,
+			"blockNum": 118,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-cool"
+		},
+		"TEST-pillar-znn": {
+			"epoch": 0,
+			"blockNum": 120,
+			"exceptedBlockNum": 120,
+			"weight": 200000000000,
+			"name": "TEST-pillar-znn"
+		}
+	},
+	"totalWeight": 2500000000000,
+	"totalBlocks": 354
+}`)
+	logs.Equals(t, `
+t=2001-09-09T02:46:20+0000 lvl=dbug msg="delete period point" module=consensus submodule=points tick=11
+t=2001-09-09T02:46:20+0000 lvl=info msg="delete epoch point" module=consensus submodule=points tick=0
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="create period point" module=consensus submodule=points tick=11
+t=2001-09-09T02:46:40+0000 lvl=info msg="create epoch point" module=consensus submodule=points tick=0
+`)
+}
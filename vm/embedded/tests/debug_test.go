@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// - test that the contract receive-blocks confirmed by the same momentum are replayed on top of each other
+// - test that the error returned by the embedded method is available
+func TestDebug_Trace(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	debugApi := api.NewDebugApi(z)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.SentinelContract,
+		Data:          definition.ABISentinel.PackMethodPanic(definition.DepositQsrMethodName),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	failed := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User2.Address,
+		ToAddress:     types.SentinelContract,
+		Data:          definition.ABISentinel.PackMethodPanic(definition.RegisterSentinelMethodName),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        constants.SentinelZnnRegisterAmount,
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(debugApi.TraceMomentumByHeight(3)).HideHashes().Equals(t, `
+{
+	"hash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+	"height": 3,
+	"blocks": [
+		{
+			"blockHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"address": "z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r",
+			"call": {
+				"contract": "z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r",
+				"method": "DepositQsr",
+				"args": {}
+			},
+			"changes": [
+				{
+					"key": "0x0304066318c6318c6318c6",
+					"before": null,
+					"after": "0xXXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+				},
+				{
+					"key": "0x048200bbfd629028e53999aa179ac6de460ef72aa76e",
+					"before": null,
+					"after": "0xXXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+				},
+				{
+					"key": "0x07",
+					"before": null,
+					"after": "0x0000000000000001"
+				}
+			],
+			"balanceChanges": {
+				"zts1qsrxxxxxxxxxxxxxmrhjll": "1000000000"
+			},
+			"descendantBlocks": [],
+			"returnedError": "",
+			"error": ""
+		},
+		{
+			"blockHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"address": "z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r",
+			"call": {
+				"contract": "z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r",
+				"method": "Register",
+				"args": {}
+			},
+			"changes": [
+				{
+					"key": "0x0314e66318c6318c6318c6",
+					"before": null,
+					"after": "0xXXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+				},
+				{
+					"key": "0x07",
+					"before": "0x0000000000000001",
+					"after": "0x0000000000000002"
+				}
+			],
+			"balanceChanges": {},
+			"descendantBlocks": [
+				{
+					"version": 1,
+					"chainIdentifier": 100,
+					"blockType": 4,
+					"hash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+					"previousHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+					"height": 2,
+					"momentumAcknowledged": {
+						"hash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+						"height": 2
+					},
+					"address": "z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r",
+					"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+					"amount": "500000000000",
+					"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+					"fromBlockHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+					"descendantBlocks": [],
+					"data": null,
+					"fusedPlasma": 0,
+					"difficulty": 0,
+					"nonce": "0000000000000000",
+					"basePlasma": 0,
+					"usedPlasma": 0,
+					"changesHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+					"publicKey": null,
+					"signature": null
+				}
+			],
+			"returnedError": "not enough deposited Qsr",
+			"error": ""
+		}
+	]
+}`)
+
+	receive, err := z.Chain().GetFrontierMomentumStore().GetBlockWhichReceives(failed.Hash)
+	common.FailIfErr(t, err)
+	common.Json(debugApi.TraceAccountBlockByHash(receive.Hash)).SubJson(&struct {
+		ReturnedError string `json:"returnedError"`
+		Error         string `json:"error"`
+	}{}).Equals(t, `
+{
+	"returnedError": "not enough deposited Qsr",
+	"error": ""
+}`)
+
+	_, err = debugApi.TraceAccountBlockByHash(receive.DescendantBlocks[0].Hash)
+	common.ExpectError(t, err, vm.ErrTraceContractSend)
+}
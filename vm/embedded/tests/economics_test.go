@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func TestEconomics_rpc(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	economicsApi := embedded.NewEconomicsApi(z)
+	defer z.StopPanic()
+
+	common.Json(economicsApi.GetRewardsByEpoch(0, 10)).Equals(t, `
+{
+	"lastEpochs": {
+		"liquidity": -1,
+		"pillar": -1,
+		"sentinel": -1,
+		"stake": -1
+	},
+	"list": []
+}`)
+	z.InsertMomentumsTo(400)
+
+	common.Json(economicsApi.GetSupply()).Equals(t, `
+{
+	"znn": {
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"totalSupply": "19687200000000",
+		"maxSupply": "4611686018427387903",
+		"circulating": "15000000000000",
+		"locked": {
+			"bridge": "0",
+			"htlc": "0",
+			"liquidity": "187200000000",
+			"otherEmbedded": "0",
+			"pillar": "4500000000000",
+			"plasma": "0",
+			"sentinel": "0",
+			"stake": "0"
+		}
+	},
+	"qsr": {
+		"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+		"totalSupply": "181050000000000",
+		"maxSupply": "4611686018427387903",
+		"circulating": "166550000000000",
+		"locked": {
+			"bridge": "0",
+			"htlc": "0",
+			"liquidity": "500000000000",
+			"otherEmbedded": "0",
+			"pillar": "0",
+			"plasma": "14000000000000",
+			"sentinel": "0",
+			"stake": "0"
+		}
+	},
+	"momentumHeight": 400
+}`)
+	common.Json(economicsApi.GetRewardsByEpoch(0, 10)).Equals(t, `
+{
+	"lastEpochs": {
+		"liquidity": 0,
+		"pillar": 0,
+		"sentinel": 0,
+		"stake": 0
+	},
+	"list": [
+		{
+			"epoch": 0,
+			"emission": {
+				"znnAmount": "1440000000000",
+				"qsrAmount": "2000000000000"
+			},
+			"distributed": {
+				"znnAmount": "44215866547",
+				"qsrAmount": "0"
+			},
+			"pillars": {
+				"znnAmount": "29916666547",
+				"qsrAmount": "0"
+			},
+			"delegators": {
+				"znnAmount": "14299200000",
+				"qsrAmount": "0"
+			},
+			"sentinels": {
+				"znnAmount": "0",
+				"qsrAmount": "0"
+			},
+			"stakers": {
+				"znnAmount": "0",
+				"qsrAmount": "0"
+			},
+			"liquidity": {
+				"znnAmount": "0",
+				"qsrAmount": "0"
+			}
+		}
+	]
+}`)
+	common.Json(economicsApi.GetRewardsByEpoch(1, 10)).Equals(t, `
+{
+	"lastEpochs": {
+		"liquidity": 0,
+		"pillar": 0,
+		"sentinel": 0,
+		"stake": 0
+	},
+	"list": []
+}`)
+	common.Json(economicsApi.GetRewardsByEpoch(0, 101)).Error(t, api.ErrCountParamTooBig)
+}
@@ -3,7 +3,9 @@ package tests
 import (
 	"encoding/hex"
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
@@ -1452,3 +1454,234 @@ t=2001-09-09T01:50:50+0000 lvl=dbug msg="invalid unlock - wrong preimage" module
 	z.ExpectBalance(types.HtlcContract, types.QsrTokenStandard, 0*g.Zexp)
 
 }
+
+func TestHtlc_listing(t *testing.T) {
+	z := mock.NewMockZenonWithHtlcIndex(t)
+	htlcApi := embedded.NewHtlcApi(z)
+	defer z.StopPanic()
+	activateHtlc(z)
+
+	// user 1 creates two htlcs for user 2
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                // hashlocked
+			int64(genesisTimestamp+300),    // expiration time
+			uint8(definition.HashTypeSHA3), // hash type
+			uint8(32),                      // max preimage size
+			crypto.Hash(preimageZ),         // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                // hashlocked
+			int64(genesisTimestamp+250),    // expiration time
+			uint8(definition.HashTypeSHA3), // hash type
+			uint8(32),                      // max preimage size
+			crypto.Hash(preimageQ),         // hashlock
+		),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	htlcStatus := func() interface{} {
+		return &struct {
+			Count int
+			List  []struct {
+				Id       types.Hash
+				Status   string
+				Preimage []byte
+			}
+		}{}
+	}
+	htlcZ := types.HexToHashPanic("7efdcca315f86cdb04e84113bfc5f003fa49c4b3f9b287cd3b4a08d8ccdf6ffc")
+	htlcQ := types.HexToHashPanic("ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e")
+
+	common.Json(htlcApi.GetByTimeLockedAddress(g.User1.Address, 0, 10)).SubJson(htlcStatus()).Equals(t, `
+{
+	"Count": 2,
+	"List": [
+		{
+			"Id": "7efdcca315f86cdb04e84113bfc5f003fa49c4b3f9b287cd3b4a08d8ccdf6ffc",
+			"Status": "active",
+			"Preimage": null
+		},
+		{
+			"Id": "ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e",
+			"Status": "active",
+			"Preimage": null
+		}
+	]
+}`)
+	common.Json(htlcApi.GetByTimeLockedAddress(g.User2.Address, 0, 10)).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+
+	// user 2 unlocks the first htlc
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			htlcZ,     // entry id
+			preimageZ, // preimage
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+	}).Error(t, nil)
+	z.InsertMomentumsTo(40)
+
+	common.Json(htlcApi.GetByHashLockedAddress(g.User2.Address, 0, 10)).SubJson(htlcStatus()).Equals(t, `
+{
+	"Count": 2,
+	"List": [
+		{
+			"Id": "7efdcca315f86cdb04e84113bfc5f003fa49c4b3f9b287cd3b4a08d8ccdf6ffc",
+			"Status": "unlocked",
+			"Preimage": "t4Ra3NQe7E5Pocx1qGgBSBG1dZQsbkpyVRvAH2NwVjQ="
+		},
+		{
+			"Id": "ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e",
+			"Status": "expired",
+			"Preimage": null
+		}
+	]
+}`)
+
+	// user 1 reclaims the second htlc
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.ReclaimHtlcMethodName,
+			htlcQ, // entry id
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(htlcApi.GetByHashLock(crypto.Hash(preimageQ), 0, 10)).SubJson(htlcStatus()).Equals(t, `
+{
+	"Count": 1,
+	"List": [
+		{
+			"Id": "ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e",
+			"Status": "reclaimed",
+			"Preimage": null
+		}
+	]
+}`)
+	common.Json(htlcApi.GetByTimeLockedAddress(g.User1.Address, 1, 1)).SubJson(htlcStatus()).Equals(t, `
+{
+	"Count": 2,
+	"List": [
+		{
+			"Id": "ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e",
+			"Status": "reclaimed",
+			"Preimage": null
+		}
+	]
+}`)
+
+	// the reclaim is rollbacked
+	momentum, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(40)
+	common.FailIfErr(t, err)
+	insert := z.Chain().AcquireInsert("test")
+	common.FailIfErr(t, z.Chain().RollbackTo(insert, momentum.Identifier()))
+	insert.Unlock()
+
+	common.Json(htlcApi.GetByHashLock(crypto.Hash(preimageQ), 0, 10)).SubJson(htlcStatus()).Equals(t, `
+{
+	"Count": 1,
+	"List": [
+		{
+			"Id": "ba1f33abec5522269218d3c663bd56f733fcf6daa1dfc28b98e8139f6ab7a77e",
+			"Status": "expired",
+			"Preimage": null
+		}
+	]
+}`)
+}
+
+// - a momentum which doesn't follow the frontier of the htlc index is reported as a failure
+// - the index catches up with the chain again and indexes the next momentums
+func TestHtlc_indexGap(t *testing.T) {
+	z := mock.NewMockZenonWithHtlcIndex(t)
+	htlcApi := embedded.NewHtlcApi(z)
+	defer z.StopPanic()
+	activateHtlc(z)
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                // hashlocked
+			int64(genesisTimestamp+300),    // expiration time
+			uint8(definition.HashTypeSHA3), // hash type
+			uint8(32),                      // max preimage size
+			crypto.Hash(preimageZ),         // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	momentumStore := z.Chain().GetFrontierMomentumStore()
+	momentum, err := momentumStore.GetMomentumByHeight(2)
+	common.FailIfErr(t, err)
+	detailed, err := momentumStore.PrefetchMomentum(momentum)
+	common.FailIfErr(t, err)
+
+	// the index catches up while holding the insert lock
+	insert := z.Chain().AcquireInsert("test")
+	z.HtlcIndex().InsertMomentum(detailed)
+	_, err = htlcApi.GetByHashLock(crypto.Hash(preimageZ), 0, 10)
+	common.ExpectTrue(t, strings.HasPrefix(err.Error(), "htlc index can't catch up with the chain: momentum"))
+	insert.Unlock()
+
+	for start := time.Now(); z.HtlcIndex().Failure() != nil; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("htlc index didn't catch up: %v", z.HtlcIndex().Failure())
+		}
+	}
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			types.HexToHashPanic("7efdcca315f86cdb04e84113bfc5f003fa49c4b3f9b287cd3b4a08d8ccdf6ffc"), // entry id
+			preimageZ, // preimage
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(htlcApi.GetByHashLock(crypto.Hash(preimageZ), 0, 10)).SubJson(&struct {
+		Count int
+		List  []struct {
+			Id     types.Hash
+			Status string
+		}
+	}{}).Equals(t, `
+{
+	"Count": 1,
+	"List": [
+		{
+			"Id": "7efdcca315f86cdb04e84113bfc5f003fa49c4b3f9b287cd3b4a08d8ccdf6ffc",
+			"Status": "unlocked"
+		}
+	]
+}`)
+}
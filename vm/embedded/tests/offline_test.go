@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/wallet/offline"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// newOfflineDescription fills in the frontier of User1 and the acknowledged momentum,
+// which are fetched from a node before going offline
+func newOfflineDescription(t *testing.T, z mock.MockZenon) *offline.Description {
+	ledgerApi := api.NewLedgerApi(z)
+	frontier, err := ledgerApi.GetFrontierAccountBlock(g.User1.Address, nil)
+	common.FailIfErr(t, err)
+	momentum, err := ledgerApi.GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	return &offline.Description{
+		ChainIdentifier:      z.Chain().ChainIdentifier(),
+		PreviousHash:         frontier.Hash,
+		Height:               frontier.Height + 1,
+		MomentumAcknowledged: momentum.Identifier(),
+	}
+}
+
+// publishOffline publishes the block through the JSON accepted by ledger.publishRawTransaction
+func publishOffline(t *testing.T, z mock.MockZenon, block *nom.AccountBlock) {
+	data, err := offline.Marshal(block)
+	common.FailIfErr(t, err)
+	raw := new(api.AccountBlock)
+	common.FailIfErr(t, json.Unmarshal(data, raw))
+	common.FailIfErr(t, api.NewLedgerApi(z).PublishRawTransaction(raw))
+	z.InsertNewMomentum()
+}
+
+func TestOffline_EmbeddedCall(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(5)
+
+	description := newOfflineDescription(t, z)
+	description.ToAddress = types.PillarContract
+	description.TokenStandard = types.ZnnTokenStandard
+	description.Method = definition.DelegateMethodName
+	description.Params = []json.RawMessage{json.RawMessage(`"` + g.Pillar1Name + `"`)}
+	// one plasma short, so a small PoW is required
+	description.FusedPlasma = constants.EmbeddedSimplePlasma - 1
+	description.PoW = true
+
+	block, err := offline.Build(description, g.User1)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, block.Difficulty, constants.PoWDifficultyPerPlasma)
+	common.ExpectBytes(t, block.Data, hexutil.Encode(definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, g.Pillar1Name)))
+	publishOffline(t, z, block)
+
+	frontier, err := api.NewLedgerApi(z).GetFrontierAccountBlock(g.User1.Address, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, frontier.Hash, block.Hash)
+	z.InsertMomentumsTo(10)
+	common.Json(embedded.NewPillarApi(z, true).GetDelegatedPillar(g.User1.Address)).SubJson(&struct {
+		Name string `json:"name"`
+	}{}).Equals(t, `
+{
+	"name": "TEST-pillar-1"
+}`)
+}
+func TestOffline_Send(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(5)
+
+	description := newOfflineDescription(t, z)
+	common.FailIfErr(t, json.Unmarshal([]byte(`
+{
+	"toAddress": "`+g.User2.Address.String()+`",
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"amount": "100000000",
+	"fusedPlasma": 21000,
+	"pow": true
+}`), description))
+
+	block, err := offline.Build(description, g.User1)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, block.Difficulty, 0)
+	publishOffline(t, z, block)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 11999*g.Zexp)
+}
+func TestOffline_InvalidDescription(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	description := newOfflineDescription(t, z)
+	description.ToAddress = types.PillarContract
+	description.Method = definition.DelegateMethodName
+	description.Params = []json.RawMessage{json.RawMessage(`12`)}
+	_, err := offline.Build(description, g.User1)
+	common.ExpectString(t, err.Error(), "abi: invalid value for argument 'name': json: cannot unmarshal number into Go value of type string")
+
+	description.Method = "Unknown"
+	_, err = offline.Build(description, g.User1)
+	common.ExpectString(t, err.Error(), "method 'Unknown' not found")
+
+	description.ToAddress = g.User2.Address
+	_, err = offline.Build(description, g.User1)
+	common.ExpectString(t, err.Error(), "can't call method Unknown on "+g.User2.Address.String()+". Reason: "+constants.ErrNotContractAddress.Error())
+}
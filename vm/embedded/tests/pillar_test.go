@@ -729,3 +729,34 @@ t=2001-09-09T02:47:00+0000 lvl=info msg="received donation" module=embedded cont
 	]
 }`)
 }
+
+// - delegate User1 to Pillar2
+// - check pillar.getDelegatedPillarAt returns the delegation as of the requested momentum
+func TestPillar_GetDelegatedPillarAt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	pillarApi := embedded.NewPillarApi(z, false)
+	defer z.StopPanic()
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PillarContract,
+		Data:          definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, g.Pillar2Name),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        common.Big0,
+	}).Error(t, nil)
+	z.InsertNewMomentum() // include send block
+	z.InsertNewMomentum() // include contract receive block
+
+	common.Json(pillarApi.GetDelegatedPillarAt(g.User1.Address, 2)).Equals(t, `
+{
+	"name": "TEST-pillar-1",
+	"status": 1,
+	"weight": "1200000000000"
+}`)
+	common.Json(pillarApi.GetDelegatedPillarAt(g.User1.Address, 3)).Equals(t, `
+{
+	"name": "TEST-pillar-cool",
+	"status": 1,
+	"weight": "1200000000000"
+}`)
+}
@@ -140,7 +140,7 @@ func TestPlasma_combined(t *testing.T) {
 	}, constants.ErrNotEnoughPlasma, mock.NoVmChanges)
 
 	// get pow-hash to generate nonce from it
-	last, err := ledgerApi.GetFrontierAccountBlock(g.User6.Address)
+	last, err := ledgerApi.GetFrontierAccountBlock(g.User6.Address, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, pow.GetAccountBlockHash(&nom.AccountBlock{
 		Address:      g.User6.Address,
@@ -311,7 +311,7 @@ t=2001-09-09T01:46:50+0000 lvl=dbug msg="canceled fusion entry" module=embedded
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -619,3 +619,170 @@ t=2001-09-09T01:46:50+0000 lvl=dbug msg="fused new entry" module=embedded contra
 	}).Error(t, constants.ErrDataNonExistent)
 	z.InsertNewMomentum()
 }
+
+func TestPlasma_Estimate(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	plasmaApi := embedded.NewPlasmaApi(z)
+	defer z.StopPanic()
+
+	estimate := func(block *nom.AccountBlock) *embedded.PlasmaEstimate {
+		result, err := plasmaApi.GetEstimateForAccountBlock(&api.AccountBlock{AccountBlock: *block})
+		common.FailIfErr(t, err)
+		if result.HashRate == 0 {
+			t.Fatalf("expected a positive hash rate")
+		}
+		if (result.ExpectedPoWTime == 0) != (result.RequiredDifficulty == 0 || result.RequiredDifficulty*1000 < result.HashRate) {
+			t.Fatalf("unexpected PoW time %v for difficulty %v", result.ExpectedPoWTime, result.RequiredDifficulty)
+		}
+		result.HashRate = 0
+		result.ExpectedPoWTime = 0
+		return result
+	}
+	fuse := &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+	}
+
+	// covered by fusion, including the plasma used by an unconfirmed block
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(1 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	fuse.Address = g.User1.Address
+	common.Json(estimate(fuse), nil).Equals(t, `
+{
+	"basePlasma": 52500,
+	"availablePlasma": 10479000,
+	"pendingPlasma": 21000,
+	"fusionSufficient": true,
+	"powPossible": true,
+	"fusedPlasma": 52500,
+	"requiredDifficulty": 0,
+	"hashRate": 0,
+	"expectedPoWTime": 0
+}`)
+
+	// covered by PoW
+	fuse.Address = g.User6.Address
+	common.Json(estimate(fuse), nil).Equals(t, `
+{
+	"basePlasma": 52500,
+	"availablePlasma": 0,
+	"pendingPlasma": 0,
+	"fusionSufficient": false,
+	"powPossible": true,
+	"fusedPlasma": 0,
+	"requiredDifficulty": 78750000,
+	"hashRate": 0,
+	"expectedPoWTime": 0
+}`)
+
+	// the data is too big to be covered by PoW
+	common.Json(estimate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User6.Address,
+		ToAddress: g.User2.Address,
+		Data:      make([]byte, 2048),
+	}), nil).Equals(t, `
+{
+	"basePlasma": 160264,
+	"availablePlasma": 0,
+	"pendingPlasma": 0,
+	"fusionSufficient": false,
+	"powPossible": false,
+	"fusedPlasma": 0,
+	"requiredDifficulty": 0,
+	"hashRate": 0,
+	"expectedPoWTime": 0
+}`)
+
+	_, err := plasmaApi.GetEstimateForAccountBlock(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User6.Address,
+		ToAddress: types.PlasmaContract,
+		Data:      []byte{1, 2, 3, 4},
+	}})
+	common.ExpectError(t, err, constants.ErrContractMethodNotFound)
+}
+
+// - fuse plasma for User6
+// - check plasma.getAt and plasma.getEntriesByAddressAt return the plasma as of the requested momentum
+func TestPlasma_GetAt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	plasmaApi := embedded.NewPlasmaApi(z)
+	defer z.StopPanic()
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // include send block
+	z.InsertNewMomentum() // include contract receive block
+
+	common.Json(plasmaApi.GetAt(g.User6.Address, 2)).Equals(t, `
+{
+	"currentPlasma": 0,
+	"maxPlasma": 0,
+	"qsrAmount": "0"
+}`)
+	common.Json(plasmaApi.GetAt(g.User6.Address, 3)).Equals(t, `
+{
+	"currentPlasma": 21000,
+	"maxPlasma": 21000,
+	"qsrAmount": "1000000000"
+}`)
+	common.Json(plasmaApi.GetEntriesByAddressAt(g.User1.Address, 2, 0, 10)).Equals(t, `
+{
+	"qsrAmount": "2000000000000",
+	"count": 2,
+	"list": [
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qqfmjdays57w488sta69ykc2ey7r6d0q9wdvtj",
+			"expirationHeight": 0,
+			"id": "0000000000000000000000000000000000000000000000000000000000000000"
+		},
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"expirationHeight": 0,
+			"id": "117613e734b6cb0fd7b7583f5b0e863a3f0c856cd32fa36f1b60b464d068c5a6"
+		}
+	]
+}`)
+	common.Json(plasmaApi.GetEntriesByAddressAt(g.User1.Address, 3, 0, 10)).HideHashes().Equals(t, `
+{
+	"qsrAmount": "2001000000000",
+	"count": 3,
+	"list": [
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qqfmjdays57w488sta69ykc2ey7r6d0q9wdvtj",
+			"expirationHeight": 0,
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+		},
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"expirationHeight": 0,
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+		},
+		{
+			"qsrAmount": "1000000000",
+			"beneficiary": "z1qqdt06lnwz57x38rwlyutcx5wgrtl0ynkfe3kv",
+			"expirationHeight": 102,
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+		}
+	]
+}`)
+	common.Json(plasmaApi.GetAt(g.User6.Address, 4)).Error(t, api.ErrMomentumNotFound)
+	common.Json(plasmaApi.GetEntriesByAddressAt(g.User1.Address, 3, 0, 1234)).Error(t, api.ErrPageSizeParamTooBig)
+}
@@ -2,15 +2,18 @@ package tests
 
 import (
 	"encoding/json"
+	"math"
 	"math/big"
 	"testing"
 	"time"
 
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/index"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
 
@@ -21,7 +24,7 @@ func TestRPCLedger_PublishRawTX_internal(t *testing.T) {
 
 	simpleSendSetup(t, z)
 
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 2, 1)).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 2, 1, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -109,7 +112,7 @@ func TestRPCLedger_PublishRawTX_internal(t *testing.T) {
 	"count": 2,
 	"more": false
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User2.Address, 2, 1)).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User2.Address, 2, 1, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -233,14 +236,14 @@ func TestRPCLedger_PublishRawTransaction(t *testing.T) {
 
 func ExpectGetFrontierAccountBlock(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
-	common.Json(ledgerApi.GetFrontierAccountBlock(g.User1.Address)).SubJson(&Height{}).Equals(t, `
+	common.Json(ledgerApi.GetFrontierAccountBlock(g.User1.Address, nil)).SubJson(&Height{}).Equals(t, `
 {
 	"height": 11
 }`)
 }
 func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 3, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 3, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -252,7 +255,7 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -273,12 +276,12 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 20, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 20, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": []
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 10, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 10, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -294,22 +297,22 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 func ExpectGetAccountBlockByHash(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
-	blocks, err := ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10)
+	blocks, err := ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10, nil)
 	common.FailIfErr(t, err)
-	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[0].Hash)).SubJson(&Height{}).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[0].Hash, nil)).SubJson(&Height{}).Equals(t, `
 {
 	"height": 1
 }`)
-	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[5].Hash)).SubJson(&Height{}).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[5].Hash, nil)).SubJson(&Height{}).Equals(t, `
 {
 	"height": 6
 }`)
-	common.Json(ledgerApi.GetAccountBlockByHash(types.NewHash([]byte{'1'}))).SubJson(&Height{}).Equals(t, `null`)
+	common.Json(ledgerApi.GetAccountBlockByHash(types.NewHash([]byte{'1'}), nil)).SubJson(&Height{}).Equals(t, `null`)
 }
 func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 0, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 0, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -321,7 +324,7 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -333,7 +336,7 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 1, 8)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 1, 8, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -348,7 +351,7 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 8)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 8, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": []
@@ -401,7 +404,7 @@ func ExpectGetUnreceivedBlocksByAddress(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": [
@@ -428,7 +431,7 @@ func ExpectGetUnreceivedBlocksByAddress(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 1, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 1, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": [
@@ -443,13 +446,13 @@ func ExpectGetUnreceivedBlocksByAddress(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 2, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 2, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": []
 }`)
 	autoreceive(t, z, g.User2.Address)
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10)).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10, nil)).Equals(t, `
 {
 	"list": [],
 	"count": 0,
@@ -471,7 +474,7 @@ func TestRPCLedger_UnconfirmedAccountBlocks(t *testing.T) {
 		}, nil, mock.SkipVmChanges)
 	}
 
-	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 0, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 0, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": [
@@ -498,7 +501,7 @@ func TestRPCLedger_UnconfirmedAccountBlocks(t *testing.T) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 1, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 1, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": [
@@ -513,7 +516,7 @@ func TestRPCLedger_UnconfirmedAccountBlocks(t *testing.T) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 2, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 2, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 10,
 	"list": []
@@ -541,7 +544,7 @@ func TestRPCLedger_ConfirmedAccountBlocks(t *testing.T) {
 	}
 	z.InsertNewMomentum()
 
-	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 0, 7)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 0, 7, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 0,
 	"list": []
@@ -701,7 +704,7 @@ func TestRPCLedger_GetDetailedMomentumsByHeight(t *testing.T) {
 	defer z.StopPanic()
 	z.InsertMomentumsTo(10)
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 3)).SubJson(ListOf(func() interface{} {
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 3, nil)).SubJson(ListOf(func() interface{} {
 		return new(struct {
 			AccountBlocks *listToCount `json:"blocks"`
 			Momentum      *struct {
@@ -738,7 +741,467 @@ func TestRPCLedger_Errors(t *testing.T) {
 	ledgerApi := api.NewLedgerApi(z)
 	defer z.StopPanic()
 
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(0, 3)).Error(t, api.ErrHeightParamIsZero)
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 1234)).Error(t, api.ErrCountParamTooBig)
-	common.Json(ledgerApi.GetAccountBlocksByPage(types.ZeroAddress, 0, 1234)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(0, 3, nil)).Error(t, api.ErrHeightParamIsZero)
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 1234, nil)).Error(t, api.ErrCountParamTooBig)
+	common.Json(ledgerApi.GetAccountBlocksByPage(types.ZeroAddress, 0, 1234, nil)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetIndexedAccountBlocksByAddress(types.ZeroAddress, nil, "", 10, nil)).Error(t, api.ErrAddressIndexDisabled)
+}
+
+// - send 100 znn from user1 to user2
+// - check ledger.GetAccountInfoByAddressAt returns the balances as of the requested momentum
+func TestRPCLedger_GetAccountInfoByAddressAt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+
+	type balanceOnly struct {
+		AccountHeight  uint64 `json:"accountHeight"`
+		BalanceInfoMap map[string]struct {
+			Balance string `json:"balance"`
+		} `json:"balanceInfoMap"`
+	}
+
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User2.Address, 2)).SubJson(new(balanceOnly)).Equals(t, `
+{
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"balance": "8000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"balance": "800000000000"
+		}
+	}
+}`)
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User2.Address, 3)).SubJson(new(balanceOnly)).Equals(t, `
+{
+	"accountHeight": 2,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"balance": "8000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"balance": "810000000000"
+		}
+	}
+}`)
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User2.Address, 0)).Error(t, api.ErrHeightParamIsZero)
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User2.Address, 100)).Error(t, api.ErrMomentumNotFound)
+}
+
+// - check ledger.SimulateTransaction for a simple send, a successful embedded call and a failing embedded call
+// - check that nothing gets inserted
+func TestRPCLedger_SimulateTransaction(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	type simulationOnly struct {
+		BalanceChanges map[string]map[string]string `json:"balanceChanges"`
+		Error          string                       `json:"error"`
+		ContractError  string                       `json:"contractError"`
+	}
+
+	common.Json(ledgerApi.SimulateTransaction(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}})).SubJson(new(simulationOnly)).Equals(t, `
+{
+	"balanceChanges": {
+		"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz": {
+			"zts1znnxxxxxxxxxxxxx9z4ulx": "-10000000000"
+		}
+	},
+	"error": "",
+	"contractError": ""
+}`)
+	common.Json(ledgerApi.SimulateTransaction(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+	}})).SubJson(new(simulationOnly)).Equals(t, `
+{
+	"balanceChanges": {
+		"z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp": {
+			"zts1qsrxxxxxxxxxxxxxmrhjll": "1000000000"
+		},
+		"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz": {
+			"zts1qsrxxxxxxxxxxxxxmrhjll": "-1000000000"
+		}
+	},
+	"error": "",
+	"contractError": ""
+}`)
+	common.Json(ledgerApi.SimulateTransaction(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     types.PillarContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data:          definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, "missing-pillar"),
+	}})).SubJson(new(simulationOnly)).Equals(t, `
+{
+	"balanceChanges": {
+		"z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg": {},
+		"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz": {}
+	},
+	"error": "",
+	"contractError": "data non existent"
+}`)
+	common.Json(ledgerApi.SimulateTransaction(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(1000000 * g.Zexp),
+	}})).SubJson(new(simulationOnly)).Equals(t, `
+{
+	"balanceChanges": {},
+	"error": "insufficient balance for transfer",
+	"contractError": ""
+}`)
+
+	z.InsertNewMomentum()
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 12000*g.Zexp)
+	common.Json(ledgerApi.GetUnconfirmedBlocksByAddress(g.User1.Address, 0, 10, nil)).Equals(t, `
+{
+	"list": [],
+	"count": 0,
+	"more": false
+}`)
+}
+
+func TestRPCLedger_GetIndexedAccountBlocksByAddress(t *testing.T) {
+	z := mock.NewMockZenonWithAddressIndex(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	type indexedOnly struct {
+		List []struct {
+			BlockType     uint64 `json:"blockType"`
+			Address       string `json:"address"`
+			ToAddress     string `json:"toAddress"`
+			Amount        string `json:"amount"`
+			TokenStandard string `json:"tokenStandard"`
+		} `json:"list"`
+		Cursor        string `json:"cursor"`
+		IndexedHeight uint64 `json:"indexedHeight"`
+	}
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(20 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User1.Address),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(30 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	incoming := true
+	znn := types.ZnnTokenStandard
+	common.Json(ledgerApi.GetIndexedAccountBlocksByAddress(g.User2.Address, &index.Filter{
+		Incoming:      &incoming,
+		TokenStandard: &znn,
+	}, "", 10, nil)).SubJson(new(indexedOnly)).Equals(t, `
+{
+	"list": [
+		{
+			"blockType": 2,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"amount": "1000000000",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		},
+		{
+			"blockType": 2,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"amount": "3000000000",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		}
+	],
+	"cursor": "",
+	"indexedHeight": 4
+}`)
+	common.Json(ledgerApi.GetIndexedAccountBlocksByAddress(g.User2.Address, &index.Filter{
+		Incoming:   &incoming,
+		FromHeight: 3,
+		ToHeight:   math.MaxUint64,
+	}, "", 10, nil)).SubJson(new(indexedOnly)).Equals(t, `
+{
+	"list": [
+		{
+			"blockType": 2,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"amount": "3000000000",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		}
+	],
+	"cursor": "",
+	"indexedHeight": 4
+}`)
+	common.Json(ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, &index.Filter{
+		Methods: []string{definition.FuseMethodName},
+	}, "", 10, nil)).SubJson(new(indexedOnly)).Equals(t, `
+{
+	"list": [
+		{
+			"blockType": 2,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"toAddress": "z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp",
+			"amount": "1000000000",
+			"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll"
+		}
+	],
+	"cursor": "",
+	"indexedHeight": 4
+}`)
+
+	// pagination
+	page, err := ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, "", 2, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, len(page.List), 2)
+	hashes := []types.Hash{page.List[0].Hash, page.List[1].Hash}
+	for page.Cursor != "" {
+		page, err = ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, page.Cursor, 2, nil)
+		common.FailIfErr(t, err)
+		for _, block := range page.List {
+			hashes = append(hashes, block.Hash)
+		}
+	}
+	all, err := ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, "", 100, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, len(hashes), len(all.List))
+	for i := range hashes {
+		common.Expect(t, hashes[i], all.List[i].Hash)
+	}
+
+	_, err = ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, "invalid", 2, nil)
+	common.ExpectError(t, err, index.ErrInvalidCursor)
+	_, err = ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, page.Cursor, 0, nil)
+	common.ExpectError(t, err, api.ErrCountParamIsZero)
+}
+
+// - a momentum which doesn't follow the frontier of the address index is reported as a failure
+// - the index catches up with the chain again and indexes the next momentums
+func TestRPCLedger_AddressIndexGap(t *testing.T) {
+	z := mock.NewMockZenonWithAddressIndex(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(4)
+	before, err := ledgerApi.GetIndexedAccountBlocksByAddress(g.User2.Address, nil, "", 10, nil)
+	common.FailIfErr(t, err)
+	momentumStore := z.Chain().GetFrontierMomentumStore()
+	momentum, err := momentumStore.GetMomentumByHeight(2)
+	common.FailIfErr(t, err)
+	detailed, err := momentumStore.PrefetchMomentum(momentum)
+	common.FailIfErr(t, err)
+
+	// the index catches up while holding the insert lock
+	insert := z.Chain().AcquireInsert("test")
+	z.AddressIndex().InsertMomentum(detailed)
+	_, err = ledgerApi.GetIndexedAccountBlocksByAddress(g.User1.Address, nil, "", 10, nil)
+	common.ExpectString(t, common.HideHashes(err.Error()), "address index can't catch up with the chain: momentum {XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX 2} doesn't follow the index frontier &{XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX 4}")
+	insert.Unlock()
+
+	for start := time.Now(); z.AddressIndex().Failure() != nil; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("address index didn't catch up: %v", z.AddressIndex().Failure())
+		}
+	}
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	list, err := ledgerApi.GetIndexedAccountBlocksByAddress(g.User2.Address, nil, "", 10, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, list.IndexedHeight, 5)
+	common.Expect(t, len(list.List), len(before.List)+1)
+}
+
+// - enable pruning with a retention of 10 momentums
+// - user1 fuses qsr twice, the first send-block is received by the plasma contract
+// - insert momentums until the first send-block is older than the retention
+// - check that the received send-block is pruned while the frontier block of user1 is kept
+func TestRPCLedger_PrunedNode(t *testing.T) {
+	z := mock.NewMockZenonWithPruning(t, 10)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	fuse := func() *nom.AccountBlock {
+		return z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     types.PlasmaContract,
+			TokenStandard: types.QsrTokenStandard,
+			Amount:        big.NewInt(10 * g.Zexp),
+			Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User1.Address),
+		}, nil, mock.SkipVmChanges)
+	}
+	first := fuse()
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	second := fuse()
+	z.InsertNewMomentum()
+	z.InsertMomentumsTo(20)
+
+	common.Json(ledgerApi.GetAccountBlockByHash(first.Hash, nil)).Error(t, api.ErrDataPruned)
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User1.Address, 2)).Error(t, api.ErrStateNotAvailable)
+
+	type hashOnly struct {
+		Hash   types.Hash `json:"hash"`
+		Height uint64     `json:"height"`
+	}
+	common.Json(ledgerApi.GetAccountBlockByHash(second.Hash, nil)).SubJson(new(hashOnly)).Equals(t, `
+{
+	"hash": "`+second.Hash.String()+`",
+	"height": 3
+}`)
+	common.Json(ledgerApi.GetAccountInfoByAddressAt(g.User1.Address, 15)).SubJson(new(struct {
+		AccountHeight uint64 `json:"accountHeight"`
+	})).Equals(t, `
+{
+	"accountHeight": 3
+}`)
+}
+
+func TestRPCLedger_GetAllUnreceivedBlocksByAddress(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	for i := int64(1); i <= 3; i += 1 {
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User5.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(i * g.Zexp),
+		}, nil, mock.SkipVmChanges)
+	}
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User5.Address,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(5 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	unreceived := func() interface{} {
+		return &struct {
+			List []struct {
+				Hash          types.Hash
+				Amount        string
+				TokenStandard types.ZenonTokenStandard
+			}
+			Cursor string
+		}{}
+	}
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "", 3, nil)).SubJson(unreceived()).Equals(t, `
+{
+	"List": [
+		{
+			"Hash": "1423e4a30c0e86d3a7ff6f4497049c2750bc39c32062b661f888969a1f5ac66e",
+			"Amount": "500000000",
+			"TokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll"
+		},
+		{
+			"Hash": "6fb38afd577f9c700e21988e9d3151229f60770b3efd97de5fa757b36160af52",
+			"Amount": "300000000",
+			"TokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		},
+		{
+			"Hash": "8495a21d63e3e79235e9ec0a3815f74f979da51b4456b7f9e697eafa522e61cd",
+			"Amount": "100000000",
+			"TokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		}
+	],
+	"Cursor": "8495a21d63e3e79235e9ec0a3815f74f979da51b4456b7f9e697eafa522e61cd"
+}`)
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "8495a21d63e3e79235e9ec0a3815f74f979da51b4456b7f9e697eafa522e61cd", 3, nil)).SubJson(unreceived()).Equals(t, `
+{
+	"List": [
+		{
+			"Hash": "c943ef8d4da1e0a92b9aae54c8ea15e89707ca28f99c35e6d1aa8887f57eddfc",
+			"Amount": "200000000",
+			"TokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		}
+	],
+	"Cursor": ""
+}`)
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "", 0, nil)).Error(t, api.ErrCountParamIsZero)
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "8495a21d63e3e79235e9ec0a3815f74f979da51b4456b7f9e697eafa522e61cd", 3, nil)).SubJson(unreceived()).Equals(t, `
+{
+	"List": [
+		{
+			"Hash": "c943ef8d4da1e0a92b9aae54c8ea15e89707ca28f99c35e6d1aa8887f57eddfc",
+			"Amount": "200000000",
+			"TokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		}
+	],
+	"Cursor": ""
+}`)
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "invalid", 3, nil)).Error(t, api.ErrInvalidUnreceivedCursor)
+	common.Json(ledgerApi.GetAllUnreceivedBlocksByAddress(g.User5.Address, "", api.RpcMaxCountSize+1, nil)).Error(t, api.ErrCountParamTooBig)
+
+	common.Json(ledgerApi.GetUnreceivedSummaryByAddress(g.User5.Address)).SubJson(&struct {
+		Count  int
+		Totals map[types.ZenonTokenStandard]struct {
+			Count  int
+			Amount string
+		}
+	}{}).Equals(t, `
+{
+	"Count": 4,
+	"Totals": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"Count": 1,
+			"Amount": "500000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"Count": 3,
+			"Amount": "600000000"
+		}
+	}
+}`)
+
+	// received blocks are no longer part of the summary
+	autoreceive(t, z, g.User5.Address)
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetUnreceivedSummaryByAddress(g.User5.Address)).Equals(t, `
+{
+	"count": 0,
+	"totals": {}
+}`)
 }
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+	consensusApi "github.com/zenon-network/go-zenon/consensus/api"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func TestRPCStats_PillarProduction(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	statsApi := api.NewStatsApi(z, nil)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(5)
+	z.SkipMomentumSlots(2)
+	z.InsertMomentumsTo(10)
+
+	common.Json(statsApi.PillarProduction("TEST-pillar-cool", 0)).Equals(t, `
+{
+	"epoch": 0,
+	"name": "TEST-pillar-cool",
+	"expectedNum": 6,
+	"producedNum": 5,
+	"missed": [
+		{
+			"timestamp": 1000000060,
+			"producer": "z1qz8v73ea2vy2rrlq7skssngu8cm8mknjjkr2ju",
+			"local": null
+		}
+	]
+}`)
+	common.Json(statsApi.PillarProduction("TEST-pillar-znn", 0)).Equals(t, `
+{
+	"epoch": 0,
+	"name": "TEST-pillar-znn",
+	"expectedNum": 3,
+	"producedNum": 3,
+	"missed": []
+}`)
+	common.Json(statsApi.PillarProduction("TEST-pillar-cool", 1)).Equals(t, `null`)
+}
+
+func TestRPCStats_ProducerSchedule(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Minute*10)
+	statsApi := api.NewStatsApi(z, nil)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(10)
+	schedule, err := statsApi.ProducerSchedule("TEST-pillar-cool")
+	common.FailIfErr(t, err)
+	common.Json(schedule[0].Slots[:2], nil).Equals(t, `
+[
+	{
+		"startTime": 1000000010,
+		"endTime": 1000000020,
+		"name": "TEST-pillar-cool",
+		"producer": "z1qz8v73ea2vy2rrlq7skssngu8cm8mknjjkr2ju",
+		"final": true
+	},
+	{
+		"startTime": 1000000030,
+		"endTime": 1000000040,
+		"name": "TEST-pillar-cool",
+		"producer": "z1qz8v73ea2vy2rrlq7skssngu8cm8mknjjkr2ju",
+		"final": true
+	}
+]`)
+	common.Json(summarizeSchedule(schedule, nil)).Equals(t, `
+[
+	{
+		"epoch": 0,
+		"startTime": 1000000000,
+		"endTime": 1000000600,
+		"final": true,
+		"slots": 20,
+		"finalSlots": 20
+	},
+	{
+		"epoch": 1,
+		"startTime": 1000000600,
+		"endTime": 1000001200,
+		"final": false,
+		"slots": 20,
+		"finalSlots": 0
+	}
+]`)
+
+	// the election of the first tick of the next epoch is based on the last momentum of the current epoch's first tick
+	z.InsertMomentumsTo(30)
+	common.Json(summarizeSchedule(statsApi.ProducerSchedule("TEST-pillar-cool"))).Equals(t, `
+[
+	{
+		"epoch": 0,
+		"startTime": 1000000000,
+		"endTime": 1000000600,
+		"final": true,
+		"slots": 20,
+		"finalSlots": 20
+	},
+	{
+		"epoch": 1,
+		"startTime": 1000000600,
+		"endTime": 1000001200,
+		"final": false,
+		"slots": 20,
+		"finalSlots": 10
+	}
+]`)
+
+	schedule, err = statsApi.ProducerSchedule("")
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(len(schedule[1].Slots)), 60)
+}
+
+// summarizeSchedule keeps the number of slots of each epoch, and of them, how many are final
+func summarizeSchedule(list []*consensusApi.ProducerSchedule, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	type summary struct {
+		Epoch     uint64 `json:"epoch"`
+		StartTime int64  `json:"startTime"`
+		EndTime   int64  `json:"endTime"`
+		Final     bool   `json:"final"`
+		Slots     int    `json:"slots"`
+		Finals    int    `json:"finalSlots"`
+	}
+	result := make([]*summary, len(list))
+	for i, schedule := range list {
+		result[i] = &summary{
+			Epoch:     schedule.Epoch,
+			StartTime: schedule.StartTime,
+			EndTime:   schedule.EndTime,
+			Final:     schedule.Final,
+			Slots:     len(schedule.Slots),
+		}
+		for _, slot := range schedule.Slots {
+			if slot.Final {
+				result[i].Finals += 1
+			}
+		}
+	}
+	return result, nil
+}
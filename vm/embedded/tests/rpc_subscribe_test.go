@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func newSubscribeClient(t *testing.T, z mock.MockZenon) (*rpc.Client, func()) {
+	server := subscribe.GetSubscribeServer(z.Chain())
+	common.FailIfErr(t, server.Init())
+	common.FailIfErr(t, server.Start())
+
+	rpcServer := rpc.NewServer()
+	common.FailIfErr(t, rpcServer.RegisterName("ledger", subscribe.GetSubscribeApi()))
+	client := rpc.DialInProc(rpcServer)
+	return client, func() {
+		client.Close()
+		rpcServer.Stop()
+		common.FailIfErr(t, server.Stop())
+	}
+}
+
+// receiveNotifications returns the next count notifications, one JSON per line
+func receiveNotifications(t *testing.T, ch chan json.RawMessage, count int) string {
+	received := ""
+	for i := 0; i < count; i += 1 {
+		select {
+		case data := <-ch:
+			received += string(data) + "\n"
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for notification %v; received so far:\n%v", i, received)
+		}
+	}
+	select {
+	case data := <-ch:
+		t.Fatalf("unexpected notification %v", string(data))
+	case <-time.After(100 * time.Millisecond):
+	}
+	return received
+}
+
+func TestSubscribe_ResumeMomentums(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(4)
+	client, stop := newSubscribeClient(t, z)
+	defer stop()
+
+	ch := make(chan json.RawMessage, 100)
+	sub, err := client.Subscribe(context.Background(), "ledger", ch, "momentums", &subscribe.ResumeFrom{Height: 2})
+	common.FailIfErr(t, err)
+	defer sub.Unsubscribe()
+
+	// replay followed by live momentums
+	z.InsertNewMomentum()
+	common.Expect(t, receiveNotifications(t, ch, 4), `
+[{"hash":"3e0e29241b309558dd792503cd81fdabe547aad3124a7dd5dac180e864306212","height":2}]
+[{"hash":"69d1a6097920cd5698ad8759ee3e434209ff00d67c87d03d64b11048d1d900c9","height":3}]
+[{"hash":"3e83aeac836943879b0de130ed97dbce3c2649d031489e3b390c52472e22d1fd","height":4}]
+[{"hash":"335ece3b0ac3a2a23f9ecb3934ebfe814d2d117ea9feb969d72a7241fab02054","height":5}]`)
+
+	// momentum 5 is replaced by a momentum produced in a later slot
+	momentum, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(4)
+	common.FailIfErr(t, err)
+	insert := z.Chain().AcquireInsert("test")
+	common.FailIfErr(t, z.Chain().RollbackTo(insert, momentum.Identifier()))
+	insert.Unlock()
+	z.SkipMomentumSlots(1)
+	z.InsertNewMomentum()
+	common.Expect(t, receiveNotifications(t, ch, 2), `
+[{"rollbackTo":{"hash":"3e83aeac836943879b0de130ed97dbce3c2649d031489e3b390c52472e22d1fd","height":4}}]
+[{"hash":"a1ef52e803328635a7faf8013d7649fa8656bc33cbd33c5cb749d7e4313cd44c","height":5}]`)
+
+	// resume after the last momentum seen
+	resumed := make(chan json.RawMessage, 100)
+	resumedSub, err := client.Subscribe(context.Background(), "ledger", resumed, "momentums", &subscribe.ResumeFrom{Hash: &momentum.Hash})
+	common.FailIfErr(t, err)
+	defer resumedSub.Unsubscribe()
+	common.Expect(t, receiveNotifications(t, resumed, 1), `
+[{"hash":"a1ef52e803328635a7faf8013d7649fa8656bc33cbd33c5cb749d7e4313cd44c","height":5}]`)
+
+	_, err = client.Subscribe(context.Background(), "ledger", resumed, "momentums", &subscribe.ResumeFrom{Hash: &types.ZeroHash})
+	common.ExpectString(t, err.Error(), subscribe.ErrResumeMomentumNotFound.Error())
+}
+
+func TestSubscribe_ResumeAccountBlocksByAddress(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	client, stop := newSubscribeClient(t, z)
+	defer stop()
+
+	ch := make(chan json.RawMessage, 100)
+	sub, err := client.Subscribe(context.Background(), "ledger", ch, "accountBlocksByAddress", g.User1.Address, &subscribe.ResumeFrom{Height: 1})
+	common.FailIfErr(t, err)
+	defer sub.Unsubscribe()
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User3.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	common.Expect(t, receiveNotifications(t, ch, 3), `
+[{"blockType":1,"hash":"598fa623dd308bec7163bb375aa7546ec4aced3b71a1c9278709903e69280dbd","height":1,"address":"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz","toAddress":"z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f","fromHash":"0000000000000000000000000000000000000000000000000000000000000000","momentumHeight":1}]
+[{"blockType":2,"hash":"a49b936608ec189f6a6f3bb5f55f2173191484454860b04590fa628134aa7b99","height":2,"address":"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz","toAddress":"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx","fromHash":"0000000000000000000000000000000000000000000000000000000000000000","momentumHeight":2}]
+[{"blockType":2,"hash":"a7b36f1037fb65e201546e338545d25e27d2e7c831df7e5137f5d4e50e7d9ac6","height":3,"address":"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz","toAddress":"z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac","fromHash":"0000000000000000000000000000000000000000000000000000000000000000","momentumHeight":4}]`)
+}
+
+// the replay fails instead of skipping the account-blocks pruned by the node
+func TestSubscribe_ResumePrunedAccountBlocks(t *testing.T) {
+	z := mock.NewMockZenonWithPruning(t, 10)
+	defer z.StopPanic()
+
+	// the send-block is pruned once it's received, unlike the frontier block of user 1
+	fuse := func() {
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     types.PlasmaContract,
+			TokenStandard: types.QsrTokenStandard,
+			Amount:        big.NewInt(10 * g.Zexp),
+			Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User1.Address),
+		}, nil, mock.SkipVmChanges)
+	}
+	fuse()
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	fuse()
+	z.InsertMomentumsTo(20)
+	client, stop := newSubscribeClient(t, z)
+	defer stop()
+
+	ch := make(chan json.RawMessage, 100)
+	sub, err := client.Subscribe(context.Background(), "ledger", ch, "allAccountBlocks", &subscribe.ResumeFrom{Height: 2})
+	common.FailIfErr(t, err)
+	defer sub.Unsubscribe()
+
+	common.Expect(t, receiveNotifications(t, ch, 1), `
+[{"error":"account-blocks to replay were pruned by this node; resume from a later height"}]`)
+}
+
+// - calls made before subscribing aren't notified
+// - each subscription receives the events which match its contract and methods
+func TestSubscribe_EmbeddedEvents(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	client, stop := newSubscribeClient(t, z)
+	defer stop()
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.PillarContract,
+		Data:      definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, g.Pillar1Name),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	pillarEvents := make(chan json.RawMessage, 100)
+	pillarSub, err := client.Subscribe(context.Background(), "ledger", pillarEvents, "embeddedEvents", types.PillarContract, nil)
+	common.FailIfErr(t, err)
+	defer pillarSub.Unsubscribe()
+	fuseEvents := make(chan json.RawMessage, 100)
+	fuseSub, err := client.Subscribe(context.Background(), "ledger", fuseEvents, "embeddedEvents", nil, []string{definition.FuseMethodName})
+	common.FailIfErr(t, err)
+	defer fuseSub.Unsubscribe()
+	stakeEvents := make(chan json.RawMessage, 100)
+	stakeSub, err := client.Subscribe(context.Background(), "ledger", stakeEvents, "embeddedEvents", types.StakeContract, nil)
+	common.FailIfErr(t, err)
+	defer stakeSub.Unsubscribe()
+	// the subscriptions are installed by the event loop
+	time.Sleep(100 * time.Millisecond)
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.PillarContract,
+		Data:      definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, g.Pillar2Name),
+	}).Error(t, nil)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User3.Address,
+		ToAddress:     types.StakeContract,
+		Data:          definition.ABIStake.PackMethodPanic(definition.StakeMethodName, constants.StakeTimeMinSec),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Expect(t, receiveNotifications(t, pillarEvents, 1), `
+[{"contract":"z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg","method":"Delegate","args":{"name":"TEST-pillar-cool"},"sender":"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx","sendBlockHash":"e3ae8ed227a73800c038bed01f2c974593238f520ffa276b04084a6f958f2193","receiveBlockHash":"bb3e6ac3c3acba2ffe8af7adf59f2ae6f003c0a596507478895612db486dc2e5","momentumHeight":5}]`)
+	common.Expect(t, receiveNotifications(t, fuseEvents, 1), `
+[{"contract":"z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp","method":"Fuse","args":{"address":"z1qqdt06lnwz57x38rwlyutcx5wgrtl0ynkfe3kv"},"sender":"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz","sendBlockHash":"a67b6ddce9089c8f243fc1c7b02d833c07ad13cf16fefc78b02e2d6b0e064524","receiveBlockHash":"4743ac59a7975895bae465d77a383685f8f7da67c5dd67a87174498ce07e8d68","momentumHeight":5}]`)
+
+	// the 64 bit integers are decimal strings, like in the decoded data of the ledger API
+	common.Expect(t, receiveNotifications(t, stakeEvents, 1), `
+[{"contract":"z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62","method":"Stake","args":{"durationInSec":"3600"},"sender":"z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac","sendBlockHash":"05799c90fef1f4c6292332b0d0c55771dead5aadcbd3b6e7b5d5b04dca2727d2","receiveBlockHash":"d3f859279a019f791281268e6b84730d43c64cd4e47d144ccf21eb724a06de2c","momentumHeight":5}]`)
+
+	_, err = client.Subscribe(context.Background(), "ledger", fuseEvents, "embeddedEvents", types.Address{}, nil)
+	common.ExpectString(t, err.Error(), "not a contract address")
+}
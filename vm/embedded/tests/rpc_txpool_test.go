@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// hidePoolTimes clears the times of the pool blocks, which depend on the time the test runs
+func hidePoolTimes(t *testing.T, blocks ...*api.PoolAccountBlock) {
+	for _, block := range blocks {
+		common.ExpectTrue(t, block.AddedAt != 0)
+		block.AddedAt = 0
+		block.Age = 0
+	}
+}
+
+// hideRejectedTimes clears the times of the rejected account-blocks, which depend on the time the test runs
+func hideRejectedTimes(t *testing.T, rejected ...*api.RejectedAccountBlock) {
+	for _, entry := range rejected {
+		common.ExpectTrue(t, entry.RejectedAt != 0)
+		entry.RejectedAt = 0
+	}
+}
+
+// - test that the pool content shows the uncommitted account-blocks until they are confirmed
+// - test that the account-block which loses the fork rules is kept in the rejected account-blocks
+// - test that the uncommitted account-block rolled back for a conflicting one is kept with the block which replaced it
+func TestRPCTxPool(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	txPoolApi := api.NewTxPoolApi(z)
+	z.InsertMomentumsTo(3)
+
+	first := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100),
+	}, nil, mock.SkipVmChanges)
+	// a send-block at the same height, which conflicts with the first one
+	second := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User3.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(200),
+		PreviousHash:  first.PreviousHash,
+		Height:        first.Height,
+	}, nil, mock.SkipVmChanges)
+
+	common.Json(txPoolApi.GetStatus()).Equals(t, `
+{
+	"pending": 1,
+	"ready": 1,
+	"queued": 0,
+	"addresses": 1,
+	"rejected": 1
+}`)
+	content, err := txPoolApi.GetContentByAddress(g.User1.Address)
+	common.FailIfErr(t, err)
+	hidePoolTimes(t, content.Pending...)
+	common.Json(content, nil).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"confirmedFrontier": {
+		"hash": "598fa623dd308bec7163bb375aa7546ec4aced3b71a1c9278709903e69280dbd",
+		"height": 1
+	},
+	"frontier": {
+		"hash": "58eed6f752d2526c50f45312048044cc05c690745d245ed1c0f7aa2563d7eeac",
+		"height": 2
+	},
+	"pending": [
+		{
+			"hash": "58eed6f752d2526c50f45312048044cc05c690745d245ed1c0f7aa2563d7eeac",
+			"height": 2,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"blockType": 2,
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "100",
+			"momentumAcknowledged": {
+				"hash": "69d1a6097920cd5698ad8759ee3e434209ff00d67c87d03d64b11048d1d900c9",
+				"height": 3
+			},
+			"totalPlasma": 21000,
+			"basePlasma": 21000,
+			"plasmaRatio": 1,
+			"addedAt": 0,
+			"age": 0,
+			"status": "ready"
+		}
+	]
+}`)
+	rejected, err := txPoolApi.GetRejected(0, 10)
+	common.FailIfErr(t, err)
+	hideRejectedTimes(t, rejected.List...)
+	common.Json(rejected, nil).Equals(t, `
+{
+	"list": [
+		{
+			"block": {
+				"hash": "9793b88cd319aa3c3681adad5107bb7ba5c77bfb1a88cef48e23437f0ac852af",
+				"height": 2,
+				"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"toAddress": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+				"blockType": 2,
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"amount": "200",
+				"momentumAcknowledged": {
+					"hash": "69d1a6097920cd5698ad8759ee3e434209ff00d67c87d03d64b11048d1d900c9",
+					"height": 3
+				},
+				"totalPlasma": 21000,
+				"basePlasma": 21000,
+				"plasmaRatio": 1
+			},
+			"reason": "hash tie-break is worse for current block",
+			"replacedBy": null,
+			"rejectedAt": 0
+		}
+	],
+	"count": 1,
+	"more": false
+}`)
+	status, err := txPoolApi.GetAccountBlockStatus(second.Hash)
+	common.FailIfErr(t, err)
+	hideRejectedTimes(t, status.Rejections...)
+	common.Json(status, nil).Equals(t, `
+{
+	"hash": "9793b88cd319aa3c3681adad5107bb7ba5c77bfb1a88cef48e23437f0ac852af",
+	"status": "rejected",
+	"pending": null,
+	"confirmationHeight": 0,
+	"rejections": [
+		{
+			"block": {
+				"hash": "9793b88cd319aa3c3681adad5107bb7ba5c77bfb1a88cef48e23437f0ac852af",
+				"height": 2,
+				"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"toAddress": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+				"blockType": 2,
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"amount": "200",
+				"momentumAcknowledged": {
+					"hash": "69d1a6097920cd5698ad8759ee3e434209ff00d67c87d03d64b11048d1d900c9",
+					"height": 3
+				},
+				"totalPlasma": 21000,
+				"basePlasma": 21000,
+				"plasmaRatio": 1
+			},
+			"reason": "hash tie-break is worse for current block",
+			"replacedBy": null,
+			"rejectedAt": 0
+		}
+	]
+}`)
+
+	z.InsertNewMomentum()
+	// the first send-block is rolled back, since the second one wins the hash tie-break
+	third := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User3.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(200),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100),
+		PreviousHash:  third.PreviousHash,
+		Height:        third.Height,
+	}, nil, mock.SkipVmChanges)
+	status, err = txPoolApi.GetAccountBlockStatus(third.Hash)
+	common.FailIfErr(t, err)
+	hideRejectedTimes(t, status.Rejections...)
+	common.Json(status, nil).Equals(t, `
+{
+	"hash": "f752603bc9483996f346e29b8d52226f21d65a5ffcd7aa9d559e411cb964552c",
+	"status": "rejected",
+	"pending": null,
+	"confirmationHeight": 0,
+	"rejections": [
+		{
+			"block": {
+				"hash": "f752603bc9483996f346e29b8d52226f21d65a5ffcd7aa9d559e411cb964552c",
+				"height": 3,
+				"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"toAddress": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+				"blockType": 2,
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"amount": "200",
+				"momentumAcknowledged": {
+					"hash": "7052c2f985e7a84169984ff4ef860ef55715b2adb8fd5669564b8cda739ebcb2",
+					"height": 4
+				},
+				"totalPlasma": 21000,
+				"basePlasma": 21000,
+				"plasmaRatio": 1
+			},
+			"reason": "rolled back to insert a conflicting account-block",
+			"replacedBy": {
+				"hash": "556de6be9073bcde3d05a551c592b511dbc321ad7cbf0cccabf0926eb0d71ab5",
+				"height": 3
+			},
+			"rejectedAt": 0
+		}
+	]
+}`)
+
+	z.InsertNewMomentum()
+	common.Json(txPoolApi.GetContent(0, 10)).Equals(t, `
+{
+	"list": [],
+	"count": 0,
+	"more": false
+}`)
+	common.Json(txPoolApi.GetAccountBlockStatus(first.Hash)).Equals(t, `
+{
+	"hash": "58eed6f752d2526c50f45312048044cc05c690745d245ed1c0f7aa2563d7eeac",
+	"status": "confirmed",
+	"pending": null,
+	"confirmationHeight": 4,
+	"rejections": []
+}`)
+}
@@ -758,7 +758,7 @@ t=2001-09-09T02:47:10+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -883,7 +883,7 @@ func TestSentinel_TryToCollectBeforeRegister(t *testing.T) {
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [],
 	"count": 0,
@@ -991,7 +991,7 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg="successfully register" module=embedded
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [],
 	"count": 0,
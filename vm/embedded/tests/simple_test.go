@@ -86,7 +86,7 @@ func TestSimple_UnreceivedDisappears(t *testing.T) {
 	simpleSendSetup(t, z)
 
 	// check that the block disappears from unreceived
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10)).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10, nil)).Equals(t, `
 {
 	"list": [],
 	"count": 0,
@@ -291,7 +291,7 @@ func TestSimple_MomentumAcknowledgedIncreasing(t *testing.T) {
 
 	momentums, err := ledgerApi.GetMomentumsByHeight(3, 2)
 	common.FailIfErr(t, err)
-	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 2)
+	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 2, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, unreceived.Count, 2)
 
@@ -324,10 +324,10 @@ func TestSimple_BatchedBlocks(t *testing.T) {
 	z.InsertNewMomentum()
 
 	// initial statement, account-block has height 1 with 10 unreceived blocks
-	frontierAccBlock, err := ledgerApi.GetFrontierAccountBlock(g.User2.Address)
+	frontierAccBlock, err := ledgerApi.GetFrontierAccountBlock(g.User2.Address, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, frontierAccBlock.Height, 1)
-	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10)
+	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, unreceived.Count, 10)
 
@@ -339,10 +339,10 @@ func TestSimple_BatchedBlocks(t *testing.T) {
 	z.InsertNewMomentum()
 
 	// final statement, account-block has height 11 with 0 unreceived blocks
-	frontierAccBlock, err = ledgerApi.GetFrontierAccountBlock(g.User2.Address)
+	frontierAccBlock, err = ledgerApi.GetFrontierAccountBlock(g.User2.Address, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, frontierAccBlock.Height, 11)
-	unreceived, err = ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10)
+	unreceived, err = ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10, nil)
 	common.FailIfErr(t, err)
 	common.Expect(t, unreceived.Count, 0)
 }
@@ -410,7 +410,7 @@ func TestSendBlockReceiver(t *testing.T) {
 	frontierMomentum, err := ledgerApi.GetFrontierMomentum()
 	common.FailIfErr(t, err)
 
-	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 1)
+	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 1, nil)
 	common.FailIfErr(t, err)
 
 	// User 2 sends receive transaction
@@ -160,7 +160,7 @@ t=2001-09-09T05:17:20+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -226,7 +226,7 @@ t=2001-09-09T05:17:20+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User2.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -683,7 +683,7 @@ t=2001-09-09T07:16:50+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	}).Error(t, nil)
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -765,7 +765,7 @@ t=2001-09-09T07:16:50+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	"znnAmount": "0",
 	"qsrAmount": "0"
 }`)
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).HideHashes().Equals(t, `
 {
 	"list": [
 		{
@@ -1028,7 +1028,7 @@ t=2001-09-09T05:17:00+0000 lvl=dbug msg="minted ZTS" module=embedded contract=to
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10)).Equals(t, `
+	common.Json(ledgerApi.GetUnreceivedBlocksByAddress(g.User1.Address, 0, 10, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -1158,3 +1158,60 @@ func TestStake_CheckRpc(t *testing.T) {
 	"list": []
 }`)
 }
+
+// - stake for User1
+// - check stake.getEntriesByAddressAt and stake.getUncollectedRewardAt return the entries and rewards as of the
+// requested momentum
+func TestStake_QueriesAt(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	stakeApi := embedded.NewStakeApi(z)
+	defer z.StopPanic()
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.StakeContract,
+		Data:          definition.ABIStake.PackMethodPanic(definition.StakeMethodName, constants.StakeTimeMinSec),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // include send block
+	z.InsertNewMomentum() // include contract receive block
+	z.InsertMomentumsTo(momentumsInHour + 10)
+
+	common.Json(stakeApi.GetEntriesByAddressAt(g.User1.Address, 2, 0, 10)).Equals(t, `
+{
+	"totalAmount": "0",
+	"totalWeightedAmount": "0",
+	"count": 0,
+	"list": []
+}`)
+	common.Json(stakeApi.GetEntriesByAddressAt(g.User1.Address, 3, 0, 10)).HideHashes().Equals(t, `
+{
+	"totalAmount": "10000000000",
+	"totalWeightedAmount": "10000000000",
+	"count": 1,
+	"list": [
+		{
+			"amount": "10000000000",
+			"weightedAmount": "10000000000",
+			"startTimestamp": 1000000010,
+			"expirationTimestamp": 1000003610,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+		}
+	]
+}`)
+	common.Json(stakeApi.GetUncollectedRewardAt(g.User1.Address, 3)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"znnAmount": "0",
+	"qsrAmount": "0"
+}`)
+	common.Json(stakeApi.GetUncollectedRewardAt(g.User1.Address, momentumsInHour+10)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"znnAmount": "0",
+	"qsrAmount": "1000000000000"
+}`)
+	common.Json(stakeApi.GetUncollectedRewardAt(g.User1.Address, 0)).Error(t, api.ErrHeightParamIsZero)
+}
@@ -1144,3 +1144,133 @@ t=2001-09-09T01:48:10+0000 lvl=dbug msg="burned ZTS" module=embedded contract=to
 	"isUtility": false
 }`)
 }
+
+func TestToken_HoldersAndSupply(t *testing.T) {
+	z := mock.NewMockZenonWithHolderIndex(t)
+	defer z.StopPanic()
+	tokenAPI := embedded.NewTokenApi(z)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(20 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User1.Address),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(tokenAPI.GetHolders(types.QsrTokenStandard, 0, 3)).Equals(t, `
+{
+	"count": 12,
+	"list": [
+		{
+			"address": "z1qqfmjdays57w488sta69ykc2ey7r6d0q9wdvtj",
+			"balance": "45000000000000"
+		},
+		{
+			"address": "z1qqqcn34kcg8gy7hcuqs7d7mu6eq4mwryftgads",
+			"balance": "20000000000000"
+		},
+		{
+			"address": "z1qqgrqcklnx08k8qwxvrryj9f92gngmcm7ltgms",
+			"balance": "20000000000000"
+		}
+	],
+	"indexedHeight": 3
+}`)
+	common.Json(tokenAPI.GetSupplyBreakdown(types.QsrTokenStandard)).Equals(t, `
+{
+	"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+	"totalSupply": "180550000000000",
+	"maxSupply": "4611686018427387903",
+	"circulating": "166547000000000",
+	"locked": {
+		"bridge": "0",
+		"htlc": "0",
+		"liquidity": "0",
+		"otherEmbedded": "0",
+		"pillar": "0",
+		"plasma": "14001000000000",
+		"sentinel": "0",
+		"stake": "0"
+	},
+	"unreceived": "2000000000",
+	"indexedHeight": 3
+}`)
+
+	autoreceive(t, z, g.User2.Address)
+	z.InsertNewMomentum()
+	common.Json(tokenAPI.GetHolders(types.QsrTokenStandard, 1, 2)).Equals(t, `
+{
+	"count": 12,
+	"list": [
+		{
+			"address": "z1qqgrqcklnx08k8qwxvrryj9f92gngmcm7ltgms",
+			"balance": "20000000000000"
+		},
+		{
+			"address": "z1qq56p6e9s6emkjj689ayz05laz466nwxme4r8z",
+			"balance": "20000000000000"
+		}
+	],
+	"indexedHeight": 4
+}`)
+	common.Json(tokenAPI.GetSupplyBreakdown(types.QsrTokenStandard)).Equals(t, `
+{
+	"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+	"totalSupply": "180550000000000",
+	"maxSupply": "4611686018427387903",
+	"circulating": "166549000000000",
+	"locked": {
+		"bridge": "0",
+		"htlc": "0",
+		"liquidity": "0",
+		"otherEmbedded": "0",
+		"pillar": "0",
+		"plasma": "14001000000000",
+		"sentinel": "0",
+		"stake": "0"
+	},
+	"unreceived": "0",
+	"indexedHeight": 4
+}`)
+}
+
+// - issue a token
+// - check token.getByZtsAt returns the token only after the momentum which confirms its issuance
+func TestToken_GetByZtsAt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	tokenApi := embedded.NewTokenApi(z)
+	defer z.StopPanic()
+
+	issueTokenSetup(t, z)
+
+	common.Json(tokenApi.GetByZtsAt(customZts, 2)).Equals(t, `null`)
+	common.Json(tokenApi.GetByZtsAt(customZts, 3)).Equals(t, `
+{
+	"name": "test.tok3n_na-m3",
+	"symbol": "TEST",
+	"domain": "",
+	"totalSupply": "100",
+	"decimals": 1,
+	"owner": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"tokenStandard": "zts103tsa5yqngu9cfpj2m0z9u",
+	"maxSupply": "1000",
+	"isBurnable": true,
+	"isMintable": true,
+	"isUtility": false
+}`)
+	common.Json(tokenApi.GetByZtsAt(types.ZnnTokenStandard, 1)).SubJson(&struct {
+		TotalSupply string `json:"totalSupply"`
+	}{}).Equals(t, `
+{
+	"totalSupply": "19500000000000"
+}`)
+}
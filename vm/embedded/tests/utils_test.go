@@ -102,7 +102,7 @@ func init() {
 
 func autoreceive(t *testing.T, z mock.MockZenon, address types.Address) {
 	ledgerApi := api.NewLedgerApi(z)
-	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(address, 0, 50)
+	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(address, 0, 50, nil)
 	common.FailIfErr(t, err)
 	for _, block := range unreceived.List {
 		z.InsertReceiveBlock(block.AccountBlock.Header(), nil, nil, mock.SkipVmChanges)
@@ -84,13 +84,25 @@ func AvailablePlasma(momentum store.Momentum, account store.Account) (uint64, er
 
 // GetBasePlasmaForAccountBlock calculates the smallest plasma required for an account block.
 func GetBasePlasmaForAccountBlock(context vm_context.AccountVmContext, block *nom.AccountBlock) (uint64, error) {
+	return getBasePlasmaForAccountBlock(block, func(address types.Address, abiSelector []byte) (embedded.Method, error) {
+		return embedded.GetEmbeddedMethod(context, address, abiSelector)
+	})
+}
+
+// GetBasePlasmaForAccountBlockOffline calculates the smallest plasma required for an account block without access to the chain.
+// All the embedded methods added by sporks are considered available.
+func GetBasePlasmaForAccountBlockOffline(block *nom.AccountBlock) (uint64, error) {
+	return getBasePlasmaForAccountBlock(block, embedded.GetLatestEmbeddedMethod)
+}
+
+func getBasePlasmaForAccountBlock(block *nom.AccountBlock, getMethod func(types.Address, []byte) (embedded.Method, error)) (uint64, error) {
 	if types.IsEmbeddedAddress(block.Address) {
 		return 0, nil
 	}
 	if block.IsReceiveBlock() {
 		return constants.AccountBlockBasePlasma, nil
 	} else {
-		if method, err := embedded.GetEmbeddedMethod(context, block.ToAddress, block.Data); err == constants.ErrNotContractAddress {
+		if method, err := getMethod(block.ToAddress, block.Data); err == constants.ErrNotContractAddress {
 			if len(block.Data) > constants.MaxDataLength {
 				return 0, verifier.ErrABDataTooBig
 			}
@@ -0,0 +1,137 @@
+package vm
+
+import (
+	"math/big"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+// BlockSimulation is the result of applying an account-block on top of the frontier without inserting it.
+//   - Block is the template filled with all the fields computed by the supervisor (height, plasma, etc.)
+//   - ContractReceive is the auto-receive block generated by the embedded contract, if the block is a send to one
+//   - BlockError is the error returned when applying the block itself
+//   - ContractError is the error returned by the embedded method; the sent funds are refunded in this case
+type BlockSimulation struct {
+	Block           *nom.AccountBlock
+	ContractReceive *nom.AccountBlock
+	BalanceChanges  map[types.Address]map[types.ZenonTokenStandard]*big.Int
+	BlockError      error
+	ContractError   error
+}
+
+func balanceChanges(before map[types.ZenonTokenStandard]*big.Int, after store.Account) (map[types.ZenonTokenStandard]*big.Int, error) {
+	afterMap, err := after.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[types.ZenonTokenStandard]*big.Int)
+	for zts, balance := range afterMap {
+		delta := new(big.Int).Set(balance)
+		if previous, ok := before[zts]; ok {
+			delta.Sub(delta, previous)
+		}
+		if delta.Sign() != 0 {
+			changes[zts] = delta
+		}
+	}
+	for zts, balance := range before {
+		if _, ok := afterMap[zts]; !ok && balance.Sign() != 0 {
+			changes[zts] = new(big.Int).Neg(balance)
+		}
+	}
+	return changes, nil
+}
+
+// SimulateBlock applies the template on top of the frontier and, for sends to embedded contracts,
+// executes the contract receive as well. None of the changes are inserted in the chain.
+// The template is not required to be signed or to have its hash computed.
+func (s *Supervisor) SimulateBlock(template *nom.AccountBlock) (result *BlockSimulation, internalErr error) {
+	if template.BlockType != nom.BlockTypeUserSend && template.BlockType != nom.BlockTypeUserReceive {
+		return nil, errors.Errorf("can only simulate user blocks")
+	}
+
+	block := template.Copy()
+	defer func() {
+		if err := recover(); err != nil {
+			l := s.log.New("block", block.Header())
+			l.Error("vm panic when simulating block", "reason", err, "stack", string(debug.Stack()))
+
+			result = nil
+			internalErr = constants.ErrVmRunPanic
+		}
+	}()
+
+	if err := s.setAll(block); err != nil {
+		return nil, err
+	}
+
+	result = &BlockSimulation{
+		Block:          block,
+		BalanceChanges: make(map[types.Address]map[types.ZenonTokenStandard]*big.Int),
+	}
+
+	if err := s.verifier.AccountBlock(block); err != nil {
+		result.BlockError = err
+		return result, nil
+	}
+	context := s.newBlockContext(block)
+	if err := s.setBlockPlasma(context, block); err != nil {
+		return nil, err
+	}
+	before, err := context.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	if err := NewVM(context).applyBlock(block); err != nil {
+		result.BlockError = err
+		return result, nil
+	}
+	block.Hash = block.ComputeHash()
+
+	changes, err := balanceChanges(before, context)
+	if err != nil {
+		return nil, err
+	}
+	result.BalanceChanges[block.Address] = changes
+
+	if block.BlockType != nom.BlockTypeUserSend || !types.IsEmbeddedAddress(block.ToAddress) {
+		return result, nil
+	}
+
+	momentumStore := s.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	contractContext := vm_context.NewAccountContext(
+		momentumStore,
+		s.chain.GetFrontierAccountStore(block.ToAddress),
+		s.consensus.FixedPillarReader(frontier.Identifier()),
+	)
+	before, err = contractContext.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	receive, methodErr, err := NewVM(contractContext).receiveEmbedded(block)
+	if err != nil {
+		return nil, err
+	}
+	result.ContractReceive = receive
+	result.ContractError = methodErr
+
+	changes, err = balanceChanges(before, contractContext)
+	if err != nil {
+		return nil, err
+	}
+	result.BalanceChanges[block.ToAddress] = changes
+
+	return result, nil
+}
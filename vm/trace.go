@@ -0,0 +1,280 @@
+package vm
+
+import (
+	"math/big"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/account"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+var (
+	ErrTraceBlockNotConfirmed = errors.New("only confirmed account-blocks can be traced")
+	ErrTraceContractSend      = errors.New("contract send-blocks are traced together with the contract receive-block which generated them")
+	ErrTraceStateUnavailable  = errors.New("the state on which the block was applied is no longer available")
+)
+
+// StorageChange is a key of the account storage changed by a block. After is nil if the key was deleted.
+type StorageChange struct {
+	Key    []byte
+	Before []byte
+	After  []byte
+}
+
+// BlockTrace is the result of replaying an account-block, which is part of the chain, on top of the state it was applied to.
+//   - Changes are the keys of the account storage changed by the block, excluding the records of the block itself
+//   - Descendants are the send-blocks generated by the embedded contract, for contract receive-blocks
+//   - ReturnedError is the error returned by the embedded method; the sent funds were refunded in this case
+//   - BlockError is the error returned when replaying the block, which is expected to be nil for blocks in the chain
+type BlockTrace struct {
+	Block          *nom.AccountBlock
+	Changes        []*StorageChange
+	BalanceChanges map[types.ZenonTokenStandard]*big.Int
+	Descendants    []*nom.AccountBlock
+	ReturnedError  error
+	BlockError     error
+}
+
+// storageDiff records the before and after values of the keys changed by patch
+type storageDiff struct {
+	before  db.DB
+	changes []*StorageChange
+	err     error
+}
+
+func (d *storageDiff) get(key []byte) []byte {
+	value, err := d.before.Get(key)
+	if err != nil && err != leveldb.ErrNotFound && d.err == nil {
+		d.err = err
+	}
+	return value
+}
+func (d *storageDiff) Put(key []byte, value []byte) {
+	d.changes = append(d.changes, &StorageChange{
+		Key:    copyBytes(key),
+		Before: d.get(key),
+		After:  copyBytes(value),
+	})
+}
+func (d *storageDiff) Delete(key []byte) {
+	d.changes = append(d.changes, &StorageChange{
+		Key:    copyBytes(key),
+		Before: d.get(key),
+	})
+}
+
+func copyBytes(data []byte) []byte {
+	return append([]byte{}, data...)
+}
+
+// TraceBlock replays the confirmed account-block on top of the state of its account before the block was inserted.
+// For contract receive-blocks the embedded method is executed again, which makes the returned error available.
+func (s *Supervisor) TraceBlock(block *nom.AccountBlock) (result *BlockTrace, internalErr error) {
+	if block.BlockType == nom.BlockTypeContractSend {
+		return nil, ErrTraceContractSend
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			l := s.log.New("block", block.Header())
+			l.Error("vm panic when tracing block", "reason", err, "stack", string(debug.Stack()))
+
+			result = nil
+			internalErr = constants.ErrVmRunPanic
+		}
+	}()
+
+	accountDB, err := s.getParentAccountDB(block)
+	if err != nil {
+		return nil, err
+	}
+	trace, _, err := s.replayBlock(accountDB, block)
+	return trace, err
+}
+
+// TraceMomentum traces all the account-blocks confirmed by the momentum, in order
+func (s *Supervisor) TraceMomentum(momentum *nom.Momentum) ([]*BlockTrace, error) {
+	detailed, err := s.chain.GetFrontierMomentumStore().PrefetchMomentum(momentum)
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*BlockTrace, 0, len(detailed.AccountBlocks))
+	for _, block := range detailed.AccountBlocks {
+		if block == nil {
+			return nil, ErrTraceStateUnavailable
+		}
+		if block.BlockType == nom.BlockTypeContractSend {
+			continue
+		}
+		trace, err := s.TraceBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, trace)
+	}
+	return traces, nil
+}
+
+// getParentAccountDB rebuilds the storage of the account right before block. The state of the account as of the
+// previous momentum is used, on top of which the blocks of the account confirmed earlier in the same momentum are replayed.
+func (s *Supervisor) getParentAccountDB(block *nom.AccountBlock) (db.DB, error) {
+	frontierStore := s.chain.GetFrontierMomentumStore()
+	confirmation, err := frontierStore.GetBlockConfirmationHeight(block.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if confirmation == 0 {
+		return nil, ErrTraceBlockNotConfirmed
+	}
+	previous, err := frontierStore.GetMomentumByHeight(confirmation - 1)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, ErrTraceStateUnavailable
+	}
+	momentumStore := s.chain.GetMomentumStore(previous.Identifier())
+	if momentumStore == nil {
+		return nil, ErrTraceStateUnavailable
+	}
+
+	accountDB, err := copyAccountDB(momentumStore.GetAccountDB(block.Address))
+	if err != nil {
+		return nil, err
+	}
+	start := account.NewAccountStore(block.Address, accountDB).Identifier().Height + 1
+	for height := start; height <= block.Previous().Height; height += 1 {
+		earlier, err := frontierStore.GetAccountBlockByHeight(block.Address, height)
+		if err != nil {
+			return nil, err
+		}
+		if earlier == nil {
+			return nil, ErrTraceStateUnavailable
+		}
+		if earlier.BlockType == nom.BlockTypeContractSend {
+			continue
+		}
+		_, changes, err := s.replayBlock(accountDB, earlier)
+		if err != nil {
+			return nil, err
+		}
+		if changes == nil {
+			return nil, errors.Errorf("failed to replay account-block %v", earlier.Header())
+		}
+		if err := applyBlockChanges(accountDB, earlier, changes); err != nil {
+			return nil, err
+		}
+	}
+	return accountDB, nil
+}
+
+// copyAccountDB copies the storage of an account read at a past momentum. Such a view reads the keys created after
+// the momentum as empty values, which no account stores, so those keys are left out.
+func copyAccountDB(past db.DB) (db.DB, error) {
+	accountDB := db.NewMemDB()
+	iterator := past.NewIterator([]byte{})
+	defer iterator.Release()
+	for iterator.Next() {
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		if err := accountDB.Put(iterator.Key(), iterator.Value()); err != nil {
+			return nil, err
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return accountDB, nil
+}
+
+// replayBlock applies block on top of accountDB, which is left unchanged, and returns the trace and the changes.
+// The changes are nil if the block failed to apply.
+func (s *Supervisor) replayBlock(accountDB db.DB, block *nom.AccountBlock) (*BlockTrace, db.Patch, error) {
+	momentumStore := s.chain.GetMomentumStore(block.MomentumAcknowledged)
+	pillarReader := s.consensus.FixedPillarReader(block.MomentumAcknowledged)
+	if momentumStore == nil || pillarReader == nil {
+		return nil, nil, ErrTraceStateUnavailable
+	}
+	context := vm_context.NewAccountContext(
+		momentumStore,
+		account.NewAccountStore(block.Address, accountDB.Snapshot()),
+		pillarReader,
+	)
+	before, err := context.GetBalanceMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trace := &BlockTrace{
+		Block:       block,
+		Changes:     make([]*StorageChange, 0),
+		Descendants: make([]*nom.AccountBlock, 0),
+	}
+	vm := NewVM(context)
+	if block.BlockType == nom.BlockTypeContractReceive {
+		generated, methodErr, err := vm.generateEmbeddedReceive(block.FromBlockHash)
+		if err != nil {
+			trace.BlockError = err
+			return trace, nil, nil
+		}
+		trace.Descendants = append(trace.Descendants, generated.DescendantBlocks...)
+		trace.ReturnedError = methodErr
+		if generated.Hash != block.Hash {
+			trace.BlockError = errors.Errorf("replayed block has different hash expected %v but got %v", block.Hash, generated.Hash)
+		}
+	} else if err := vm.applyBlock(block); err != nil {
+		trace.BlockError = err
+		return trace, nil, nil
+	}
+
+	changes, err := context.Changes()
+	if err != nil {
+		return nil, nil, err
+	}
+	diff := &storageDiff{before: accountDB}
+	if err := changes.Replay(diff); err != nil {
+		return nil, nil, err
+	}
+	if diff.err != nil {
+		return nil, nil, diff.err
+	}
+	trace.Changes = diff.changes
+	if trace.BalanceChanges, err = balanceChanges(before, context); err != nil {
+		return nil, nil, err
+	}
+	return trace, changes, nil
+}
+
+// applyBlockChanges applies changes on accountDB together with the records of block and of its descendants,
+// the same way the account-block transaction is inserted in the chain
+func applyBlockChanges(accountDB db.DB, block *nom.AccountBlock, changes db.Patch) error {
+	transaction := &nom.AccountBlockTransaction{
+		Block:   block,
+		Changes: changes,
+	}
+	for _, commit := range transaction.GetCommits() {
+		temp := db.NewMemDB()
+		data, err := commit.Serialize()
+		if err != nil {
+			return err
+		}
+		if err := db.SetFrontier(temp, commit.Identifier(), data); err != nil {
+			return err
+		}
+		frontierPatch, err := temp.Changes()
+		if err != nil {
+			return err
+		}
+		if err := frontierPatch.Replay(changes); err != nil {
+			return err
+		}
+	}
+	return accountDB.Apply(changes)
+}
@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"bytes"
 	"math/big"
 
 	"github.com/pkg/errors"
@@ -34,6 +35,11 @@ func errToStatus(err error) uint64 {
 	}
 }
 
+// IsSuccessfulEmbeddedReceive reports whether the embedded method executed by the contract-receive block succeeded
+func IsSuccessfulEmbeddedReceive(block *nom.AccountBlock) bool {
+	return block.BlockType == nom.BlockTypeContractReceive && bytes.Equal(block.Data, common.Uint64ToBytes(resultSuccess))
+}
+
 type VM struct {
 	context vm_context.AccountVmContext
 }
@@ -164,12 +170,19 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	if err != nil {
 		return nil, nil, err
 	}
+	return vm.receiveEmbedded(sendBlock)
+}
+
+// receiveEmbedded executes the embedded method called by sendBlock on top of vm.context.
+// Unlike generateEmbeddedReceive, the send-block doesn't need to be present in the momentum store.
+func (vm *VM) receiveEmbedded(sendBlock *nom.AccountBlock) (*nom.AccountBlock, error, error) {
+	fromBlockHash := sendBlock.Hash
 	method, err := embedded.GetEmbeddedMethod(vm.context, sendBlock.ToAddress, sendBlock.Data)
 
 	// can happen when a method is deleted in a spork (height 100) and someone calls it before the spork (height 95)
 	// and the autoReceive uses momentum height 105 for various reasons
 	if err == constants.ErrContractMethodNotFound {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 
 	vm.context.Save()
@@ -178,13 +191,13 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	// call code
 	descendantBlocks, err := method.ReceiveBlock(vm.context, sendBlock)
 	if err != nil {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 	// apply send-descendant-blocks
 	for _, dblock := range descendantBlocks {
 		err := vm.applySend(dblock)
 		if err != nil {
-			return vm.rollbackEmbedded(fromBlockHash, err)
+			return vm.rollbackEmbedded(sendBlock, err)
 		}
 	}
 
@@ -192,10 +205,7 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	vm.context.Done()
 	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, nil)
 }
-func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.AccountBlock, error, error) {
-	sendBlock, err := vm.context.MomentumStore().GetAccountBlockByHash(fromBlockHash)
-	common.DealWithErr(err) // impossible to not find send-block at rollback
-
+func (vm *VM) rollbackEmbedded(sendBlock *nom.AccountBlock, methodErr error) (*nom.AccountBlock, error, error) {
 	vm.context.Reset()
 	// If sendBlock contains amount, add current amount to embedded to be able to refund it
 	// This operation was rollbacked with vm.context.Reset()
@@ -221,7 +231,7 @@ func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.
 		descendantBlocks = append(descendantBlocks, dBlock)
 	}
 
-	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, methodErr)
+	return vm.finalizeEmbedded(sendBlock.Hash, descendantBlocks, methodErr)
 }
 func (vm *VM) finalizeEmbedded(fromBlockHash types.Hash, descendantBlocks []*nom.AccountBlock, executionError error) (*nom.AccountBlock, error, error) {
 	var err error
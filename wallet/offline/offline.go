@@ -0,0 +1,176 @@
+// Package offline assembles, ABI-encodes, computes the PoW and signs account-blocks without access to a node.
+//
+// The account frontier and the acknowledged momentum are part of the description, so they have to be fetched from a
+// node beforehand. The resulting block is in the JSON format accepted by ledger.publishRawTransaction.
+package offline
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const (
+	blockVersion = 1
+)
+
+var (
+	ErrInvalidHeight       = errors.New("height must be at least 1")
+	ErrInvalidBlockType    = errors.New("only user send and user receive blocks can be built")
+	ErrMissingMomentum     = errors.New("momentumAcknowledged is required")
+	ErrDataAndMethod       = errors.New("data and method can't be used together")
+	ErrMethodOnReceive     = errors.New("method can only be used for send blocks")
+	ErrInvalidAmount       = errors.New("amount must be a non-negative integer")
+	ErrMissingFromBlock    = errors.New("fromBlockHash is required for receive blocks")
+	ErrPoWAndDifficulty    = errors.New("pow and difficulty can't be used together")
+	ErrPlasmaTooHighForPoW = errors.New("the required plasma can't be covered by PoW")
+)
+
+// Description holds everything needed to build an account-block
+type Description struct {
+	ChainIdentifier uint64 `json:"chainIdentifier"`
+	// BlockType is nom.BlockTypeUserSend or nom.BlockTypeUserReceive. Defaults to send
+	BlockType uint64 `json:"blockType"`
+
+	// PreviousHash and Height are the hash of the account frontier and the height of the new block
+	PreviousHash         types.Hash       `json:"previousHash"`
+	Height               uint64           `json:"height"`
+	MomentumAcknowledged types.HashHeight `json:"momentumAcknowledged"`
+
+	// Send information
+	ToAddress     types.Address            `json:"toAddress"`
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount        string                   `json:"amount"`
+
+	// Receive information
+	FromBlockHash types.Hash `json:"fromBlockHash"`
+
+	// Data is used as it is. Method and Params encode a call to the embedded contract at ToAddress instead,
+	// with params as described by abi.PackMethodJSON
+	Data   []byte            `json:"data"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+
+	FusedPlasma uint64 `json:"fusedPlasma"`
+	Difficulty  uint64 `json:"difficulty"`
+	// PoW sets the difficulty to cover the plasma which is required by the block and is not covered by FusedPlasma
+	PoW bool `json:"pow"`
+}
+
+// Build assembles the block described by d and signs it with keyPair
+func Build(d *Description, keyPair *wallet.KeyPair) (*nom.AccountBlock, error) {
+	block, err := d.template(keyPair.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPoW(d, block); err != nil {
+		return nil, err
+	}
+
+	block.Hash = block.ComputeHash()
+	block.PublicKey = keyPair.Public
+	block.Signature = keyPair.Sign(block.Hash.Bytes())
+	return block, nil
+}
+
+// Marshal returns the JSON of block as accepted by ledger.publishRawTransaction
+func Marshal(block *nom.AccountBlock) ([]byte, error) {
+	return json.MarshalIndent(block.ToNomMarshalJson(), "", "    ")
+}
+
+func (d *Description) template(address types.Address) (*nom.AccountBlock, error) {
+	blockType := d.BlockType
+	if blockType == 0 {
+		blockType = nom.BlockTypeUserSend
+	}
+	if blockType != nom.BlockTypeUserSend && blockType != nom.BlockTypeUserReceive {
+		return nil, ErrInvalidBlockType
+	}
+	if d.Height == 0 {
+		return nil, ErrInvalidHeight
+	}
+	if d.MomentumAcknowledged.IsZero() {
+		return nil, ErrMissingMomentum
+	}
+
+	block := &nom.AccountBlock{
+		Version:              blockVersion,
+		ChainIdentifier:      d.ChainIdentifier,
+		BlockType:            blockType,
+		PreviousHash:         d.PreviousHash,
+		Height:               d.Height,
+		MomentumAcknowledged: d.MomentumAcknowledged,
+		Address:              address,
+		Amount:               big.NewInt(0),
+		FusedPlasma:          d.FusedPlasma,
+		Difficulty:           d.Difficulty,
+	}
+
+	if blockType == nom.BlockTypeUserReceive {
+		if d.Method != "" {
+			return nil, ErrMethodOnReceive
+		}
+		if d.FromBlockHash.IsZero() {
+			return nil, ErrMissingFromBlock
+		}
+		block.FromBlockHash = d.FromBlockHash
+		block.Data = d.Data
+		return block, nil
+	}
+
+	block.ToAddress = d.ToAddress
+	block.TokenStandard = d.TokenStandard
+	if d.Amount != "" {
+		amount, ok := new(big.Int).SetString(d.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, ErrInvalidAmount
+		}
+		block.Amount = amount
+	}
+
+	if d.Method == "" {
+		block.Data = d.Data
+		return block, nil
+	}
+	if len(d.Data) != 0 {
+		return nil, ErrDataAndMethod
+	}
+	contractAbi, err := embedded.GetEmbeddedABI(d.ToAddress)
+	if err != nil {
+		return nil, errors.Errorf("can't call method %v on %v. Reason: %v", d.Method, d.ToAddress, err)
+	}
+	if block.Data, err = contractAbi.PackMethodJSON(d.Method, d.Params); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func setPoW(d *Description, block *nom.AccountBlock) error {
+	if d.PoW {
+		if d.Difficulty != 0 {
+			return ErrPoWAndDifficulty
+		}
+		required, err := vm.GetBasePlasmaForAccountBlockOffline(block)
+		if err != nil {
+			return err
+		}
+		if required > block.FusedPlasma {
+			if block.Difficulty, err = vm.GetDifficultyForPlasma(required - block.FusedPlasma); err != nil {
+				return ErrPlasmaTooHighForPoW
+			}
+		}
+	}
+	if block.Difficulty != 0 {
+		nonce := pow.GetPoWNonce(new(big.Int).SetUint64(block.Difficulty), pow.GetAccountBlockHash(block))
+		block.Nonce = nom.DeSerializeNonce(nonce)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package signer
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// DefaultProtectionFile is the name of the file, inside the data dir, which holds the last signed momentum
+	DefaultProtectionFile = "signer-protection.json"
+)
+
+// Protection refuses to sign two different momentums in the same slot, which would allow the pillar to fork the chain.
+//
+// Only the last signed momentum is remembered, so momentums of earlier slots are refused as well, since there is no way to
+// tell if a different momentum was already signed in that slot. A momentum identical to the last one can be signed again.
+// The heights are not checked, since a rollback makes the pillar produce momentums at already signed heights in later slots.
+type Protection struct {
+	lock sync.Mutex
+	file string
+	Last *SignedMomentum `json:"lastMomentum"`
+}
+
+// SignedMomentum identifies a signed momentum and its slot
+type SignedMomentum struct {
+	types.HashHeight
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// OpenProtection loads the last signed momentum from file, if the file exists.
+// Every momentum is persisted in file before it is signed. An empty file keeps the protection only in memory.
+func OpenProtection(file string) (*Protection, error) {
+	protection := &Protection{file: file}
+	if file == "" {
+		return protection, nil
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return protection, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, protection); err != nil {
+		return nil, err
+	}
+	return protection, nil
+}
+
+// UseMomentum checks that the momentum can be signed and records it as the last signed one
+func (p *Protection) UseMomentum(momentum *nom.Momentum) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.Last != nil {
+		if momentum.TimestampUnix < p.Last.Timestamp {
+			return ErrSlotBelowLastOne
+		}
+		if momentum.TimestampUnix == p.Last.Timestamp {
+			if momentum.Hash != p.Last.Hash {
+				return ErrDoubleSign
+			}
+			return nil
+		}
+	}
+
+	previous := p.Last
+	p.Last = &SignedMomentum{
+		HashHeight: momentum.Identifier(),
+		Timestamp:  momentum.TimestampUnix,
+	}
+	if err := p.save(); err != nil {
+		p.Last = previous
+		return err
+	}
+	return nil
+}
+func (p *Protection) save() error {
+	if p.file == "" {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	// write in a different file and rename it, so a crash can't leave the protection file corrupted
+	temporary := p.file + ".tmp"
+	f, err := os.OpenFile(temporary, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	// the momentum must be on disk before the signature is released
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary, p.file)
+}
@@ -0,0 +1,123 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// a momentum must be broadcasted within a few seconds of the start of its slot
+	remoteTimeout = 2 * time.Second
+)
+
+type remoteSigner struct {
+	address  types.Address
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewRemoteSigner connects to the signer server at endpoint, which is either an http(s) URL or unix:///path/to/socket,
+// and checks that it signs for address. The token authenticates the node, it can be empty for unix sockets.
+func NewRemoteSigner(endpoint string, address types.Address, token string) (Signer, error) {
+	s := &remoteSigner{
+		address:  address,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: remoteTimeout},
+	}
+	if strings.HasPrefix(endpoint, unixScheme) {
+		socket := strings.TrimPrefix(endpoint, unixScheme)
+		s.endpoint = "http://unix"
+		s.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, "unix", socket)
+			},
+		}
+	} else if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, errors.Errorf("unsupported signer endpoint %v", endpoint)
+	}
+
+	response := new(addressResponse)
+	if err := s.do(http.MethodGet, addressPath, nil, response); err != nil {
+		return nil, errors.Errorf("can't reach remote signer %v. Reason: %v", endpoint, err)
+	}
+	if response.Address != address {
+		return nil, errors.Errorf("remote signer signs for %v but the producer address is %v", response.Address, address)
+	}
+	return s, nil
+}
+
+func (s *remoteSigner) Address() types.Address {
+	return s.address
+}
+func (s *remoteSigner) SignMomentum(momentum *nom.Momentum) ([]byte, ed25519.PublicKey, error) {
+	data, err := momentum.Serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.sign(momentumPath, data, momentum.Hash)
+}
+func (s *remoteSigner) SignAccountBlock(block *nom.AccountBlock) ([]byte, ed25519.PublicKey, error) {
+	data, err := block.Serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.sign(accountBlockPath, data, block.Hash)
+}
+
+// sign sends the request and checks that the returned signature is valid for the producer address
+func (s *remoteSigner) sign(path string, data []byte, hash types.Hash) ([]byte, ed25519.PublicKey, error) {
+	response := new(signResponse)
+	if err := s.do(http.MethodPost, path, &signRequest{Data: data}, response); err != nil {
+		return nil, nil, err
+	}
+	if len(response.PublicKey) != ed25519.PublicKeySize || types.PubKeyToAddress(response.PublicKey) != s.address {
+		return nil, nil, errors.Errorf("remote signer returned a public key which doesn't match %v", s.address)
+	}
+	if !ed25519.Verify(response.PublicKey, hash.Bytes(), response.Signature) {
+		return nil, nil, errors.Errorf("remote signer returned an invalid signature")
+	}
+	return response.Signature, response.PublicKey, nil
+}
+func (s *remoteSigner) do(method, path string, request interface{}, response interface{}) error {
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return err
+		}
+	}
+	httpRequest, err := http.NewRequest(method, s.endpoint+path, &body)
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		httpRequest.Header.Set("Authorization", bearerPrefix+s.token)
+	}
+	httpResponse, err := s.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		failure := new(errorResponse)
+		if err := json.NewDecoder(httpResponse.Body).Decode(failure); err != nil || failure.Error == "" {
+			return errors.Errorf("remote signer failed with status %v", httpResponse.Status)
+		}
+		return errors.Errorf("remote signer refused to sign. Reason: %v", failure.Error)
+	}
+	return json.NewDecoder(httpResponse.Body).Decode(response)
+}
@@ -0,0 +1,156 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	addressPath      = "/address"
+	momentumPath     = "/sign/momentum"
+	accountBlockPath = "/sign/account-block"
+
+	unixScheme = "unix://"
+	// the token is sent in the Authorization header of every request
+	bearerPrefix = "Bearer "
+
+	// requests contain a single momentum or account-block
+	maxRequestSize = 1024 * 1024
+)
+
+var (
+	log = common.WalletLogger.New("submodule", "signer")
+)
+
+type addressResponse struct {
+	Address types.Address `json:"address"`
+}
+type signRequest struct {
+	// Data is the protobuf serialization of the momentum or account-block
+	Data []byte `json:"data"`
+}
+type signResponse struct {
+	Signature []byte            `json:"signature"`
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+}
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer exposes the signer over HTTP, so it can be used by a node through a remote signer.
+// Requests without the token are refused. An empty token disables the check, which is only allowed on unix sockets.
+func NewServer(signer Signer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(addressPath, func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, http.StatusOK, &addressResponse{Address: signer.Address()})
+	})
+	mux.HandleFunc(momentumPath, func(w http.ResponseWriter, r *http.Request) {
+		serveSign(w, r, func(data []byte) ([]byte, ed25519.PublicKey, error) {
+			momentum, err := nom.DeserializeMomentum(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			log.Info("signing momentum", "identifier", momentum.Identifier())
+			return signer.SignMomentum(momentum)
+		})
+	})
+	mux.HandleFunc(accountBlockPath, func(w http.ResponseWriter, r *http.Request) {
+		serveSign(w, r, func(data []byte) ([]byte, ed25519.PublicKey, error) {
+			block, err := nom.DeserializeAccountBlock(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			log.Info("signing account-block", "header", block.Header())
+			return signer.SignAccountBlock(block)
+		})
+	})
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(bearerPrefix+token)) != 1 {
+			log.Warn("refused unauthorized request", "remote", r.RemoteAddr, "path", r.URL.Path)
+			writeResponse(w, http.StatusUnauthorized, &errorResponse{Error: ErrUnauthorized.Error()})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+func serveSign(w http.ResponseWriter, r *http.Request, sign func([]byte) ([]byte, ed25519.PublicKey, error)) {
+	if r.Method != http.MethodPost {
+		writeResponse(w, http.StatusMethodNotAllowed, &errorResponse{Error: "method not allowed"})
+		return
+	}
+	request := new(signRequest)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(request); err != nil {
+		writeResponse(w, http.StatusBadRequest, &errorResponse{Error: err.Error()})
+		return
+	}
+	signature, publicKey, err := sign(request.Data)
+	if err != nil {
+		log.Warn("refused to sign", "reason", err)
+		writeResponse(w, http.StatusForbidden, &errorResponse{Error: err.Error()})
+		return
+	}
+	writeResponse(w, http.StatusOK, &signResponse{Signature: signature, PublicKey: publicKey})
+}
+func writeResponse(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("failed to write response", "reason", err)
+	}
+}
+
+// Listen listens on endpoint, which is either a host:port or unix:///path/to/socket.
+// Since anyone who can reach a TCP endpoint can request signatures, a token is required to listen on one.
+func Listen(endpoint string, token string) (net.Listener, error) {
+	if strings.HasPrefix(endpoint, unixScheme) {
+		socket := strings.TrimPrefix(endpoint, unixScheme)
+		// remove the socket left by a previous run
+		if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, err
+		}
+		// only the user running the signer and the node can use the socket
+		if err := os.Chmod(socket, 0660); err != nil {
+			listener.Close()
+			return nil, err
+		}
+		return listener, nil
+	}
+	if strings.Contains(endpoint, "://") {
+		return nil, errors.Errorf("unsupported signer endpoint %v", endpoint)
+	}
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+	return net.Listen("tcp", endpoint)
+}
+
+// ReadToken reads the token shared by the signer and the node from file
+func ReadToken(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errors.Errorf("signer token file %v is empty", file)
+	}
+	return token, nil
+}
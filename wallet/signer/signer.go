@@ -0,0 +1,75 @@
+// Package signer decouples the pillar producer from its key.
+// The momentums and account-blocks of a pillar can be signed in process, by a local signer, or by a remote signer
+// which runs in another process, possibly on another host, and keeps the producer key off the node host.
+package signer
+
+import (
+	"crypto/ed25519"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+var (
+	ErrHashMismatch     = errors.New("the hash doesn't match the content")
+	ErrWrongProducer    = errors.New("the producer is not the address of the signer")
+	ErrBlockNotAllowed  = errors.New("the signer only signs update calls to embedded contracts")
+	ErrDoubleSign       = errors.New("refusing to sign a different momentum in an already signed slot")
+	ErrSlotBelowLastOne = errors.New("refusing to sign a momentum of a slot before the last signed one")
+	ErrUnauthorized     = errors.New("missing or invalid signer token")
+	ErrTokenRequired    = errors.New("a token is required to serve the signer on a TCP endpoint")
+)
+
+// Signer signs the momentums and account-blocks produced by a pillar
+type Signer interface {
+	Address() types.Address
+	SignMomentum(momentum *nom.Momentum) (signature []byte, publicKey ed25519.PublicKey, err error)
+	SignAccountBlock(block *nom.AccountBlock) (signature []byte, publicKey ed25519.PublicKey, err error)
+}
+
+type localSigner struct {
+	keyPair    *wallet.KeyPair
+	protection *Protection
+}
+
+// NewLocalSigner signs with the key pair held in memory.
+// A nil protection disables the double-sign protection, which is only meant for testing environments.
+func NewLocalSigner(keyPair *wallet.KeyPair, protection *Protection) Signer {
+	return &localSigner{
+		keyPair:    keyPair,
+		protection: protection,
+	}
+}
+
+func (s *localSigner) Address() types.Address {
+	return s.keyPair.Address
+}
+func (s *localSigner) SignMomentum(momentum *nom.Momentum) ([]byte, ed25519.PublicKey, error) {
+	if momentum.ComputeHash() != momentum.Hash {
+		return nil, nil, ErrHashMismatch
+	}
+	if s.protection != nil {
+		if err := s.protection.UseMomentum(momentum); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s.keyPair.Sign(momentum.Hash.Bytes()), s.keyPair.Public, nil
+}
+func (s *localSigner) SignAccountBlock(block *nom.AccountBlock) ([]byte, ed25519.PublicKey, error) {
+	if block.ComputeHash() != block.Hash {
+		return nil, nil, ErrHashMismatch
+	}
+	if block.Address != s.keyPair.Address {
+		return nil, nil, ErrWrongProducer
+	}
+	// the producer only publishes blocks which trigger the update of embedded contracts
+	if block.BlockType != nom.BlockTypeUserSend || !types.IsEmbeddedAddress(block.ToAddress) ||
+		(block.Amount != nil && block.Amount.Cmp(big.NewInt(0)) != 0) {
+		return nil, nil, ErrBlockNotAllowed
+	}
+	return s.keyPair.Sign(block.Hash.Bytes()), s.keyPair.Public, nil
+}
@@ -0,0 +1,131 @@
+package signer
+
+import (
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func newMomentum(height uint64, timestamp uint64) *nom.Momentum {
+	m := &nom.Momentum{
+		ChainIdentifier: 100,
+		Height:          height,
+		TimestampUnix:   timestamp,
+		Version:         1,
+	}
+	m.Hash = m.ComputeHash()
+	return m
+}
+func newUpdateBlock(address types.Address) *nom.AccountBlock {
+	block := &nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   address,
+		ToAddress: types.PillarContract,
+		Data:      definition.ABICommon.PackMethodPanic(definition.UpdateMethodName),
+	}
+	block.Hash = block.ComputeHash()
+	return block
+}
+
+const (
+	testToken = "test-token"
+)
+
+// newRemoteSigner starts a stand-in signer server for g.Pillar1 and connects to it
+func newRemoteSigner(t *testing.T, protectionFile string) Signer {
+	protection, err := OpenProtection(protectionFile)
+	common.FailIfErr(t, err)
+	server := httptest.NewServer(NewServer(NewLocalSigner(g.Pillar1, protection), testToken))
+	t.Cleanup(server.Close)
+
+	signer, err := NewRemoteSigner(server.URL, g.Pillar1.Address, testToken)
+	common.FailIfErr(t, err)
+	return signer
+}
+
+func TestRemoteSigner_Momentum(t *testing.T) {
+	signer := newRemoteSigner(t, "")
+
+	m := newMomentum(10, 1000)
+	signature, publicKey, err := signer.SignMomentum(m)
+	common.FailIfErr(t, err)
+	common.ExpectBytes(t, signature, hexutil.Encode(g.Pillar1.Sign(m.Hash.Bytes())))
+	common.ExpectBytes(t, publicKey, hexutil.Encode(g.Pillar1.Public))
+
+	// the same momentum can be signed again, in case the first response was lost
+	_, _, err = signer.SignMomentum(m)
+	common.FailIfErr(t, err)
+
+	_, _, err = signer.SignMomentum(newMomentum(11, 1000))
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrDoubleSign.Error())
+	_, _, err = signer.SignMomentum(newMomentum(11, 990))
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrSlotBelowLastOne.Error())
+	_, _, err = signer.SignMomentum(newMomentum(11, 1010))
+	common.FailIfErr(t, err)
+	// after a rollback, the momentum at an already signed height is produced in a later slot
+	_, _, err = signer.SignMomentum(newMomentum(10, 1020))
+	common.FailIfErr(t, err)
+
+	tampered := newMomentum(12, 1030)
+	tampered.Height = 13
+	_, _, err = signer.SignMomentum(tampered)
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrHashMismatch.Error())
+}
+func TestRemoteSigner_AccountBlock(t *testing.T) {
+	signer := newRemoteSigner(t, "")
+
+	_, _, err := signer.SignAccountBlock(newUpdateBlock(g.Pillar1.Address))
+	common.FailIfErr(t, err)
+
+	_, _, err = signer.SignAccountBlock(newUpdateBlock(g.Pillar2.Address))
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrWrongProducer.Error())
+
+	block := newUpdateBlock(g.Pillar1.Address)
+	block.ToAddress = g.User1.Address
+	block.Hash = block.ComputeHash()
+	_, _, err = signer.SignAccountBlock(block)
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrBlockNotAllowed.Error())
+}
+func TestRemoteSigner_WrongAddress(t *testing.T) {
+	server := httptest.NewServer(NewServer(NewLocalSigner(g.Pillar1, nil), ""))
+	defer server.Close()
+
+	_, err := NewRemoteSigner(server.URL, g.Pillar2.Address, "")
+	common.ExpectString(t, err.Error(), "remote signer signs for "+g.Pillar1.Address.String()+" but the producer address is "+g.Pillar2.Address.String())
+}
+func TestRemoteSigner_Token(t *testing.T) {
+	server := httptest.NewServer(NewServer(NewLocalSigner(g.Pillar1, nil), testToken))
+	defer server.Close()
+
+	_, err := NewRemoteSigner(server.URL, g.Pillar1.Address, "")
+	common.ExpectString(t, err.Error(), "can't reach remote signer "+server.URL+". Reason: remote signer refused to sign. Reason: "+ErrUnauthorized.Error())
+	_, err = NewRemoteSigner(server.URL, g.Pillar1.Address, "wrong-token")
+	common.ExpectString(t, err.Error(), "can't reach remote signer "+server.URL+". Reason: remote signer refused to sign. Reason: "+ErrUnauthorized.Error())
+
+	_, err = Listen("127.0.0.1:0", "")
+	common.ExpectError(t, err, ErrTokenRequired)
+	listener, err := Listen("127.0.0.1:0", testToken)
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, listener.Close())
+}
+func TestProtection_Persistence(t *testing.T) {
+	file := path.Join(t.TempDir(), DefaultProtectionFile)
+	signer := newRemoteSigner(t, file)
+	_, _, err := signer.SignMomentum(newMomentum(10, 1000))
+	common.FailIfErr(t, err)
+
+	// a restarted signer remembers the last signed momentum
+	signer = newRemoteSigner(t, file)
+	_, _, err = signer.SignMomentum(newMomentum(11, 1000))
+	common.ExpectString(t, err.Error(), "remote signer refused to sign. Reason: "+ErrDoubleSign.Error())
+	_, _, err = signer.SignMomentum(newMomentum(10, 1000))
+	common.FailIfErr(t, err)
+}
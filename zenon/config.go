@@ -3,24 +3,53 @@ package zenon
 import (
 	"path"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
 	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
-	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/wallet/signer"
+)
+
+const (
+	// ChainDir, ConsensusDir and the index dirs are the names of the databases inside DataDir
+	ChainDir        = "nom"
+	ConsensusDir    = "consensus"
+	AddressIndexDir = "index"
+	HolderIndexDir  = "holder-index"
+	HtlcIndexDir    = "htlc-index"
+)
+
+var (
+	// Dirs are the names of all the databases which may be stored inside DataDir
+	Dirs = []string{ChainDir, ConsensusDir, AddressIndexDir, HolderIndexDir, HtlcIndexDir}
 )
 
 type Config struct {
 	MinPeers          int
 	MinConnectedPeers int
 	DataDir           string
-	ProducingKeyPair  *wallet.KeyPair
+	ProducingSigner   signer.Signer
 	GenesisConfig     store.Genesis
+
+	EnableAddressIndex bool
+	EnableHolderIndex  bool
+	EnableHtlcIndex    bool
+	PruneRetention     uint64
+	// DBBackend is the key-value engine of the databases
+	DBBackend db.Backend
 }
 
 func (c *Config) NewDBManager(inside string) db.Manager {
-	return db.NewLevelDBManager(path.Join(c.DataDir, inside))
+	manager, err := db.OpenManager(c.DBBackend, path.Join(c.DataDir, inside))
+	common.DealWithErr(err)
+	return manager
+}
+func (c *Config) NewDB(inside string) (db.DB, db.Storage) {
+	wrapper, storage, err := db.OpenDB(c.DBBackend, path.Join(c.DataDir, inside))
+	common.DealWithErr(err)
+	return wrapper, storage
 }
-func (c *Config) NewLevelDB(inside string) (db.DB, *leveldb.DB) {
-	return db.NewLevelDB(path.Join(c.DataDir, inside))
+func (c *Config) NewIndexDB(inside string) (db.DB, db.Storage) {
+	wrapper, storage, err := db.OpenIndexDB(c.DBBackend, path.Join(c.DataDir, inside))
+	common.DealWithErr(err)
+	return wrapper, storage
 }
@@ -0,0 +1,365 @@
+package integrity
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+var (
+	// ProgressInterval is the number of momentums checked between two calls of the progress callback
+	ProgressInterval = uint64(10000)
+)
+
+// Report is the result of the verification of a data dir.
+// FailedHeight is the height of the first inconsistent momentum and Reason describes the inconsistency.
+// Both are empty if the data dir is consistent.
+type Report struct {
+	Frontier     types.HashHeight
+	PrunedHeight uint64
+	// HistoryHeight is the height of the first momentum with a stored patch. The data dirs seeded from a snapshot or
+	// reindexed from a height don't have the patches of the momentums before, so their changes-hash can't be checked.
+	HistoryHeight uint64
+	Momentums     uint64
+	AccountBlocks uint64
+	Accounts      uint64
+
+	FailedHeight uint64
+	Reason       error
+}
+
+func (r *Report) Consistent() bool {
+	return r.Reason == nil
+}
+
+// Verify walks the momentum chain of the data dir from genesis and reports the first inconsistent momentum.
+// The node must be stopped since the databases are opened exclusively. Nothing is written in the data dir.
+//
+// For each momentum the hash, the signature, the producer and the link to the previous momentum are checked, and the
+// changes-hash is recomputed from the stored patch. The account-blocks confirmed by each momentum are checked to
+// continue the chains of their accounts, and receive-blocks to match the mailboxes. The patches and the account-blocks
+// of the momentums pruned by the node are no longer available, so only the momentums themselves are checked for those.
+// The same applies to the patches of the history which wasn't inserted by the node, see Report.HistoryHeight.
+func Verify(dataDir string, genesis store.Genesis, progress func(height uint64)) (*Report, error) {
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	if _, err := os.Stat(chainDir); err != nil {
+		return nil, errors.Errorf("can't find chain db in %v", dataDir)
+	}
+	manager, err := db.OpenManager("", chainDir)
+	if err != nil {
+		return nil, errors.Errorf("can't open chain db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer manager.Stop()
+
+	consensusStorage, err := db.OpenStorage("", path.Join(dataDir, zenon.ConsensusDir))
+	if err != nil {
+		return nil, errors.Errorf("can't open consensus db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer consensusStorage.Close()
+	// elections missing from the consensus db are computed again, so writes are kept in memory
+	consensusSnapshot, err := consensusStorage.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer consensusSnapshot.Release()
+
+	ch := chain.NewChain(manager, genesis)
+	cs := consensus.NewConsensus(db.NewLevelDBSnapshotWrapper(consensusSnapshot), ch, true)
+
+	c := &checker{
+		frontierStore: ch.GetFrontierMomentumStore(),
+		manager:       manager,
+		verifier:      verifier.NewVerifier(ch, cs),
+		genesis:       genesis.GetGenesisMomentum(),
+		heads:         make(map[types.Address]types.HashHeight),
+		report:        new(Report),
+	}
+	if pruner, ok := manager.(db.Pruner); ok {
+		c.report.PrunedHeight = pruner.PrunedHeight()
+	}
+	if err := c.run(progress); err != nil {
+		return nil, err
+	}
+	return c.report, nil
+}
+
+type checker struct {
+	frontierStore store.Momentum
+	manager       db.Manager
+	verifier      verifier.Verifier
+	genesis       *nom.Momentum
+
+	// heads are the last account-blocks of each account, as confirmed by the momentums checked so far
+	heads  map[types.Address]types.HashHeight
+	report *Report
+}
+
+func (c *checker) run(progress func(height uint64)) error {
+	frontier, err := c.frontierStore.GetFrontierMomentum()
+	if err != nil {
+		return errors.Errorf("can't read frontier momentum. Reason: %v", err)
+	}
+	c.report.Frontier = frontier.Identifier()
+
+	var previous *nom.Momentum
+	for height := uint64(1); height <= frontier.Height; height += 1 {
+		momentum, err := c.checkMomentum(height, previous)
+		if err != nil {
+			c.report.FailedHeight = height
+			c.report.Reason = err
+			return nil
+		}
+		previous = momentum
+		c.report.Momentums += 1
+		if progress != nil && height%ProgressInterval == 0 {
+			progress(height)
+		}
+	}
+
+	c.report.Accounts = uint64(len(c.heads))
+	if err := c.checkAccountFrontiers(); err != nil {
+		c.report.FailedHeight = frontier.Height
+		c.report.Reason = err
+	}
+	return nil
+}
+
+func (c *checker) checkMomentum(height uint64, previous *nom.Momentum) (*nom.Momentum, error) {
+	momentum, err := c.frontierStore.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, errors.Errorf("momentum is missing")
+	}
+	if momentum.Height != height {
+		return nil, errors.Errorf("momentum %v is stored at height %v", momentum.Identifier(), height)
+	}
+
+	if height == 1 {
+		if momentum.Hash != c.genesis.Hash {
+			return nil, errors.Errorf("genesis momentum %v doesn't match the configured genesis %v", momentum.Hash, c.genesis.Hash)
+		}
+	} else {
+		if momentum.Previous() != previous.Identifier() {
+			return nil, errors.Errorf("momentum %v points to previous %v instead of %v", momentum.Identifier(), momentum.Previous(), previous.Identifier())
+		}
+		if momentum.TimestampUnix <= previous.TimestampUnix {
+			return nil, verifier.ErrMTimestampNotIncreasing
+		}
+	}
+
+	if err := c.checkTransaction(momentum); err != nil {
+		return nil, err
+	}
+	if err := c.checkContent(momentum); err != nil {
+		return nil, err
+	}
+	return momentum, nil
+}
+
+// checkTransaction checks the changes-hash, hash, signature and producer of momentum
+func (c *checker) checkTransaction(momentum *nom.Momentum) error {
+	if momentum.Height <= c.report.PrunedHeight {
+		return c.checkSignedMomentum(momentum)
+	}
+
+	patch := c.manager.GetPatch(momentum.Identifier())
+	if patch == nil {
+		if c.report.HistoryHeight == 0 {
+			return c.checkSignedMomentum(momentum)
+		}
+		return errors.Errorf("patch of momentum %v is missing", momentum.Identifier())
+	}
+	if c.report.HistoryHeight == 0 {
+		c.report.HistoryHeight = momentum.Height
+	}
+	changes, err := db.StripFrontier(patch, momentum.Identifier())
+	if err != nil {
+		return err
+	}
+	// the genesis momentum isn't signed
+	if momentum.Height == 1 {
+		if db.PatchHash(changes) != momentum.ChangesHash {
+			return verifier.ErrMChangesHashInvalid
+		}
+		return nil
+	}
+	return c.verifier.MomentumTransaction(&nom.MomentumTransaction{
+		Momentum: momentum,
+		Changes:  changes,
+	})
+}
+
+// checkSignedMomentum checks the hash and signature of a momentum without history,
+// for which the patch was discarded and the election may depend on state which is no longer available
+func (c *checker) checkSignedMomentum(momentum *nom.Momentum) error {
+	if momentum.ComputeHash() != momentum.Hash {
+		return verifier.ErrMHashInvalid
+	}
+	if momentum.Height == 1 {
+		return nil
+	}
+	if verified, err := wallet.VerifySignature(momentum.PublicKey, momentum.Hash.Bytes(), momentum.Signature); err != nil || !verified {
+		return verifier.ErrMSignatureInvalid
+	}
+	return nil
+}
+
+// checkContent checks that the account-blocks confirmed by momentum continue the chains of their accounts
+func (c *checker) checkContent(momentum *nom.Momentum) error {
+	// the content is sorted by header, so the blocks of each account are grouped and put in height order
+	addresses := make([]types.Address, 0)
+	byAddress := make(map[types.Address][]*types.AccountHeader)
+	for _, header := range momentum.Content {
+		if _, ok := byAddress[header.Address]; !ok {
+			addresses = append(addresses, header.Address)
+		}
+		byAddress[header.Address] = append(byAddress[header.Address], header)
+	}
+
+	for _, address := range addresses {
+		headers := byAddress[address]
+		sortByHeight(headers)
+		head := c.heads[address]
+		for _, header := range headers {
+			if header.Height != head.Height+1 {
+				return errors.Errorf("account-block %v of %v doesn't follow height %v", header.Identifier(), address, head.Height)
+			}
+			if momentum.Height > c.report.PrunedHeight {
+				if err := c.checkAccountBlock(momentum, header, head); err != nil {
+					return err
+				}
+			}
+			head = header.Identifier()
+		}
+		c.heads[address] = head
+	}
+	c.report.AccountBlocks += uint64(len(momentum.Content))
+	return nil
+}
+
+func (c *checker) checkAccountBlock(momentum *nom.Momentum, header *types.AccountHeader, previous types.HashHeight) error {
+	block, err := c.frontierStore.GetAccountBlock(*header)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return errors.Errorf("account-block %v of %v is missing", header.Identifier(), header.Address)
+	}
+	if block.Hash != header.Hash {
+		return errors.Errorf("account-block at height %v of %v has hash %v instead of %v", header.Height, header.Address, block.Hash, header.Hash)
+	}
+	if block.ComputeHash() != block.Hash {
+		return errors.Errorf("%v %v", verifier.ErrABHashInvalid, header.Hash)
+	}
+	if err := checkAccountBlockSignature(block); err != nil {
+		return errors.Errorf("%v %v", err, header.Hash)
+	}
+	if block.Previous() != previous {
+		return errors.Errorf("account-block %v of %v points to previous %v instead of %v", header.Identifier(), header.Address, block.Previous(), previous)
+	}
+	confirmation, err := c.frontierStore.GetBlockConfirmationHeight(block.Hash)
+	if err != nil {
+		return err
+	}
+	if confirmation != momentum.Height {
+		return errors.Errorf("account-block %v is confirmed at height %v instead of %v", block.Hash, confirmation, momentum.Height)
+	}
+
+	if block.IsReceiveBlock() && block.BlockType != nom.BlockTypeGenesisReceive {
+		return c.checkMailbox(momentum, block)
+	}
+	return nil
+}
+
+// checkAccountBlockSignature checks that block is signed by the key of its address, the same way the verifier does.
+// The blocks of the embedded contracts and the genesis blocks aren't signed.
+func checkAccountBlockSignature(block *nom.AccountBlock) error {
+	if block.BlockType == nom.BlockTypeGenesisReceive {
+		return nil
+	}
+	if types.IsEmbeddedAddress(block.Address) {
+		if len(block.PublicKey) != 0 {
+			return verifier.ErrABPublicKeyMustBeZero
+		}
+		if len(block.Signature) != 0 {
+			return verifier.ErrABSignatureMustBeZero
+		}
+		return nil
+	}
+	if len(block.Signature) == 0 {
+		return verifier.ErrABSignatureMissing
+	}
+	if len(block.PublicKey) == 0 {
+		return verifier.ErrABPublicKeyMissing
+	}
+	if verified, err := wallet.VerifySignature(block.PublicKey, block.Hash.Bytes(), block.Signature); err != nil || !verified {
+		return verifier.ErrABSignatureInvalid
+	}
+	if types.PubKeyToAddress(block.PublicKey) != block.Address {
+		return verifier.ErrABPublicKeyWrongAddress
+	}
+	return nil
+}
+
+// checkMailbox checks that the send-block received by block exists and that the mailbox records the receive
+func (c *checker) checkMailbox(momentum *nom.Momentum, block *nom.AccountBlock) error {
+	send, err := c.frontierStore.GetAccountBlockByHash(block.FromBlockHash)
+	if err == db.ErrPruned {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if send == nil {
+		return errors.Errorf("account-block %v receives the missing send-block %v", block.Hash, block.FromBlockHash)
+	}
+	if send.ToAddress != block.Address {
+		return errors.Errorf("account-block %v receives the send-block %v which is sent to %v", block.Hash, send.Hash, send.ToAddress)
+	}
+	confirmation, err := c.frontierStore.GetBlockConfirmationHeight(send.Hash)
+	if err != nil {
+		return err
+	}
+	if confirmation == 0 || confirmation > momentum.Height {
+		return errors.Errorf("account-block %v receives the send-block %v which is confirmed later", block.Hash, send.Hash)
+	}
+
+	receive, err := c.frontierStore.GetBlockWhichReceives(send.Hash)
+	if err != nil {
+		return err
+	}
+	if receive == nil || receive.Hash != block.Hash {
+		return errors.Errorf("mailbox doesn't record the send-block %v as received by %v", send.Hash, block.Hash)
+	}
+	return nil
+}
+
+// checkAccountFrontiers checks that the frontier of each account matches the last confirmed account-block
+func (c *checker) checkAccountFrontiers() error {
+	for address, head := range c.heads {
+		if identifier := c.frontierStore.GetAccountStore(address).Identifier(); identifier != head {
+			return errors.Errorf("frontier of %v is %v instead of %v", address, identifier, head)
+		}
+	}
+	return nil
+}
+
+func sortByHeight(headers []*types.AccountHeader) {
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Height < headers[j].Height
+	})
+}
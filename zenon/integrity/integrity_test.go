@@ -0,0 +1,92 @@
+package integrity
+
+import (
+	"math/big"
+	"path"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// newDataDir returns the data dir of a stopped mock node with a few transfers between users and contracts
+func newDataDir(t *testing.T) string {
+	z := mock.NewMockZenon(t)
+	dataDir := z.Config().DataDir
+
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User2.Address),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertMomentumsTo(10)
+	z.StopPanic()
+	return dataDir
+}
+
+func TestVerify(t *testing.T) {
+	dataDir := newDataDir(t)
+	report, err := Verify(dataDir, genesis.NewGenesis(g.EmbeddedGenesis), nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Consistent())
+	common.ExpectUint64(t, report.Momentums, 10)
+	common.ExpectUint64(t, report.AccountBlocks, 22)
+}
+
+func TestVerify_Corrupted(t *testing.T) {
+	dataDir := newDataDir(t)
+	patchKey := func(height uint64) []byte {
+		return common.JoinBytes([]byte{102}, common.Uint64ToBytes(height))
+	}
+
+	ldb, err := leveldb.OpenFile(path.Join(dataDir, zenon.ChainDir), nil)
+	common.FailIfErr(t, err)
+	// add a change to the patch of momentum 7 and drop the patch of momentum 5
+	data, err := ldb.Get(patchKey(7), nil)
+	common.FailIfErr(t, err)
+	original, err := db.NewPatchFromDump(data)
+	common.FailIfErr(t, err)
+	altered := db.NewPatch()
+	altered.Put([]byte{3, 1}, []byte{1})
+	common.FailIfErr(t, original.Replay(altered))
+	common.FailIfErr(t, ldb.Put(patchKey(7), altered.Dump(), nil))
+	dropped, err := ldb.Get(patchKey(5), nil)
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, ldb.Delete(patchKey(5), nil))
+	common.FailIfErr(t, ldb.Close())
+
+	genesisConfig := genesis.NewGenesis(g.EmbeddedGenesis)
+	report, err := Verify(dataDir, genesisConfig, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, report.FailedHeight, 5)
+	common.ExpectString(t, report.Reason.Error(), `patch of momentum {2a194ca5042fbb873d282a9d4c672d8417e91b798df65771ba1b63cafcd07932 5} is missing`)
+
+	ldb, err = leveldb.OpenFile(path.Join(dataDir, zenon.ChainDir), nil)
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, ldb.Put(patchKey(5), dropped, nil))
+	common.FailIfErr(t, ldb.Close())
+	report, err = Verify(dataDir, genesisConfig, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, report.FailedHeight, 7)
+	common.ExpectString(t, report.Reason.Error(), `momentum changes-hash is different than the one computed`)
+}
@@ -2,6 +2,7 @@ package zenon
 
 import (
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
@@ -20,4 +21,10 @@ type Zenon interface {
 	Producer() pillar.Manager
 	Config() *Config
 	Broadcaster() protocol.Broadcaster
+	// AddressIndex returns nil if the address index is not enabled
+	AddressIndex() *index.AddressIndex
+	// HolderIndex returns nil if the holder index is not enabled
+	HolderIndex() *index.HolderIndex
+	// HtlcIndex returns nil if the htlc index is not enabled
+	HtlcIndex() *index.HtlcIndex
 }
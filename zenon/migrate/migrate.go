@@ -0,0 +1,199 @@
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// TempSuffix is appended to the name of a database while it is copied
+	TempSuffix = ".migrate"
+	// swapSuffix is appended to the name of a database for the file which records, once the copy is complete,
+	// that the copy replaces the database
+	swapSuffix = ".swap"
+
+	// number of entries written at once
+	batchSize = 10000
+)
+
+var (
+	// ProgressInterval is the number of entries copied between two calls of the progress callback
+	ProgressInterval = uint64(1000000)
+)
+
+type Result struct {
+	Dir  string
+	From db.Backend
+	// Migrated is false if the database already used the target backend
+	Migrated bool
+	Entries  uint64
+}
+
+type swapJournal struct {
+	From    db.Backend
+	Entries uint64
+}
+
+// BackupDir is the name under which the database in dir is kept once converted from backend
+func BackupDir(dir string, backend db.Backend) string {
+	return dir + "." + string(backend)
+}
+
+// Run converts the databases of the data dir to backend, by copying all their entries. The node must be stopped.
+//
+// Each database is copied next to it with the TempSuffix, so an interrupted migration is started again by calling Run
+// again. Once copied, the new database replaces the current one, which is kept under BackupDir. The swap is recorded
+// next to the database first, so a swap which is interrupted is finished by calling Run again. The databases which
+// already use backend are left unchanged.
+func Run(dataDir string, backend db.Backend, progress func(dir string, entries uint64)) ([]*Result, error) {
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	if _, err := os.Stat(chainDir); err != nil {
+		// the chain db is missing while it is swapped
+		if _, err := os.Stat(chainDir + swapSuffix); err != nil {
+			return nil, errors.Errorf("can't find chain db in %v", dataDir)
+		}
+	}
+
+	results := make([]*Result, 0, len(zenon.Dirs))
+	for _, name := range zenon.Dirs {
+		dir := path.Join(dataDir, name)
+		if data, err := os.ReadFile(dir + swapSuffix); err == nil {
+			journal := new(swapJournal)
+			if err := json.Unmarshal(data, journal); err != nil {
+				return nil, errors.Errorf("can't read the swap journal of %v. Reason: %v", name, err)
+			}
+			if err := swap(dir, journal.From); err != nil {
+				return nil, err
+			}
+			results = append(results, &Result{
+				Dir:      name,
+				From:     journal.From,
+				Migrated: true,
+				Entries:  journal.Entries,
+			})
+			continue
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		from, err := db.DetectBackend(dir)
+		if err != nil {
+			return nil, err
+		}
+		// the indexes are only created if enabled
+		if from == "" {
+			continue
+		}
+		result := &Result{
+			Dir:  name,
+			From: from,
+		}
+		results = append(results, result)
+		if from == backend {
+			continue
+		}
+		if _, err := os.Stat(BackupDir(dir, from)); err == nil {
+			return nil, errors.Errorf("%v already exists. Remove the backup of the previous migration first", BackupDir(dir, from))
+		}
+
+		if result.Entries, err = migrate(dir, from, backend, func(entries uint64) {
+			if progress != nil {
+				progress(name, entries)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(&swapJournal{
+			From:    from,
+			Entries: result.Entries,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(dir+swapSuffix, data); err != nil {
+			return nil, err
+		}
+		if err := swap(dir, from); err != nil {
+			return nil, err
+		}
+		result.Migrated = true
+	}
+	return results, nil
+}
+
+// swap replaces the database in dir with its copy and removes the swap journal. Each step is skipped if it was
+// already done, so an interrupted swap is finished by calling swap again.
+func swap(dir string, from db.Backend) error {
+	if _, err := os.Stat(dir + TempSuffix); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			if err := os.Rename(dir, BackupDir(dir, from)); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(dir+TempSuffix, dir); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(dir + swapSuffix)
+}
+
+// writeFile writes data to file in one step, by writing it to a temporary file first
+func writeFile(file string, data []byte) error {
+	temp, err := os.Create(file + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// migrate copies the database in dir to a new database with the TempSuffix
+func migrate(dir string, from, to db.Backend, progress func(entries uint64)) (uint64, error) {
+	source, err := db.OpenStorage(from, dir)
+	if err != nil {
+		return 0, errors.Errorf("can't open %v. Make sure znnd is stopped. Reason: %v", path.Base(dir), err)
+	}
+	defer source.Close()
+
+	// the copy of an interrupted migration is incomplete
+	if err := os.RemoveAll(dir + TempSuffix); err != nil {
+		return 0, err
+	}
+	target, err := db.OpenStorage(to, dir+TempSuffix)
+	if err != nil {
+		return 0, err
+	}
+
+	reported := uint64(0)
+	entries, err := db.CopyStorage(source, target, batchSize, func(entries uint64) {
+		if entries/ProgressInterval != reported/ProgressInterval {
+			progress(entries)
+		}
+		reported = entries
+	})
+	if err != nil {
+		target.Close()
+		return 0, err
+	}
+	return entries, target.Close()
+}
@@ -0,0 +1,114 @@
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/integrity"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func frontierState(t *testing.T, chainDir string) string {
+	manager, err := db.OpenManager("", chainDir)
+	common.FailIfErr(t, err)
+	defer manager.Stop()
+	return db.DebugDB(manager.Frontier())
+}
+
+func TestMigrate(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	dataDir := z.Config().DataDir
+	z.InsertMomentumsTo(10)
+	z.StopPanic()
+	// the mock node keeps the consensus db in memory
+	_, consensus, err := db.OpenDB(db.LevelDB, path.Join(dataDir, zenon.ConsensusDir))
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, consensus.Close())
+
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	expected := frontierState(t, chainDir)
+
+	results, err := Run(dataDir, db.Pebble, nil)
+	common.FailIfErr(t, err)
+	common.Json(results, nil).Equals(t, `
+[
+	{
+		"Dir": "nom",
+		"From": "leveldb",
+		"Migrated": true,
+		"Entries": 214
+	},
+	{
+		"Dir": "consensus",
+		"From": "leveldb",
+		"Migrated": true,
+		"Entries": 0
+	}
+]`)
+	backend, err := db.DetectBackend(chainDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, string(backend), "pebble")
+	_, err = os.Stat(BackupDir(chainDir, db.LevelDB))
+	common.FailIfErr(t, err)
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+
+	report, err := integrity.Verify(dataDir, genesis.NewGenesis(g.EmbeddedGenesis), nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Consistent())
+	common.ExpectUint64(t, report.Momentums, 10)
+
+	// databases which already use the backend are left unchanged
+	results, err = Run(dataDir, db.Pebble, nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, !results[0].Migrated && !results[1].Migrated)
+
+	// the leveldb backups must be removed before migrating back
+	_, err = Run(dataDir, db.LevelDB, nil)
+	common.FailIfErr(t, err)
+	_, err = Run(dataDir, db.Pebble, nil)
+	common.ExpectString(t, err.Error(), BackupDir(chainDir, db.LevelDB)+" already exists. Remove the backup of the previous migration first")
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+}
+
+func TestMigrate_ResumeSwap(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	dataDir := z.Config().DataDir
+	z.InsertMomentumsTo(10)
+	z.StopPanic()
+
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	expected := frontierState(t, chainDir)
+	_, err := Run(dataDir, db.Pebble, nil)
+	common.FailIfErr(t, err)
+
+	// go back to a swap interrupted after the leveldb database was moved to the backup
+	common.FailIfErr(t, os.Rename(chainDir, chainDir+TempSuffix))
+	data, err := json.Marshal(&swapJournal{From: db.LevelDB, Entries: 214})
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, writeFile(chainDir+swapSuffix, data))
+
+	results, err := Run(dataDir, db.Pebble, nil)
+	common.FailIfErr(t, err)
+	common.Json(results[0], nil).Equals(t, `
+{
+	"Dir": "nom",
+	"From": "leveldb",
+	"Migrated": true,
+	"Entries": 214
+}`)
+	backend, err := db.DetectBackend(chainDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, string(backend), "pebble")
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+	_, err = os.Stat(BackupDir(chainDir, db.LevelDB))
+	common.FailIfErr(t, err)
+	_, err = os.Stat(chainDir + swapSuffix)
+	common.ExpectTrue(t, os.IsNotExist(err))
+}
@@ -13,6 +13,8 @@ type MockZenon interface {
 
 	InsertNewMomentum()
 	InsertMomentumsTo(targetHeight uint64)
+	// SkipMomentumSlots leaves the next count slots without a momentum, as if their producers were offline
+	SkipMomentumSlots(count uint64)
 
 	CallContract(template *nom.AccountBlock) *common.Expecter
 	InsertSendBlock(template *nom.AccountBlock, expectedError error, expectedVmChanges string) *nom.AccountBlock
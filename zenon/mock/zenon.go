@@ -13,6 +13,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/genesis"
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/index"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
@@ -23,6 +24,7 @@ import (
 	"github.com/zenon-network/go-zenon/verifier"
 	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
+	"github.com/zenon-network/go-zenon/wallet/signer"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -118,15 +120,21 @@ func (clock *mockClock) Now() time.Time {
 
 type mockZenon struct {
 	lastTime         *time.Time
+	skippedSlots     uint64
 	t                common.T
 	log              log15.Logger
 	producerLogSaver *ProducerLogSaver
 
+	config     *zenon.Config
 	pillars    []pillar.Manager
 	chain      chain.Chain
 	consensus  consensus.Consensus
 	supervisor *vm.Supervisor
 
+	addressIndex *index.AddressIndex
+	holderIndex  *index.HolderIndex
+	htlcIndex    *index.HtlcIndex
+
 	loggers              []log15.Logger
 	handlers             []log15.Handler
 	initialEpochDuration time.Duration
@@ -171,7 +179,8 @@ func (zenon *mockZenon) InsertNewMomentum() {
 	store := zenon.chain.GetFrontierMomentumStore()
 	previousMomentum, err := store.GetFrontierMomentum()
 	common.DealWithErr(err)
-	t := previousMomentum.Timestamp.Add(time.Second * 10)
+	t := previousMomentum.Timestamp.Add(time.Second * time.Duration(10*(zenon.skippedSlots+1)))
+	zenon.skippedSlots = 0
 	expected, err := zenon.consensus.GetMomentumProducer(t)
 	common.DealWithErr(err)
 	if expected == nil {
@@ -189,6 +198,9 @@ func (zenon *mockZenon) InsertNewMomentum() {
 		}
 	}
 }
+func (zenon *mockZenon) SkipMomentumSlots(count uint64) {
+	zenon.skippedSlots += count
+}
 func (zenon *mockZenon) InsertMomentumsTo(targetHeight uint64) {
 	currentHeight := zenon.chain.GetFrontierMomentumStore().Identifier().Height
 	for i := currentHeight + 1; i <= targetHeight; i += 1 {
@@ -311,12 +323,24 @@ func (zenon *mockZenon) Stop() error {
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Stop())
 	}
+	if zenon.addressIndex != nil {
+		common.DealWithErr(zenon.addressIndex.Stop())
+	}
+	if zenon.holderIndex != nil {
+		common.DealWithErr(zenon.holderIndex.Stop())
+	}
+	if zenon.htlcIndex != nil {
+		common.DealWithErr(zenon.htlcIndex.Stop())
+	}
 	common.DealWithErr(zenon.consensus.Stop())
 	common.DealWithErr(zenon.chain.Stop())
 
 	zenon.chain = nil
 	zenon.consensus = nil
 	zenon.pillars = nil
+	zenon.addressIndex = nil
+	zenon.holderIndex = nil
+	zenon.htlcIndex = nil
 
 	for i := range zenon.loggers {
 		zenon.loggers[i].SetHandler(zenon.handlers[i])
@@ -346,11 +370,20 @@ func (zenon *mockZenon) Producer() pillar.Manager {
 	return nil
 }
 func (zenon *mockZenon) Config() *zenon.Config {
-	return nil
+	return zenon.config
 }
 func (zenon *mockZenon) Broadcaster() protocol.Broadcaster {
 	return zenon
 }
+func (zenon *mockZenon) AddressIndex() *index.AddressIndex {
+	return zenon.addressIndex
+}
+func (zenon *mockZenon) HolderIndex() *index.HolderIndex {
+	return zenon.holderIndex
+}
+func (zenon *mockZenon) HtlcIndex() *index.HtlcIndex {
+	return zenon.htlcIndex
+}
 
 func NewMockZenon(t common.T) MockZenon {
 	return newMockZenon(t, consensus.EpochDuration)
@@ -358,6 +391,38 @@ func NewMockZenon(t common.T) MockZenon {
 func NewMockZenonWithCustomEpochDuration(t common.T, epochDuration time.Duration) MockZenon {
 	return newMockZenon(t, epochDuration)
 }
+func NewMockZenonWithAddressIndex(t common.T) MockZenon {
+	mock := newMockZenon(t, consensus.EpochDuration).(*mockZenon)
+	_, storage := mock.config.NewIndexDB(zenon.AddressIndexDir)
+	mock.addressIndex = index.NewAddressIndex(mock.chain, storage)
+	common.DealWithErr(mock.addressIndex.Init())
+	common.DealWithErr(mock.addressIndex.Start())
+	<-mock.addressIndex.CaughtUp()
+	return mock
+}
+func NewMockZenonWithHolderIndex(t common.T) MockZenon {
+	mock := newMockZenon(t, consensus.EpochDuration).(*mockZenon)
+	_, storage := mock.config.NewIndexDB(zenon.HolderIndexDir)
+	mock.holderIndex = index.NewHolderIndex(mock.chain, storage)
+	common.DealWithErr(mock.holderIndex.Init())
+	common.DealWithErr(mock.holderIndex.Start())
+	<-mock.holderIndex.Built()
+	return mock
+}
+func NewMockZenonWithHtlcIndex(t common.T) MockZenon {
+	mock := newMockZenon(t, consensus.EpochDuration).(*mockZenon)
+	_, storage := mock.config.NewIndexDB(zenon.HtlcIndexDir)
+	mock.htlcIndex = index.NewHtlcIndex(mock.chain, storage)
+	common.DealWithErr(mock.htlcIndex.Init())
+	common.DealWithErr(mock.htlcIndex.Start())
+	<-mock.htlcIndex.CaughtUp()
+	return mock
+}
+func NewMockZenonWithPruning(t common.T, retention uint64) MockZenon {
+	zenon := newMockZenon(t, consensus.EpochDuration).(*mockZenon)
+	common.DealWithErr(zenon.chain.SetPruneRetention(retention))
+	return zenon
+}
 
 func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 	// silence loggers
@@ -366,12 +431,17 @@ func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 	common.SupervisorLogger.SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.StderrHandler))
 	consensus.EpochDuration = customEpochDuration
 
-	ch := chain.NewChain(db.NewLevelDBManager(t.TempDir()), genesis.NewGenesis(g.EmbeddedGenesis))
+	config := &zenon.Config{
+		DataDir:       t.TempDir(),
+		GenesisConfig: genesis.NewGenesis(g.EmbeddedGenesis),
+	}
+	ch := chain.NewChain(config.NewDBManager(zenon.ChainDir), config.GenesisConfig)
 	cs := consensus.NewConsensus(db.NewMemDB(), ch, true)
 	supervisor := vm.NewSupervisor(ch, cs)
 	zenon := &mockZenon{
 		t:                    t,
 		log:                  common.ZenonLogger,
+		config:               config,
 		chain:                ch,
 		consensus:            cs,
 		supervisor:           supervisor,
@@ -407,7 +477,8 @@ func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 	pillars := make([]pillar.Manager, len(g.PillarKeys))
 	for i, key := range g.PillarKeys {
 		pillars[i] = pillar.NewPillar(ch, cs, zenon)
-		pillars[i].SetCoinBase(key)
+		// no double-sign protection, since the momentums which are rolled back are produced again in the same slots
+		pillars[i].SetSigner(signer.NewLocalSigner(key, nil))
 	}
 	zenon.pillars = pillars
 
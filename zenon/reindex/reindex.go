@@ -0,0 +1,395 @@
+package reindex
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/consensus/storage"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// Dir is the directory of the data dir in which the databases are rebuilt
+	Dir = "reindex"
+	// BackupSuffix is appended to the names of the databases replaced by the rebuilt ones
+	BackupSuffix = ".old"
+
+	// number of momentums inserted at once
+	batchSize = uint64(100)
+
+	// seededFile is written in Dir once the target databases are seeded, so a reindex is only resumed on top of a
+	// complete state
+	seededFile = "seeded"
+	// swapFile is written in Dir, with the result, before the rebuilt databases replace the current ones, so an
+	// interrupted swap is finished by the next Run
+	swapFile = "swap"
+)
+
+var (
+	// ProgressInterval is the number of momentums replayed between two calls of the progress callback
+	ProgressInterval = uint64(10000)
+)
+
+type Config struct {
+	DataDir string
+	Genesis store.Genesis
+	// From is the height of the first replayed momentum. The state before it is copied from the current databases.
+	// 0 replays all the momentums which weren't pruned.
+	From uint64
+	// PruneRetention is the pruning retention of the node, 0 if the node keeps the full history
+	PruneRetention uint64
+}
+
+type Result struct {
+	// From is the height of the first replayed momentum
+	From uint64
+	// Resumed is set if an interrupted reindex was resumed
+	Resumed  bool
+	Frontier types.HashHeight
+}
+
+type swapJournal struct {
+	From     uint64
+	Frontier types.HashHeight
+}
+
+// Run rebuilds the chain state and the consensus db of the data dir by replaying the momentums and account-blocks
+// stored in the data dir, the same way they are inserted when syncing. The node must be stopped.
+//
+// The databases are rebuilt in the Dir directory of the data dir, so an interrupted reindex is resumed by calling Run
+// again. Once all the momentums are replayed, the rebuilt databases replace the current ones, which are kept with the
+// BackupSuffix. The swap is recorded in Dir first, so a swap which is interrupted is finished by calling Run again.
+func Run(config *Config, progress func(height uint64)) (*Result, error) {
+	chainDir := path.Join(config.DataDir, zenon.ChainDir)
+	consensusDir := path.Join(config.DataDir, zenon.ConsensusDir)
+	reindexDir := path.Join(config.DataDir, Dir)
+
+	if data, err := os.ReadFile(path.Join(reindexDir, swapFile)); err == nil {
+		journal := new(swapJournal)
+		if err := json.Unmarshal(data, journal); err != nil {
+			return nil, errors.Errorf("can't read the swap journal of the reindex in %v. Reason: %v", reindexDir, err)
+		}
+		if err := swap(config.DataDir); err != nil {
+			return nil, err
+		}
+		return &Result{
+			From:     journal.From,
+			Resumed:  true,
+			Frontier: journal.Frontier,
+		}, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if _, err := os.Stat(chainDir); err != nil {
+		return nil, errors.Errorf("can't find chain db in %v", config.DataDir)
+	}
+	for _, dir := range []string{chainDir, consensusDir} {
+		if _, err := os.Stat(dir + BackupSuffix); err == nil {
+			return nil, errors.Errorf("%v already exists. Remove the backup of the previous reindex first", dir+BackupSuffix)
+		}
+	}
+
+	// the databases are rebuilt with the backend of the current ones
+	backend, err := db.DetectBackend(chainDir)
+	if err != nil {
+		return nil, err
+	}
+	source, err := db.OpenManager(backend, chainDir)
+	if err != nil {
+		return nil, errors.Errorf("can't open chain db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	result, err := run(config, backend, source, progress)
+	if err := source.Stop(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&swapJournal{
+		From:     result.From,
+		Frontier: result.Frontier,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path.Join(reindexDir, swapFile), data); err != nil {
+		return nil, err
+	}
+	return result, swap(config.DataDir)
+}
+
+// swap replaces the databases of the data dir with the rebuilt ones. Each step is skipped if it was already done,
+// so an interrupted swap is finished by calling swap again.
+func swap(dataDir string) error {
+	reindexDir := path.Join(dataDir, Dir)
+	for _, name := range []string{zenon.ChainDir, zenon.ConsensusDir} {
+		dir := path.Join(dataDir, name)
+		rebuilt := path.Join(reindexDir, name)
+		if _, err := os.Stat(rebuilt); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// the consensus db is created by the node on start, so it may be missing
+		if _, err := os.Stat(dir); err == nil {
+			if err := os.Rename(dir, dir+BackupSuffix); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(rebuilt, dir); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(reindexDir)
+}
+
+// writeFile writes data to file in one step, by writing it to a temporary file first
+func writeFile(file string, data []byte) error {
+	temp, err := os.Create(file + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func run(config *Config, backend db.Backend, source db.Manager, progress func(height uint64)) (*Result, error) {
+	reindexDir := path.Join(config.DataDir, Dir)
+	targetChainDir := path.Join(reindexDir, zenon.ChainDir)
+	targetConsensusDir := path.Join(reindexDir, zenon.ConsensusDir)
+
+	sourceStore := momentum.NewStore(nil, source.Frontier())
+	sourceFrontier, err := sourceStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(Result)
+	if _, err := os.Stat(reindexDir); err == nil {
+		if config.From != 0 {
+			return nil, errors.Errorf("a reindex is already in progress in %v. Run it without a height to resume it, or remove the directory to start again", reindexDir)
+		}
+		if _, err := os.Stat(path.Join(reindexDir, seededFile)); os.IsNotExist(err) {
+			return nil, errors.Errorf("the reindex in %v was interrupted before the state was copied. Remove the directory to start again", reindexDir)
+		} else if err != nil {
+			return nil, err
+		}
+		result.Resumed = true
+	} else {
+		from := config.From
+		if pruner, ok := source.(db.Pruner); ok && pruner.PrunedHeight() != 0 {
+			// the account-blocks of pruned momentums can't be replayed
+			if from == 0 {
+				from = pruner.PrunedHeight() + 1
+			} else if from <= pruner.PrunedHeight() {
+				return nil, errors.Errorf("momentums up to height %v were pruned. The reindex must start after them", pruner.PrunedHeight())
+			}
+		}
+		if from > sourceFrontier.Height {
+			return nil, errors.Errorf("can't start the reindex at height %v. The frontier momentum is at height %v", from, sourceFrontier.Height)
+		}
+		if err := os.MkdirAll(reindexDir, 0700); err != nil {
+			return nil, err
+		}
+		if from > 1 {
+			if err := seed(config, backend, source, sourceStore, from, targetChainDir, targetConsensusDir); err != nil {
+				// leave the data dir as it was, so the reindex can be started again
+				os.RemoveAll(reindexDir)
+				return nil, err
+			}
+		}
+		if err := writeFile(path.Join(reindexDir, seededFile), common.Uint64ToBytes(from)); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := db.OpenManager(backend, targetChainDir)
+	if err != nil {
+		return nil, err
+	}
+	consensusDB, consensusStorage, err := db.OpenDB(backend, targetConsensusDir)
+	if err != nil {
+		target.Stop()
+		return nil, err
+	}
+	defer consensusStorage.Close()
+
+	ch := chain.NewChain(target, config.Genesis)
+	defer ch.Stop()
+	if err := ch.SetPruneRetention(config.PruneRetention); err != nil {
+		return nil, err
+	}
+	if err := ch.Init(); err != nil {
+		return nil, err
+	}
+	cs := consensus.NewConsensus(consensusDB, ch, true)
+	if err := cs.Init(); err != nil {
+		return nil, err
+	}
+	// registers the consensus points and elections, which are computed as momentums are inserted
+	if err := cs.Start(); err != nil {
+		return nil, err
+	}
+	defer cs.Stop()
+
+	frontier, err := ch.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	result.From = frontier.Height + 1
+	stored, err := sourceStore.GetMomentumByHeight(frontier.Height)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.Hash != frontier.Hash {
+		return nil, errors.Errorf("the reindexed momentum %v isn't part of the chain", frontier.Identifier())
+	}
+
+	bridge := protocol.NewChainBridge(ch, cs, verifier.NewVerifier(ch, cs), vm.NewSupervisor(ch, cs))
+	for height := frontier.Height + 1; height <= sourceFrontier.Height; height += batchSize {
+		count := sourceFrontier.Height - height + 1
+		if count > batchSize {
+			count = batchSize
+		}
+		momentums, err := sourceStore.GetMomentumsByHeight(height, true, count)
+		if err != nil {
+			return nil, err
+		}
+		detailed := make([]*nom.DetailedMomentum, len(momentums))
+		for index, m := range momentums {
+			if m == nil {
+				return nil, errors.Errorf("momentum at height %v is missing", height+uint64(index))
+			}
+			if detailed[index], err = sourceStore.PrefetchMomentum(m); err != nil {
+				return nil, err
+			}
+		}
+		if index, err := bridge.InsertChain(detailed); err != nil {
+			return nil, errors.Errorf("failed to replay momentum at height %v. Reason: %v", height+uint64(index), err)
+		}
+
+		last := height + uint64(len(momentums)) - 1
+		if progress != nil && last/ProgressInterval != (height-1)/ProgressInterval {
+			progress(last)
+		}
+	}
+
+	result.Frontier = sourceFrontier.Identifier()
+	return result, nil
+}
+
+// seed writes in the target databases the state before the momentum at height from, with the history needed to
+// verify the next momentums, and the consensus points of the ticks which ended before it
+func seed(config *Config, backend db.Backend, source db.Manager, sourceStore store.Momentum, from uint64, chainDir, consensusDir string) error {
+	previous, err := sourceStore.GetMomentumByHeight(from - 1)
+	if err != nil {
+		return err
+	}
+	first, err := sourceStore.GetMomentumByHeight(from)
+	if err != nil {
+		return err
+	}
+	state := source.Get(previous.Identifier())
+	if state == nil {
+		return errors.Errorf("state at momentum %v is no longer retained by this node", previous.Identifier())
+	}
+	sourceSeeder, ok := source.(db.Seeder)
+	if !ok {
+		return errors.Errorf("chain db doesn't support seeding")
+	}
+
+	target, err := db.OpenManager(backend, chainDir)
+	if err != nil {
+		return err
+	}
+	defer target.Stop()
+	seeder, ok := target.(db.Seeder)
+	if !ok {
+		return errors.Errorf("chain db doesn't support seeding")
+	}
+	if err := seeder.Seed(state.NewIterator(nil)); err != nil {
+		return err
+	}
+	historyFrom := uint64(1)
+	if previous.Height > chain.MinPruneRetention {
+		historyFrom = previous.Height - chain.MinPruneRetention + 1
+	}
+	if pruner, ok := source.(db.Pruner); ok && historyFrom <= pruner.PrunedHeight() {
+		historyFrom = pruner.PrunedHeight() + 1
+	}
+	if err := seeder.SeedRollbacks(sourceSeeder.Rollbacks(historyFrom, previous.Height)); err != nil {
+		return err
+	}
+
+	return seedPoints(config, backend, *first.Timestamp, consensusDir)
+}
+
+// seedPoints copies the consensus points of the ticks which ended before timestamp. Election results are computed again.
+func seedPoints(config *Config, backend db.Backend, timestamp time.Time, consensusDir string) error {
+	source, err := db.OpenStorage(backend, path.Join(config.DataDir, zenon.ConsensusDir))
+	if err != nil {
+		return errors.Errorf("can't open consensus db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer source.Close()
+	target, err := db.OpenStorage(backend, consensusDir)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	genesisTime := *config.Genesis.GetGenesisMomentum().Timestamp
+	periodTicker := common.NewTicker(genesisTime, time.Second*time.Duration(uint64(constants.ConsensusConfig.BlockTime)*uint64(constants.ConsensusConfig.NodeCount)))
+	epochTicker := common.NewTicker(genesisTime, consensus.EpochDuration)
+	limits := map[byte]uint64{
+		storage.PrefixPeriodPoint: periodTicker.ToTick(timestamp),
+		storage.PrefixEpochPoint:  epochTicker.ToTick(timestamp),
+	}
+
+	batch := new(leveldb.Batch)
+	for prefix, limit := range limits {
+		iterator := source.NewIterator(nil, nil)
+		for ok := iterator.Seek(storage.CreatePointKey(prefix, 0)); ok; ok = iterator.Next() {
+			key := iterator.Key()
+			if len(key) != 9 || key[0] != prefix || common.BytesToUint64(key[1:]) >= limit {
+				break
+			}
+			batch.Put(key, iterator.Value())
+		}
+		iterator.Release()
+		if err := iterator.Error(); err != nil {
+			return err
+		}
+	}
+	return target.Write(batch, nil)
+}
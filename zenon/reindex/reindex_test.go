@@ -0,0 +1,154 @@
+package reindex
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/integrity"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// newDataDir returns the data dir of a stopped mock node with a few transfers between users and contracts
+func newDataDir(t *testing.T) string {
+	z := mock.NewMockZenon(t)
+	dataDir := z.Config().DataDir
+
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User2.Address),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertMomentumsTo(20)
+	z.StopPanic()
+	return dataDir
+}
+
+func frontierState(t *testing.T, chainDir string) string {
+	manager, err := db.OpenLevelDBManager(chainDir)
+	common.FailIfErr(t, err)
+	defer manager.Stop()
+	return db.DebugDB(manager.Frontier())
+}
+
+func TestReindex(t *testing.T) {
+	dataDir := newDataDir(t)
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	expected := frontierState(t, chainDir)
+	genesisConfig := genesis.NewGenesis(g.EmbeddedGenesis)
+
+	result, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig}, nil)
+	common.FailIfErr(t, err)
+	common.Json(result, nil).Equals(t, `
+{
+	"From": 2,
+	"Resumed": false,
+	"Frontier": {
+		"hash": "c0c59d512160745da66af4b9005016dde910602d68685461df5d1da2f800d0c6",
+		"height": 20
+	}
+}`)
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+	_, err = os.Stat(chainDir + BackupSuffix)
+	common.FailIfErr(t, err)
+
+	_, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig}, nil)
+	common.ExpectString(t, err.Error(), chainDir+BackupSuffix+" already exists. Remove the backup of the previous reindex first")
+	common.FailIfErr(t, os.RemoveAll(chainDir+BackupSuffix))
+	common.FailIfErr(t, os.RemoveAll(path.Join(dataDir, zenon.ConsensusDir)+BackupSuffix))
+
+	result, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig, From: 8}, nil)
+	common.FailIfErr(t, err)
+	common.Json(result, nil).Equals(t, `
+{
+	"From": 8,
+	"Resumed": false,
+	"Frontier": {
+		"hash": "c0c59d512160745da66af4b9005016dde910602d68685461df5d1da2f800d0c6",
+		"height": 20
+	}
+}`)
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+
+	report, err := integrity.Verify(dataDir, genesisConfig, nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Consistent())
+	// the patches before the reindexed height are not available
+	common.ExpectUint64(t, report.HistoryHeight, 8)
+}
+
+func TestReindex_ResumeSwap(t *testing.T) {
+	dataDir := newDataDir(t)
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	consensusDir := path.Join(dataDir, zenon.ConsensusDir)
+	reindexDir := path.Join(dataDir, Dir)
+	expected := frontierState(t, chainDir)
+	genesisConfig := genesis.NewGenesis(g.EmbeddedGenesis)
+
+	result, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig}, nil)
+	common.FailIfErr(t, err)
+
+	// go back to a swap interrupted after the chain db was moved to the backup
+	common.FailIfErr(t, os.MkdirAll(reindexDir, 0700))
+	common.FailIfErr(t, os.Rename(chainDir, path.Join(reindexDir, zenon.ChainDir)))
+	common.FailIfErr(t, os.Rename(consensusDir, path.Join(reindexDir, zenon.ConsensusDir)))
+	data, err := json.Marshal(&swapJournal{From: result.From, Frontier: result.Frontier})
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, writeFile(path.Join(reindexDir, swapFile), data))
+
+	result, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig}, nil)
+	common.FailIfErr(t, err)
+	common.Json(result, nil).Equals(t, `
+{
+	"From": 2,
+	"Resumed": true,
+	"Frontier": {
+		"hash": "c0c59d512160745da66af4b9005016dde910602d68685461df5d1da2f800d0c6",
+		"height": 20
+	}
+}`)
+	common.ExpectString(t, frontierState(t, chainDir), expected)
+	for _, dir := range []string{chainDir + BackupSuffix, consensusDir} {
+		_, err = os.Stat(dir)
+		common.FailIfErr(t, err)
+	}
+	_, err = os.Stat(reindexDir)
+	common.ExpectTrue(t, os.IsNotExist(err))
+}
+
+func TestReindex_InterruptedSeed(t *testing.T) {
+	dataDir := newDataDir(t)
+	reindexDir := path.Join(dataDir, Dir)
+	genesisConfig := genesis.NewGenesis(g.EmbeddedGenesis)
+
+	// a seed which was interrupted leaves the target databases without the seeded file
+	common.FailIfErr(t, os.MkdirAll(path.Join(reindexDir, zenon.ChainDir), 0700))
+	_, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig}, nil)
+	common.ExpectString(t, err.Error(), "the reindex in "+reindexDir+" was interrupted before the state was copied. Remove the directory to start again")
+
+	common.FailIfErr(t, os.RemoveAll(reindexDir))
+	result, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig, From: 8}, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, result.From, 8)
+}
@@ -0,0 +1,183 @@
+package rollback
+
+import (
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+type Config struct {
+	DataDir string
+	Genesis store.Genesis
+	// To is the height or the hash of the momentum which becomes the frontier momentum
+	To string
+	// Force allows rolling back stable momentums
+	Force bool
+}
+
+type Result struct {
+	// Previous is the frontier momentum before the rollback
+	Previous types.HashHeight `json:"previous"`
+	Frontier types.HashHeight `json:"frontier"`
+	// Momentums is the number of momentums rolled back
+	Momentums uint64 `json:"momentums"`
+}
+
+// Rollback removes the momentums of the chain which follow the momentum to, given by its height or its hash. The
+// account-blocks confirmed by the removed momentums are removed with them, and the momentum listeners, such as the
+// consensus points and the indexes, discard what was computed from them.
+//
+// Momentums which are more than chain.StableDepth momentums below the frontier are stable, so they are only rolled
+// back if force is set. Momentums can't be rolled back past the state retained by the node.
+func Rollback(ch chain.Chain, to string, force bool) (*Result, error) {
+	insert := ch.AcquireInsert("rollback")
+	defer insert.Unlock()
+
+	momentumStore := ch.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	target, err := findMomentum(momentumStore, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Previous:  frontier.Identifier(),
+		Frontier:  target.Identifier(),
+		Momentums: frontier.Height - target.Height,
+	}
+	if target.Height == frontier.Height {
+		return result, nil
+	}
+	if result.Momentums > chain.StableDepth && !force {
+		return nil, errors.Errorf("momentum %v is %v momentums below the frontier momentum, past the last %v momentums which aren't stable. Force the rollback to remove stable momentums", target.Identifier(), result.Momentums, chain.StableDepth)
+	}
+	if ch.GetMomentumStore(target.Identifier()) == nil {
+		return nil, errors.Errorf("state at momentum %v is no longer retained by this node", target.Identifier())
+	}
+
+	if err := ch.RollbackTo(insert, target.Identifier()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findMomentum returns the momentum of the chain with the given height or hash
+func findMomentum(momentumStore store.Momentum, to string) (*nom.Momentum, error) {
+	var momentum *nom.Momentum
+	if height, err := strconv.ParseUint(to, 10, 64); err == nil {
+		if momentum, err = momentumStore.GetMomentumByHeight(height); err != nil {
+			return nil, err
+		}
+	} else if hash, err := types.HexToHash(to); err == nil {
+		if momentum, err = momentumStore.GetMomentumByHash(hash); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, errors.Errorf("%v is neither a momentum height nor a momentum hash", to)
+	}
+
+	if momentum == nil {
+		return nil, errors.Errorf("momentum %v is not part of the chain", to)
+	}
+	return momentum, nil
+}
+
+// Run rolls back the chain of the data dir, the same way Rollback does on a running node. The node must be stopped.
+//
+// The consensus db and the address index, if enabled, are rolled back with the chain. The holder index is built again
+// from the frontier state when the node starts.
+func Run(config *Config) (*Result, error) {
+	chainDir := path.Join(config.DataDir, zenon.ChainDir)
+	if _, err := os.Stat(chainDir); err != nil {
+		return nil, errors.Errorf("can't find chain db in %v", config.DataDir)
+	}
+
+	// the consensus db is created by the node on start, so it's opened with the backend of the chain db
+	backend, err := db.DetectBackend(chainDir)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := db.OpenManager(backend, chainDir)
+	if err != nil {
+		return nil, errors.Errorf("can't open chain db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	consensusDB, consensusStorage, err := db.OpenDB(backend, path.Join(config.DataDir, zenon.ConsensusDir))
+	if err != nil {
+		manager.Stop()
+		return nil, errors.Errorf("can't open consensus db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer consensusStorage.Close()
+
+	ch := chain.NewChain(manager, config.Genesis)
+	defer ch.Stop()
+	if err := ch.Init(); err != nil {
+		return nil, err
+	}
+	cs := consensus.NewConsensus(consensusDB, ch, true)
+	if err := cs.Init(); err != nil {
+		return nil, err
+	}
+	// registers the consensus points, which discard the points of the removed momentums
+	if err := cs.Start(); err != nil {
+		return nil, err
+	}
+	defer cs.Stop()
+
+	addressIndex, err := openAddressIndex(config.DataDir, ch)
+	if err != nil {
+		return nil, err
+	}
+	if addressIndex != nil {
+		defer addressIndex.Stop()
+	}
+
+	return Rollback(ch, config.To, config.Force)
+}
+
+// openAddressIndex starts the address index of the data dir, if it's up to date with the chain, so it removes the
+// entries of the removed momentums. Otherwise, the index catches up or is built again when the node starts.
+func openAddressIndex(dataDir string, ch chain.Chain) (*index.AddressIndex, error) {
+	dir := path.Join(dataDir, zenon.AddressIndexDir)
+	backend, err := db.DetectBackend(dir)
+	if err != nil || backend == "" {
+		return nil, err
+	}
+	storage, err := db.OpenStorage(backend, dir)
+	if err != nil {
+		return nil, errors.Errorf("can't open address index. Reason: %v", err)
+	}
+	addressIndex := index.NewAddressIndex(ch, storage)
+	if err := addressIndex.Init(); err != nil {
+		storage.Close()
+		return nil, err
+	}
+	indexFrontier, err := addressIndex.Frontier()
+	if err != nil {
+		storage.Close()
+		return nil, err
+	}
+	if *indexFrontier != ch.GetFrontierMomentumStore().Identifier() {
+		return nil, storage.Close()
+	}
+
+	if err := addressIndex.Start(); err != nil {
+		addressIndex.Stop()
+		return nil, err
+	}
+	<-addressIndex.CaughtUp()
+	return addressIndex, nil
+}
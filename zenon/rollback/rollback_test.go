@@ -0,0 +1,107 @@
+package rollback
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus/storage"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/integrity"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+	"github.com/zenon-network/go-zenon/zenon/reindex"
+)
+
+// newDataDir returns the data dir of a stopped mock node with a transfer confirmed by momentum 42,
+// and with the consensus points of the completed periods
+func newDataDir(t *testing.T) string {
+	z := mock.NewMockZenon(t)
+	dataDir := z.Config().DataDir
+
+	z.InsertMomentumsTo(40)
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertMomentumsTo(70)
+	z.StopPanic()
+
+	// the mock node keeps the consensus db in memory, the reindex computes the points again on disk
+	_, err := reindex.Run(&reindex.Config{DataDir: dataDir, Genesis: genesis.NewGenesis(g.EmbeddedGenesis)}, nil)
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, os.RemoveAll(path.Join(dataDir, zenon.ChainDir)+reindex.BackupSuffix))
+	common.FailIfErr(t, os.RemoveAll(path.Join(dataDir, zenon.ConsensusDir)+reindex.BackupSuffix))
+	return dataDir
+}
+
+// stateAt returns the state of the chain at the momentum at height, and the identifier of the momentum
+func stateAt(t *testing.T, chainDir string, height uint64) (string, types.HashHeight) {
+	manager, err := db.OpenManager("", chainDir)
+	common.FailIfErr(t, err)
+	defer manager.Stop()
+	m, err := momentum.NewStore(nil, manager.Frontier()).GetMomentumByHeight(height)
+	common.FailIfErr(t, err)
+	return db.DebugDB(manager.Get(m.Identifier())), m.Identifier()
+}
+
+// periodPoints returns the ticks of the period points stored in the consensus db
+func periodPoints(t *testing.T, dataDir string) []uint64 {
+	consensusDB, consensusStorage, err := db.OpenDB("", path.Join(dataDir, zenon.ConsensusDir))
+	common.FailIfErr(t, err)
+	defer consensusStorage.Close()
+	ticks := make([]uint64, 0)
+	for tick := uint64(0); tick < 10; tick += 1 {
+		if _, err := consensusDB.Get(storage.CreatePointKey(storage.PrefixPeriodPoint, tick)); err == nil {
+			ticks = append(ticks, tick)
+		}
+	}
+	return ticks
+}
+
+func TestRollback(t *testing.T) {
+	dataDir := newDataDir(t)
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	genesisConfig := genesis.NewGenesis(g.EmbeddedGenesis)
+	expected, identifier := stateAt(t, chainDir, 35)
+	common.ExpectString(t, fmt.Sprint(periodPoints(t, dataDir)), "[0 1]")
+
+	_, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig, To: "71"})
+	common.ExpectString(t, err.Error(), "momentum 71 is not part of the chain")
+	_, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig, To: "momentum"})
+	common.ExpectString(t, err.Error(), "momentum is neither a momentum height nor a momentum hash")
+	_, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig, To: "35"})
+	common.ExpectString(t, err.Error(), fmt.Sprintf("momentum %v is 35 momentums below the frontier momentum, past the last 30 momentums which aren't stable. Force the rollback to remove stable momentums", identifier))
+
+	result, err := Run(&Config{DataDir: dataDir, Genesis: genesisConfig, To: identifier.Hash.String(), Force: true})
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, result.Momentums, 35)
+	common.ExpectTrue(t, result.Frontier == identifier)
+	// the transfer confirmed after momentum 35 is rolled back with it
+	state, _ := stateAt(t, chainDir, 35)
+	common.ExpectString(t, state, expected)
+	// the period which contains momentum 36 is no longer completed
+	common.ExpectString(t, fmt.Sprint(periodPoints(t, dataDir)), "[0]")
+
+	report, err := integrity.Verify(dataDir, genesisConfig, nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Consistent())
+	common.ExpectUint64(t, report.Momentums, 35)
+
+	// rolling back to the frontier momentum changes nothing
+	result, err = Run(&Config{DataDir: dataDir, Genesis: genesisConfig, To: "35"})
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, result.Momentums, 0)
+}
@@ -0,0 +1,300 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// A snapshot file has the following layout:
+//   - magic, version and header
+//   - sections, each one starting with the section id and containing key-value entries:
+//     the chain state, the rollback-patches of the last momentums and the consensus db
+//   - the end-section id followed by the sha256 checksum of all previous bytes
+const (
+	Version = uint32(1)
+
+	sectionEnd       = byte(0)
+	sectionChain     = byte(1)
+	sectionConsensus = byte(2)
+	sectionHistory   = byte(3)
+
+	entryMarker      = byte(1)
+	endOfSectionMark = byte(0)
+
+	headerSize = 8 + 8 + types.HashSize + 8 + types.HashSize
+	// keys and values bigger than this are considered corrupted
+	maxFieldSize = 64 * 1024 * 1024
+)
+
+var (
+	magic = []byte("ZNNSNAP\x00")
+
+	ErrInvalidSnapshot     = errors.New("invalid snapshot file")
+	ErrUnsupportedVersion  = errors.New("unsupported snapshot version")
+	ErrChecksumMismatch    = errors.New("snapshot checksum mismatch")
+	ErrStateDigestMismatch = errors.New("snapshot state digest mismatch")
+)
+
+// Header describes the state contained in a snapshot.
+// StateDigest is the sha256 of the chain state at Momentum and can be compared with the digest computed by a trusted node.
+type Header struct {
+	Version         uint32
+	ChainIdentifier uint64
+	Momentum        types.HashHeight
+	Timestamp       int64
+	StateDigest     types.Hash
+}
+
+func (h *Header) serialize() []byte {
+	return common.JoinBytes(
+		common.Uint64ToBytes(h.ChainIdentifier),
+		common.Uint64ToBytes(h.Momentum.Height),
+		h.Momentum.Hash.Bytes(),
+		common.Uint64ToBytes(uint64(h.Timestamp)),
+		h.StateDigest.Bytes(),
+	)
+}
+func deserializeHeader(version uint32, data []byte) (*Header, error) {
+	if len(data) != headerSize {
+		return nil, ErrInvalidSnapshot
+	}
+	h := &Header{
+		Version:         version,
+		ChainIdentifier: common.BytesToUint64(data[0:8]),
+		Timestamp:       int64(common.BytesToUint64(data[48:56])),
+	}
+	var err error
+	h.Momentum.Height = common.BytesToUint64(data[8:16])
+	if h.Momentum.Hash, err = types.BytesToHash(data[16:48]); err != nil {
+		return nil, err
+	}
+	if h.StateDigest, err = types.BytesToHash(data[56:88]); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// writeEntry encodes a key-value entry. The same encoding is used to compute the state digest.
+func writeEntry(w io.Writer, key, value []byte) error {
+	buf := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(key)+len(value))
+	buf = append(buf, entryMarker)
+	buf = binary.AppendUvarint(buf, uint64(len(key)))
+	buf = append(buf, key...)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	buf = append(buf, value...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// stateDigest computes the digest of all entries of the state, in key order
+func stateDigest(state db.DB) (types.Hash, error) {
+	hasher := sha256.New()
+	iterator := state.NewIterator(nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		if iterator.Value() == nil {
+			continue
+		}
+		if err := writeEntry(hasher, iterator.Key(), iterator.Value()); err != nil {
+			return types.ZeroHash, err
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return types.ZeroHash, err
+	}
+	return types.BytesToHash(hasher.Sum(nil))
+}
+
+type writer struct {
+	file   *bufio.Writer
+	hasher hash.Hash
+	w      io.Writer
+}
+
+func newWriter(w io.Writer) *writer {
+	file := bufio.NewWriter(w)
+	hasher := sha256.New()
+	return &writer{
+		file:   file,
+		hasher: hasher,
+		w:      io.MultiWriter(file, hasher),
+	}
+}
+func (w *writer) writeHeader(header *Header) error {
+	_, err := w.w.Write(common.JoinBytes(magic, common.Uint32ToBytes(header.Version), header.serialize()))
+	return err
+}
+func (w *writer) writeSection(id byte, iterator db.StorageIterator) error {
+	defer iterator.Release()
+	if _, err := w.w.Write([]byte{id}); err != nil {
+		return err
+	}
+	for iterator.Next() {
+		// deleted entries have nil values
+		if iterator.Value() == nil {
+			continue
+		}
+		if err := writeEntry(w.w, iterator.Key(), iterator.Value()); err != nil {
+			return err
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	_, err := w.w.Write([]byte{endOfSectionMark})
+	return err
+}
+
+// close ends the snapshot and writes the checksum
+func (w *writer) close() error {
+	if _, err := w.w.Write([]byte{sectionEnd}); err != nil {
+		return err
+	}
+	if _, err := w.file.Write(w.hasher.Sum(nil)); err != nil {
+		return err
+	}
+	return w.file.Flush()
+}
+
+type reader struct {
+	file   *bufio.Reader
+	hasher hash.Hash
+}
+
+func newReader(r io.Reader) *reader {
+	return &reader{
+		file:   bufio.NewReader(r),
+		hasher: sha256.New(),
+	}
+}
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(r.file, p)
+	r.hasher.Write(p[:n])
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return n, ErrInvalidSnapshot
+	}
+	return n, err
+}
+func (r *reader) ReadByte() (byte, error) {
+	b, err := r.file.ReadByte()
+	if err == io.EOF {
+		return 0, ErrInvalidSnapshot
+	}
+	if err != nil {
+		return 0, err
+	}
+	r.hasher.Write([]byte{b})
+	return b, nil
+}
+func (r *reader) readBytes(length uint64) ([]byte, error) {
+	data := make([]byte, length)
+	if _, err := r.Read(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+func (r *reader) readHeader() (*Header, error) {
+	prefix, err := r.readBytes(uint64(len(magic) + 4))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:len(magic)], magic) {
+		return nil, ErrInvalidSnapshot
+	}
+	version := binary.BigEndian.Uint32(prefix[len(magic):])
+	if version != Version {
+		return nil, ErrUnsupportedVersion
+	}
+	data, err := r.readBytes(headerSize)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeHeader(version, data)
+}
+
+// readSection returns the id of the next section, sectionEnd if there are no more sections
+func (r *reader) readSection() (byte, error) {
+	return r.ReadByte()
+}
+
+// close checks the checksum of the snapshot
+func (r *reader) close() error {
+	expected := r.hasher.Sum(nil)
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(r.file, checksum); err != nil {
+		return ErrInvalidSnapshot
+	}
+	if !bytes.Equal(checksum, expected) {
+		return ErrChecksumMismatch
+	}
+	if _, err := r.file.ReadByte(); err != io.EOF {
+		return ErrInvalidSnapshot
+	}
+	return nil
+}
+
+// sectionIterator iterates over the entries of the current section of a reader
+type sectionIterator struct {
+	r     *reader
+	key   []byte
+	value []byte
+	err   error
+	done  bool
+}
+
+func (si *sectionIterator) Next() bool {
+	if si.done || si.err != nil {
+		return false
+	}
+	marker, err := si.r.ReadByte()
+	if err != nil {
+		si.err = err
+		return false
+	}
+	if marker == endOfSectionMark {
+		si.done = true
+		return false
+	}
+	if marker != entryMarker {
+		si.err = ErrInvalidSnapshot
+		return false
+	}
+	if si.key, si.err = si.readField(); si.err != nil {
+		return false
+	}
+	if si.value, si.err = si.readField(); si.err != nil {
+		return false
+	}
+	return true
+}
+func (si *sectionIterator) readField() ([]byte, error) {
+	length, err := binary.ReadUvarint(si.r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxFieldSize {
+		return nil, ErrInvalidSnapshot
+	}
+	return si.r.readBytes(length)
+}
+func (si *sectionIterator) Key() []byte {
+	return si.key
+}
+func (si *sectionIterator) Value() []byte {
+	return si.value
+}
+func (si *sectionIterator) Error() error {
+	return si.err
+}
+func (si *sectionIterator) Release() {
+}
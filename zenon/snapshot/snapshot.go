@@ -0,0 +1,350 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/consensus/storage"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// number of consensus entries written at once when importing a snapshot
+	importBatchSize = 10000
+)
+
+var (
+	// DefaultHistory is the default number of momentums for which the rollback-patches are exported
+	DefaultHistory = chain.MinPruneRetention
+)
+
+// Export writes the chain state and the consensus data at the momentum with the given height in file.
+// Only stable momentums can be exported, a height of 0 exports the last stable momentum.
+// The node must be stopped since the databases are opened exclusively.
+//
+// The rollback-patches of the last history momentums are exported as well, since a node needs the state of recent
+// momentums to verify new account-blocks and momentums.
+func Export(dataDir string, height uint64, history uint64, file string) (*Header, error) {
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	if _, err := os.Stat(chainDir); err != nil {
+		return nil, errors.Errorf("can't find chain db in %v", dataDir)
+	}
+	manager, err := db.OpenManager("", chainDir)
+	if err != nil {
+		return nil, errors.Errorf("can't open chain db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer manager.Stop()
+	seeder, ok := manager.(db.Seeder)
+	if !ok {
+		return nil, errors.Errorf("chain db doesn't support snapshots")
+	}
+
+	frontierStore := momentum.NewStore(nil, manager.Frontier())
+	genesis, err := frontierStore.GetMomentumByHeight(1)
+	if err != nil {
+		return nil, err
+	}
+	frontier, err := frontierStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 {
+		height = genesis.Height
+		if frontier.Height > genesis.Height+chain.StableDepth {
+			height = frontier.Height - chain.StableDepth - 1
+		}
+	}
+	m, err := frontierStore.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, errors.Errorf("momentum at height %v doesn't exist", height)
+	}
+	// the genesis momentum is never rolled back
+	if m.Height != genesis.Height && frontier.Height-m.Height <= chain.StableDepth {
+		return nil, errors.Errorf("momentum at height %v is not stable yet. Stable momentums are more than %v momentums below the frontier momentum", height, chain.StableDepth)
+	}
+	state := manager.Get(m.Identifier())
+	if state == nil {
+		return nil, errors.Errorf("state at momentum %v is no longer retained by this node", m.Identifier())
+	}
+
+	digest, err := stateDigest(state)
+	if err != nil {
+		return nil, err
+	}
+	header := &Header{
+		Version:         Version,
+		ChainIdentifier: m.ChainIdentifier,
+		Momentum:        m.Identifier(),
+		Timestamp:       m.Timestamp.Unix(),
+		StateDigest:     digest,
+	}
+
+	consensusStorage, err := db.OpenStorage("", path.Join(dataDir, zenon.ConsensusDir))
+	if err != nil {
+		return nil, errors.Errorf("can't open consensus db. Make sure znnd is stopped. Reason: %v", err)
+	}
+	defer consensusStorage.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	w := newWriter(f)
+	if err := w.writeHeader(header); err != nil {
+		return nil, err
+	}
+	if err := w.writeSection(sectionChain, state.NewIterator(nil)); err != nil {
+		return nil, err
+	}
+	from := uint64(1)
+	if m.Height > history {
+		from = m.Height - history + 1
+	}
+	if pruner, ok := manager.(db.Pruner); ok && from <= pruner.PrunedHeight() {
+		from = pruner.PrunedHeight() + 1
+	}
+	if err := w.writeSection(sectionHistory, seeder.Rollbacks(from, m.Height)); err != nil {
+		return nil, err
+	}
+	consensusData := newConsensusIterator(frontierStore, genesis, m, consensusStorage.NewIterator(nil, nil))
+	if err := w.writeSection(sectionConsensus, consensusData); err != nil {
+		return nil, err
+	}
+	if err := w.close(); err != nil {
+		return nil, err
+	}
+	return header, f.Sync()
+}
+
+// consensusIterator skips the consensus data which a node at the exported momentum doesn't have yet.
+// The points of the ticks which are not completed at the momentum are skipped, the same way they are deleted when
+// the momentum is rolled back, and so are the election results of proof momentums above it.
+type consensusIterator struct {
+	db.StorageIterator
+	store    store.Momentum
+	m        *nom.Momentum
+	maxTicks [storage.NumPointTypes]uint64
+	err      error
+}
+
+func newConsensusIterator(frontierStore store.Momentum, genesis, m *nom.Momentum, iterator db.StorageIterator) *consensusIterator {
+	periodTicker := consensus.NewConsensusContext(*genesis.Timestamp).Ticker
+	epochTicker := common.NewTicker(*genesis.Timestamp, consensus.EpochDuration)
+	ci := &consensusIterator{
+		StorageIterator: iterator,
+		store:           frontierStore,
+		m:               m,
+	}
+	ci.maxTicks[storage.PrefixPeriodPoint] = periodTicker.ToTick(*m.Timestamp)
+	ci.maxTicks[storage.PrefixEpochPoint] = epochTicker.ToTick(*m.Timestamp)
+	return ci
+}
+
+func (ci *consensusIterator) Next() bool {
+	for ci.err == nil && ci.StorageIterator.Next() {
+		keep, err := ci.keep(ci.Key())
+		if err != nil {
+			ci.err = err
+			return false
+		}
+		if keep {
+			return true
+		}
+	}
+	return false
+}
+func (ci *consensusIterator) keep(key []byte) (bool, error) {
+	switch {
+	case len(key) == 9 && key[0] < storage.NumPointTypes:
+		return binary.BigEndian.Uint64(key[1:]) < ci.maxTicks[key[0]], nil
+	case len(key) == 1+types.HashSize && key[0] == storage.PrefixElectionResult:
+		hash, err := types.BytesToHash(key[1:])
+		if err != nil {
+			return false, err
+		}
+		proof, err := ci.store.GetMomentumByHash(hash)
+		if err != nil {
+			return false, err
+		}
+		return proof != nil && proof.Height <= ci.m.Height, nil
+	default:
+		return true, nil
+	}
+}
+func (ci *consensusIterator) Error() error {
+	if ci.err != nil {
+		return ci.err
+	}
+	return ci.StorageIterator.Error()
+}
+
+// Verify checks the checksum and the state digest of the snapshot and returns its header
+func Verify(file string) (*Header, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := newReader(f)
+	header, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	for {
+		id, err := r.readSection()
+		if err != nil {
+			return nil, err
+		}
+		if id == sectionEnd {
+			break
+		}
+		if id != sectionChain && id != sectionHistory && id != sectionConsensus {
+			return nil, ErrInvalidSnapshot
+		}
+
+		iterator := &sectionIterator{r: r}
+		for iterator.Next() {
+			if id == sectionChain {
+				if err := writeEntry(hasher, iterator.Key(), iterator.Value()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := iterator.Error(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.close(); err != nil {
+		return nil, err
+	}
+
+	digest, err := types.BytesToHash(hasher.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if digest != header.StateDigest {
+		return nil, ErrStateDigestMismatch
+	}
+	return header, nil
+}
+
+// Import verifies the snapshot and seeds the chain and consensus databases of a fresh data dir, using backend.
+// The node resumes syncing from the momentum of the snapshot, the history before it is not available.
+func Import(dataDir string, backend db.Backend, file string) (*Header, error) {
+	header, err := Verify(file)
+	if err != nil {
+		return nil, err
+	}
+
+	chainDir := path.Join(dataDir, zenon.ChainDir)
+	consensusDir := path.Join(dataDir, zenon.ConsensusDir)
+	for _, dir := range []string{chainDir, consensusDir} {
+		if _, err := os.Stat(dir); err == nil {
+			return nil, errors.Errorf("%v already exists. A snapshot can only be imported in a fresh data dir", dir)
+		}
+	}
+
+	if err := importSnapshot(backend, chainDir, consensusDir, file, header); err != nil {
+		// leave the data dir as it was, so the import can be retried
+		os.RemoveAll(chainDir)
+		os.RemoveAll(consensusDir)
+		return nil, err
+	}
+	return header, nil
+}
+func importSnapshot(backend db.Backend, chainDir, consensusDir, file string, header *Header) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	manager, err := db.OpenManager(backend, chainDir)
+	if err != nil {
+		return err
+	}
+	defer manager.Stop()
+	seeder, ok := manager.(db.Seeder)
+	if !ok {
+		return errors.Errorf("chain db doesn't support seeding")
+	}
+	consensusStorage, err := db.OpenStorage(backend, consensusDir)
+	if err != nil {
+		return err
+	}
+	defer consensusStorage.Close()
+
+	r := newReader(f)
+	if _, err := r.readHeader(); err != nil {
+		return err
+	}
+	for {
+		id, err := r.readSection()
+		if err != nil {
+			return err
+		}
+		if id == sectionEnd {
+			break
+		}
+
+		iterator := &sectionIterator{r: r}
+		switch id {
+		case sectionChain:
+			err = seeder.Seed(iterator)
+		case sectionHistory:
+			err = seeder.SeedRollbacks(iterator)
+		case sectionConsensus:
+			err = writeStorage(consensusStorage, iterator)
+		default:
+			err = ErrInvalidSnapshot
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.close(); err != nil {
+		return err
+	}
+
+	if frontier := db.GetFrontierIdentifier(manager.Frontier()); frontier != header.Momentum {
+		return errors.Errorf("imported state has frontier %v but the snapshot header has %v", frontier, header.Momentum)
+	}
+	return nil
+}
+func writeStorage(storage db.Storage, iterator db.StorageIterator) error {
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Put(iterator.Key(), iterator.Value())
+		if batch.Len() >= importBatchSize {
+			if err := storage.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	return storage.Write(batch, nil)
+}
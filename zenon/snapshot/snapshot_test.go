@@ -0,0 +1,133 @@
+package snapshot
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus/storage"
+	"github.com/zenon-network/go-zenon/zenon"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func newDataDir(t *testing.T) (string, types.HashHeight) {
+	dataDir := t.TempDir()
+	ch := chain.NewChain(db.NewLevelDBManager(path.Join(dataDir, zenon.ChainDir)), genesis.NewGenesis(g.EmbeddedGenesis))
+	common.FailIfErr(t, ch.Init())
+	identifier := ch.GetGenesisMomentum().Identifier()
+	common.FailIfErr(t, ch.Stop())
+
+	consensusDB, consensusLevelDB := db.NewLevelDB(path.Join(dataDir, zenon.ConsensusDir))
+	common.FailIfErr(t, consensusDB.Put([]byte{1, 2, 3}, []byte{4, 5, 6}))
+	common.FailIfErr(t, consensusLevelDB.Close())
+	return dataDir, identifier
+}
+
+func TestSnapshot_ExportImport(t *testing.T) {
+	dataDir, genesisIdentifier := newDataDir(t)
+	file := path.Join(t.TempDir(), "snapshot.bin")
+
+	header, err := Export(dataDir, 0, DefaultHistory, file)
+	common.FailIfErr(t, err)
+	common.Expect(t, header.Momentum, genesisIdentifier)
+	verified, err := Verify(file)
+	common.FailIfErr(t, err)
+	common.Expect(t, verified, header)
+
+	imported := t.TempDir()
+	_, err = Import(imported, db.LevelDB, file)
+	common.FailIfErr(t, err)
+	_, err = Import(imported, db.LevelDB, file)
+	common.ExpectString(t, err.Error(), path.Join(imported, zenon.ChainDir)+" already exists. A snapshot can only be imported in a fresh data dir")
+
+	original := db.NewLevelDBManager(path.Join(dataDir, zenon.ChainDir))
+	defer original.Stop()
+	seeded := db.NewLevelDBManager(path.Join(imported, zenon.ChainDir))
+	defer seeded.Stop()
+	common.ExpectString(t, db.DebugDB(seeded.Frontier()), db.DebugDB(original.Frontier()))
+	// the rollback-patch of the genesis momentum is exported too
+	common.ExpectUint64(t, seeded.(db.Pruner).PrunedHeight(), header.Momentum.Height-1)
+
+	consensusDB, consensusLevelDB := db.NewLevelDB(path.Join(imported, zenon.ConsensusDir))
+	defer consensusLevelDB.Close()
+	value, err := consensusDB.Get([]byte{1, 2, 3})
+	common.FailIfErr(t, err)
+	common.ExpectBytes(t, value, "0x040506")
+}
+
+func TestSnapshot_Corrupted(t *testing.T) {
+	dataDir, _ := newDataDir(t)
+	file := path.Join(t.TempDir(), "snapshot.bin")
+	_, err := Export(dataDir, 0, DefaultHistory, file)
+	common.FailIfErr(t, err)
+
+	data, err := os.ReadFile(file)
+	common.FailIfErr(t, err)
+	data[len(data)/2] ^= 0xff
+	common.FailIfErr(t, os.WriteFile(file, data, 0600))
+
+	_, err = Verify(file)
+	common.ExpectError(t, err, ErrChecksumMismatch)
+	imported := t.TempDir()
+	_, err = Import(imported, db.LevelDB, file)
+	common.ExpectError(t, err, ErrChecksumMismatch)
+	if _, err := os.Stat(path.Join(imported, zenon.ChainDir)); !os.IsNotExist(err) {
+		t.Fatal("expected no chain db to be created")
+	}
+
+	_, err = Export(dataDir, 100, DefaultHistory, file)
+	common.ExpectString(t, err.Error(), "momentum at height 100 doesn't exist")
+}
+
+func TestSnapshot_ExportHistory(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	z.InsertMomentumsTo(100)
+	momentumStore := z.Chain().GetFrontierMomentumStore()
+	proof40, err := momentumStore.GetMomentumByHeight(40)
+	common.FailIfErr(t, err)
+	proof60, err := momentumStore.GetMomentumByHeight(60)
+	common.FailIfErr(t, err)
+	dataDir := z.Config().DataDir
+	z.StopPanic()
+
+	// the consensus data of a node at momentum 100
+	consensusDB, consensusLevelDB := db.NewLevelDB(path.Join(dataDir, zenon.ConsensusDir))
+	for tick := uint64(0); tick < 3; tick += 1 {
+		common.FailIfErr(t, consensusDB.Put(storage.CreatePointKey(storage.PrefixPeriodPoint, tick), []byte{byte(tick)}))
+	}
+	common.FailIfErr(t, consensusDB.Put(storage.CreatePointKey(storage.PrefixEpochPoint, 0), []byte{0}))
+	common.FailIfErr(t, consensusDB.Put(storage.CreateElectionResultKey(proof40.Hash), []byte{40}))
+	common.FailIfErr(t, consensusDB.Put(storage.CreateElectionResultKey(proof60.Hash), []byte{60}))
+	common.FailIfErr(t, consensusDB.Put(storage.CreateElectionResultKey(types.NewHash([]byte{1})), []byte{1}))
+	common.FailIfErr(t, consensusLevelDB.Close())
+
+	file := path.Join(t.TempDir(), "snapshot.bin")
+	_, err = Export(dataDir, 80, DefaultHistory, file)
+	common.ExpectString(t, err.Error(), "momentum at height 80 is not stable yet. Stable momentums are more than 30 momentums below the frontier momentum")
+	header, err := Export(dataDir, 0, DefaultHistory, file)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, header.Momentum.Height, 69)
+
+	header, err = Export(dataDir, 50, DefaultHistory, file)
+	common.FailIfErr(t, err)
+	imported := t.TempDir()
+	_, err = Import(imported, db.LevelDB, file)
+	common.FailIfErr(t, err)
+
+	seeded := db.NewLevelDBManager(path.Join(imported, zenon.ChainDir))
+	common.Expect(t, db.GetFrontierIdentifier(seeded.Frontier()), header.Momentum)
+	seeded.Stop()
+
+	// only the points of the ticks completed at momentum 50 and the election of momentum 40 are exported
+	consensusDB, consensusLevelDB = db.NewLevelDB(path.Join(imported, zenon.ConsensusDir))
+	defer consensusLevelDB.Close()
+	common.ExpectString(t, db.DebugDB(consensusDB), `
+000000000000000000 - 00
+0a7329fd7f4055b0ca51fe5f474980d0a2f045eebf056f297b473297ad5be1dc1c - 28`)
+}
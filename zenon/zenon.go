@@ -1,9 +1,9 @@
 package zenon
 
 import (
-	"github.com/syndtr/goleveldb/leveldb"
-
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/index"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
@@ -23,7 +23,11 @@ type zenon struct {
 	consensus   consensus.Consensus
 	evPrinter   EventPrinter
 	broadcaster protocol.Broadcaster
-	levelDb     *leveldb.DB
+	consensusDb db.Storage
+
+	addressIndex *index.AddressIndex
+	holderIndex  *index.HolderIndex
+	htlcIndex    *index.HtlcIndex
 }
 
 func NewZenon(cfg *Config) (Zenon, error) {
@@ -31,11 +35,14 @@ func NewZenon(cfg *Config) (Zenon, error) {
 		config: cfg,
 	}
 
-	z.chain = chain.NewChain(cfg.NewDBManager("nom"), cfg.GenesisConfig)
-	db, levelDb := cfg.NewLevelDB("consensus")
-	z.consensus = consensus.NewConsensus(db, z.chain, false)
+	z.chain = chain.NewChain(cfg.NewDBManager(ChainDir), cfg.GenesisConfig)
+	if err := z.chain.SetPruneRetention(cfg.PruneRetention); err != nil {
+		return nil, err
+	}
+	consensusDB, consensusStorage := cfg.NewDB(ConsensusDir)
+	z.consensus = consensus.NewConsensus(consensusDB, z.chain, false)
 	z.verifier = verifier.NewVerifier(z.chain, z.consensus)
-	z.levelDb = levelDb
+	z.consensusDb = consensusStorage
 
 	chainBridge := protocol.NewChainBridge(z.chain, z.consensus, z.verifier, vm.NewSupervisor(z.chain, z.consensus))
 	z.protocol = protocol.NewProtocolManager(cfg.MinPeers, z.chain.ChainIdentifier(), chainBridge)
@@ -45,8 +52,21 @@ func NewZenon(cfg *Config) (Zenon, error) {
 	z.subscribe = subscribe.GetSubscribeServer(z.chain)
 	z.pillar = pillar.NewPillar(z.chain, z.consensus, z.broadcaster)
 
-	if cfg.ProducingKeyPair != nil {
-		z.pillar.SetCoinBase(cfg.ProducingKeyPair)
+	if cfg.EnableAddressIndex {
+		_, indexDb := cfg.NewIndexDB(AddressIndexDir)
+		z.addressIndex = index.NewAddressIndex(z.chain, indexDb)
+	}
+	if cfg.EnableHolderIndex {
+		_, holderDb := cfg.NewIndexDB(HolderIndexDir)
+		z.holderIndex = index.NewHolderIndex(z.chain, holderDb)
+	}
+	if cfg.EnableHtlcIndex {
+		_, htlcDb := cfg.NewIndexDB(HtlcIndexDir)
+		z.htlcIndex = index.NewHtlcIndex(z.chain, htlcDb)
+	}
+
+	if cfg.ProducingSigner != nil {
+		z.pillar.SetSigner(cfg.ProducingSigner)
 	}
 
 	return z, nil
@@ -59,6 +79,21 @@ func (z *zenon) Init() error {
 	if err := z.consensus.Init(); err != nil {
 		return err
 	}
+	if z.addressIndex != nil {
+		if err := z.addressIndex.Init(); err != nil {
+			return err
+		}
+	}
+	if z.holderIndex != nil {
+		if err := z.holderIndex.Init(); err != nil {
+			return err
+		}
+	}
+	if z.htlcIndex != nil {
+		if err := z.htlcIndex.Init(); err != nil {
+			return err
+		}
+	}
 	if err := z.evPrinter.Init(); err != nil {
 		return err
 	}
@@ -79,6 +114,21 @@ func (z *zenon) Start() error {
 	if err := z.consensus.Start(); err != nil {
 		return err
 	}
+	if z.addressIndex != nil {
+		if err := z.addressIndex.Start(); err != nil {
+			return err
+		}
+	}
+	if z.holderIndex != nil {
+		if err := z.holderIndex.Start(); err != nil {
+			return err
+		}
+	}
+	if z.htlcIndex != nil {
+		if err := z.htlcIndex.Start(); err != nil {
+			return err
+		}
+	}
 	if err := z.evPrinter.Start(); err != nil {
 		return err
 	}
@@ -103,13 +153,28 @@ func (z *zenon) Stop() error {
 	if err := z.evPrinter.Stop(); err != nil {
 		return err
 	}
+	if z.htlcIndex != nil {
+		if err := z.htlcIndex.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.holderIndex != nil {
+		if err := z.holderIndex.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.addressIndex != nil {
+		if err := z.addressIndex.Stop(); err != nil {
+			return err
+		}
+	}
 	if err := z.consensus.Stop(); err != nil {
 		return err
 	}
 	if err := z.chain.Stop(); err != nil {
 		return err
 	}
-	if err := z.levelDb.Close(); err != nil {
+	if err := z.consensusDb.Close(); err != nil {
 		return err
 	}
 
@@ -137,3 +202,12 @@ func (z *zenon) Config() *Config {
 func (z *zenon) Broadcaster() protocol.Broadcaster {
 	return z.broadcaster
 }
+func (z *zenon) AddressIndex() *index.AddressIndex {
+	return z.addressIndex
+}
+func (z *zenon) HolderIndex() *index.HolderIndex {
+	return z.holderIndex
+}
+func (z *zenon) HtlcIndex() *index.HtlcIndex {
+	return z.htlcIndex
+}